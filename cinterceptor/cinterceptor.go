@@ -2,11 +2,19 @@ package cinterceptor
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
 	"time"
 
 	"connectrpc.com/connect"
 	"github.com/mickamy/grpc-scope/scope"
 	"github.com/mickamy/grpc-scope/scope/domain"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
 // Option configures a Scope.
@@ -17,6 +25,194 @@ func WithPort(port int) Option {
 	return scope.WithPort(port)
 }
 
+// WithBindAddr sets the host the internal gRPC server listens on (default 127.0.0.1).
+// Use "0.0.0.0" to let monitors on other hosts connect.
+func WithBindAddr(host string) Option {
+	return scope.WithBindAddr(host)
+}
+
+// WithUnixSocket makes the internal gRPC server listen on a Unix domain socket instead of a TCP port.
+func WithUnixSocket(path string) Option {
+	return scope.WithUnixSocket(path)
+}
+
+// WithBufferSize sets how many events are buffered for each Watch subscriber before events are dropped.
+func WithBufferSize(n int) Option {
+	return scope.WithBufferSize(n)
+}
+
+// WithBlockingPublish makes capturing wait up to timeout for a slow Watch subscriber instead of dropping events.
+// While a subscriber is behind, every captured call is delayed by up to timeout.
+func WithBlockingPublish(timeout time.Duration) Option {
+	return scope.WithBlockingPublish(timeout)
+}
+
+// WithMaxRepeatedElements limits every JSON array in captured payloads to its first n elements.
+func WithMaxRepeatedElements(n int) Option {
+	return scope.WithMaxRepeatedElements(n)
+}
+
+// WithMaxShownElements makes monitors show only the first n elements of every JSON array in payloads.
+func WithMaxShownElements(n int) Option {
+	return scope.WithMaxShownElements(n)
+}
+
+// WithMaxPayloadSize sets the size budget, in bytes, for capturing a single payload.
+func WithMaxPayloadSize(n int) Option {
+	return scope.WithMaxPayloadSize(n)
+}
+
+// WithMaxBytesFieldSize replaces bytes fields longer than n bytes in captured payloads with a "<N bytes>" placeholder.
+func WithMaxBytesFieldSize(n int) Option {
+	return scope.WithMaxBytesFieldSize(n)
+}
+
+// WithRawRequestBytes also captures unary requests in their protobuf wire encoding, which replays send instead of JSON.
+func WithRawRequestBytes() Option {
+	return scope.WithRawRequestBytes()
+}
+
+// WithPayloadSampleRate captures payloads for only the given fraction of successful calls.
+func WithPayloadSampleRate(rate float64) Option {
+	return scope.WithPayloadSampleRate(rate)
+}
+
+// WithIgnoreMethods skips capturing methods matching any of patterns, replacing
+// the default list of health check, reflection, and scope methods.
+func WithIgnoreMethods(patterns ...string) Option {
+	return scope.WithIgnoreMethods(patterns...)
+}
+
+// WithCaptureOnly captures only methods matching any of patterns, even ignored ones.
+func WithCaptureOnly(patterns ...string) Option {
+	return scope.WithCaptureOnly(patterns...)
+}
+
+// CaptureFilter decides per call whether it is captured.
+type CaptureFilter = scope.CaptureFilter
+
+// WithCaptureFilter skips capturing calls for which fn returns false.
+func WithCaptureFilter(fn CaptureFilter) Option {
+	return scope.WithCaptureFilter(fn)
+}
+
+// IDFormat builds the ID of a captured event from its sequence number and the event.
+type IDFormat = scope.IDFormat
+
+// WithIDFormat replaces the default "call-N" event IDs with the ones fn returns.
+func WithIDFormat(fn IDFormat) Option {
+	return scope.WithIDFormat(fn)
+}
+
+// Processor enriches or rewrites a captured event before it is published.
+type Processor = scope.Processor
+
+// WithProcessor runs fn on every captured event before it is published, after any processors added earlier.
+func WithProcessor(fn Processor) Option {
+	return scope.WithProcessor(fn)
+}
+
+// PayloadMarshaler formats a captured request or response for display.
+type PayloadMarshaler = scope.PayloadMarshaler
+
+// WithPayloadMarshaler replaces the default JSON formatting of captured payloads with fn.
+func WithPayloadMarshaler(fn PayloadMarshaler) Option {
+	return scope.WithPayloadMarshaler(fn)
+}
+
+// WithDeadlineSourceKey records on each event whether its deadline came from the client or from an interceptor
+// that stored a marker in the context under key.
+func WithDeadlineSourceKey(key any) Option {
+	return scope.WithDeadlineSourceKey(key)
+}
+
+// LabelExtractor returns labels describing a call from its context.
+type LabelExtractor = scope.LabelExtractor
+
+// WithLabelExtractor records the labels fn returns for each call on its event.
+func WithLabelExtractor(fn LabelExtractor) Option {
+	return scope.WithLabelExtractor(fn)
+}
+
+// WithPersistPath saves captured events to path on Close and restores them on New.
+func WithPersistPath(path string) Option {
+	return scope.WithPersistPath(path)
+}
+
+// WithRecoverPanics records calls whose handler panicked, then lets the panic continue.
+func WithRecoverPanics() Option {
+	return scope.WithRecoverPanics()
+}
+
+// WithHistorySize keeps the last n captured events in memory for GetHistory queries.
+func WithHistorySize(n int) Option {
+	return scope.WithHistorySize(n)
+}
+
+// WithHeartbeatInterval sets how long a Watch stream may idle before a heartbeat is sent.
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return scope.WithHeartbeatInterval(interval)
+}
+
+// WithLingerOnClose makes Close wait up to d for watching TUI clients to disconnect.
+func WithLingerOnClose(d time.Duration) Option {
+	return scope.WithLingerOnClose(d)
+}
+
+// WithAppTarget sets the application address the monitor replays calls to when given none.
+func WithAppTarget(addr string) Option {
+	return scope.WithAppTarget(addr)
+}
+
+// WithServeErrorHandler calls fn when the internal server stops serving before Close.
+func WithServeErrorHandler(fn func(error)) Option {
+	return scope.WithServeErrorHandler(fn)
+}
+
+// WithDisableServer captures events without binding any port; read them with Subscribe.
+func WithDisableServer() Option {
+	return scope.WithDisableServer()
+}
+
+// LogFormat selects how WithStderrLog writes events.
+type LogFormat = scope.LogFormat
+
+// Formats accepted by WithStderrLog.
+const (
+	LogFormatJSON   = scope.LogFormatJSON
+	LogFormatLogfmt = scope.LogFormatLogfmt
+)
+
+// WithStderrLog also writes every captured event to stderr as a one-line JSON or logfmt log.
+func WithStderrLog(format LogFormat) Option {
+	return scope.WithStderrLog(format)
+}
+
+// WithHTTPPort also streams captured events as JSON server-sent events on GET /events at port.
+func WithHTTPPort(port int) Option {
+	return scope.WithHTTPPort(port)
+}
+
+// WithAuthToken requires TUI clients to present token before they can watch traffic.
+func WithAuthToken(token string) Option {
+	return scope.WithAuthToken(token)
+}
+
+// WithLogger makes the scope log diagnostics, such as payload marshal failures and dropped events, to logger.
+func WithLogger(logger *slog.Logger) Option {
+	return scope.WithLogger(logger)
+}
+
+// WithRuntimeStats records the application's goroutine count on every captured event.
+func WithRuntimeStats(enabled bool) Option {
+	return scope.WithRuntimeStats(enabled)
+}
+
+// Suppress keeps the call handled under ctx, and outbound calls made with ctx afterwards, from being captured.
+func Suppress(ctx context.Context) {
+	scope.Suppress(ctx)
+}
+
 // Scope captures ConnectRPC traffic and exposes it via an internal gRPC server.
 type Scope struct {
 	scope *scope.Scope
@@ -31,11 +227,45 @@ func New(opts ...Option) (*Scope, error) {
 	return &Scope{scope: s}, nil
 }
 
+// Addr returns the address the internal gRPC server listens on.
+func (s *Scope) Addr() net.Addr {
+	return s.scope.Addr()
+}
+
+// HTTPAddr returns the address the server-sent events endpoint listens on, or nil if it is disabled.
+func (s *Scope) HTTPAddr() net.Addr {
+	return s.scope.HTTPAddr()
+}
+
 // SubscriberCount returns the number of active Watch subscribers.
 func (s *Scope) SubscriberCount() int {
 	return s.scope.SubscriberCount()
 }
 
+// DroppedEvents returns the number of events dropped because a Watch subscriber fell behind.
+func (s *Scope) DroppedEvents() uint64 {
+	return s.scope.DroppedEvents()
+}
+
+// AppTarget returns the application address reported to the monitor, or "" if it is not known yet.
+func (s *Scope) AppTarget() string {
+	return s.scope.AppTarget()
+}
+
+// Subscribe returns a channel of the events captured in this process and a function that unsubscribes.
+func (s *Scope) Subscribe() (<-chan domain.CallEvent, func()) {
+	return s.scope.Subscribe()
+}
+
+// HandlerPath is the URL path prefix to mount Scope.Handler on.
+const HandlerPath = scope.HandlerPath
+
+// Handler returns the scope server as an http.Handler to mount at HandlerPath on an HTTP/2 server.
+// It serves gRPC over HTTP/2 (h2 or h2c) only, not the Connect protocol, gRPC-Web or HTTP/1.1.
+func (s *Scope) Handler() http.Handler {
+	return s.scope.Handler()
+}
+
 // Close stops the internal gRPC server.
 func (s *Scope) Close() {
 	s.scope.Close()
@@ -52,66 +282,280 @@ type interceptor struct {
 
 func (i *interceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
 	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		entered := time.Now()
+		if !req.Spec().IsClient {
+			observeLocalAddr(ctx, i.s)
+		}
+		md := extractHeaders(req.Header())
+		if !i.s.ShouldCapture(req.Spec().Procedure, md) {
+			return next(ctx, req)
+		}
+
 		start := time.Now()
 
-		resp, err := next(ctx, req)
+		ctx = scope.Suppressible(ctx)
+		publish := func(resp connect.AnyResponse, err error) {
+			if scope.Suppressed(ctx) {
+				return
+			}
 
-		ev := domain.CallEvent{
-			ID:              i.s.GenerateID(),
-			Method:          req.Spec().Procedure,
-			StartTime:       start,
-			Duration:        time.Since(start),
-			RequestMetadata: extractHeaders(req.Header()),
-			RequestPayload:  scope.MarshalPayload(req.Any()),
-		}
+			ev := domain.CallEvent{
+				ID:              i.s.GenerateID(),
+				Method:          req.Spec().Procedure,
+				StartTime:       start,
+				Duration:        time.Since(start),
+				RequestMetadata: md,
+				Direction:       direction(req.Spec()),
+				Protocol:        req.Peer().Protocol,
+				ContentEncoding: contentEncoding(req.Header()),
+			}
+			ev.Attempt = scope.PreviousAttempts(ev.RequestMetadata)
+			ev.HTTPPath = scope.ForwardedPath(ev.RequestMetadata)
+			ev.Deadline, _ = ctx.Deadline()
+			ev.DeadlineSource = i.s.DeadlineSource(ctx)
+			ev.Labels = i.s.Labels(ctx)
+
+			ev.ResponseContentType = unaryResponseContentType(req, resp, err)
+			if !req.Spec().IsClient {
+				recordBodySize(&ev, req)
+			}
+
+			if err != nil {
+				code := codeOf(err)
+				ev.StatusCode = domain.StatusCode(code + 1) // +1 for Unspecified offset
+				ev.StatusMessage = err.Error()
+				ev.StatusDetails = statusDetails(err)
+			} else {
+				ev.StatusCode = domain.StatusOK
+				ev.NilResponse = scope.IsNil(resp) || scope.IsNil(resp.Any())
+			}
 
-		if err != nil {
-			code := connect.CodeOf(err)
-			ev.StatusCode = domain.StatusCode(code + 1) // +1 for Unspecified offset
-			ev.StatusMessage = err.Error()
-		} else {
-			ev.StatusCode = domain.StatusOK
-			ev.ResponsePayload = scope.MarshalPayload(resp.Any())
+			if i.s.CapturePayload(ev) {
+				if ev.Direction == domain.DirectionInbound {
+					ev.SchemaWarnings = i.s.SchemaWarnings(req.Any())
+				}
+				ev.RequestPayload = i.s.Marshal(req.Any())
+				ev.RequestBytesRaw = i.s.MarshalRaw(req.Any())
+				if err == nil && !ev.NilResponse {
+					ev.ResponsePayload = i.s.Marshal(resp.Any())
+				}
+			}
+			ev.TotalDuration = time.Since(entered)
+
+			i.s.Publish(ev)
 		}
 
-		i.s.Publish(ev)
+		resp, err := func() (connect.AnyResponse, error) {
+			defer i.s.RecoverPanic(func(v any) {
+				publish(nil, connect.NewError(connect.CodeInternal, errors.New(scope.PanicMessage(v))))
+			})
+			return next(ctx, req)
+		}()
+		publish(resp, err)
 
 		return resp, err
 	}
 }
 
-func (i *interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
-	return next
-}
-
 func (i *interceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
 	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		observeLocalAddr(ctx, i.s)
+		md := extractHeaders(conn.RequestHeader())
+		if !i.s.ShouldCapture(conn.Spec().Procedure, md) {
+			return next(ctx, conn)
+		}
+
 		start := time.Now()
 
-		err := next(ctx, conn)
+		ctx = scope.Suppressible(ctx)
+		cc := &countingHandlerConn{StreamingHandlerConn: conn}
+		publish := func(err error) {
+			if scope.Suppressed(ctx) {
+				return
+			}
 
-		ev := domain.CallEvent{
-			ID:              i.s.GenerateID(),
-			Method:          conn.Spec().Procedure,
-			StartTime:       start,
-			Duration:        time.Since(start),
-			RequestMetadata: extractHeaders(conn.RequestHeader()),
-		}
+			ev := domain.CallEvent{
+				ID:              i.s.GenerateID(),
+				Method:          conn.Spec().Procedure,
+				StartTime:       start,
+				Duration:        time.Since(start),
+				RequestMetadata: md,
+				Direction:       domain.DirectionInbound,
+				Protocol:        conn.Peer().Protocol,
+				ContentEncoding: contentEncoding(conn.RequestHeader()),
+			}
+			ev.ResponseContentType = conn.ResponseHeader().Get("Content-Type")
+			if ev.ResponseContentType == "" {
+				ev.ResponseContentType = conn.RequestHeader().Get("Content-Type")
+			}
+			ev.Attempt = scope.PreviousAttempts(ev.RequestMetadata)
+			ev.HTTPPath = scope.ForwardedPath(ev.RequestMetadata)
+			ev.Deadline, _ = ctx.Deadline()
+			ev.DeadlineSource = i.s.DeadlineSource(ctx)
+			ev.Labels = i.s.Labels(ctx)
+			ev.Streaming = true
+			ev.SentCount = cc.sent.Load()
+			ev.RecvCount = cc.recv.Load()
 
-		if err != nil {
-			code := connect.CodeOf(err)
-			ev.StatusCode = domain.StatusCode(code + 1)
-			ev.StatusMessage = err.Error()
-		} else {
-			ev.StatusCode = domain.StatusOK
+			if err != nil {
+				code := codeOf(err)
+				ev.StatusCode = domain.StatusCode(code + 1)
+				ev.StatusMessage = err.Error()
+				ev.StatusDetails = statusDetails(err)
+			} else {
+				ev.StatusCode = domain.StatusOK
+			}
+
+			i.s.Publish(ev)
 		}
 
-		i.s.Publish(ev)
+		err := func() error {
+			defer i.s.RecoverPanic(func(v any) {
+				publish(connect.NewError(connect.CodeInternal, errors.New(scope.PanicMessage(v))))
+			})
+			return next(ctx, cc)
+		}()
+		publish(err)
 
 		return err
 	}
 }
 
+// countingHandlerConn counts the messages a streaming handler sends and
+// receives.
+type countingHandlerConn struct {
+	connect.StreamingHandlerConn
+	sent, recv atomic.Int64
+}
+
+func (c *countingHandlerConn) Send(msg any) error {
+	err := c.StreamingHandlerConn.Send(msg)
+	if err == nil {
+		c.sent.Add(1)
+	}
+	return err
+}
+
+func (c *countingHandlerConn) Receive(msg any) error {
+	err := c.StreamingHandlerConn.Receive(msg)
+	if err == nil {
+		c.recv.Add(1)
+	}
+	return err
+}
+
+// direction reports whether spec describes a call made by a client or one
+// received by a handler; WrapUnary runs on both sides.
+func direction(spec connect.Spec) domain.Direction {
+	if spec.IsClient {
+		return domain.DirectionOutbound
+	}
+	return domain.DirectionInbound
+}
+
+// unaryResponseContentType returns the Content-Type of a unary response.
+// Clients see the server's header. Handlers answer in the request's codec,
+// except that Connect unary errors are always JSON; Connect sets the header
+// only after interceptors run, so it is derived here.
+func unaryResponseContentType(req connect.AnyRequest, resp connect.AnyResponse, err error) string {
+	if req.Spec().IsClient {
+		if resp != nil {
+			return resp.Header().Get("Content-Type")
+		}
+		var connectErr *connect.Error
+		if errors.As(err, &connectErr) {
+			return connectErr.Meta().Get("Content-Type")
+		}
+		return ""
+	}
+	if err != nil && req.Peer().Protocol == connect.ProtocolConnect {
+		return "application/json"
+	}
+	return req.Header().Get("Content-Type")
+}
+
+// recordBodySize compares the Content-Length a handled request declared with
+// the size its decoded message encodes to, and flags any mismatch on ev.
+// Connect hands interceptors the decoded message, not the bytes read, so a
+// valid but non-canonical encoding, such as a repeated scalar field or an
+// unpacked repeated field, is flagged as well; the flag is a hint to look
+// for a proxy rewriting the body, not proof of one.
+func recordBodySize(ev *domain.CallEvent, req connect.AnyRequest) {
+	declared, err := strconv.ParseInt(req.Header().Get("Content-Length"), 10, 64)
+	if err != nil {
+		return
+	}
+	ev.RequestContentLength = declared
+	ev.RequestBodySize = requestBodySize(req)
+	ev.SizeMismatch = ev.RequestBodySize > 0 && ev.RequestBodySize != declared
+}
+
+// requestBodySize returns the size of the HTTP body that req's message
+// encodes to, or 0 when it cannot be derived: for compressed requests and
+// codecs other than proto, whose encoding is not canonical.
+func requestBodySize(req connect.AnyRequest) int64 {
+	msg, ok := req.Any().(proto.Message)
+	if !ok || contentEncoding(req.Header()) != "" {
+		return 0
+	}
+	size := int64(proto.Size(msg))
+	switch req.Header().Get("Content-Type") {
+	case "application/proto":
+		return size
+	case "application/grpc", "application/grpc+proto", "application/grpc-web", "application/grpc-web+proto":
+		return size + 5 // length-prefixed message envelope
+	default:
+		return 0
+	}
+}
+
+// encodingHeaders lists the request headers that carry the compression a
+// client chose, for Connect unary, Connect streaming, and gRPC respectively.
+var encodingHeaders = []string{"Content-Encoding", "Connect-Content-Encoding", "Grpc-Encoding"}
+
+// codeOf is connect.CodeOf for a call that ended with err, except that a bare
+// context error, e.g. a handler returning ctx.Err() after its client went
+// away, maps to the code Connect sends for it rather than CodeUnknown. A
+// client whose own deadline expires cancels the call, which may reach the
+// server before the server's copy of the deadline fires; that call is
+// recorded as CodeCanceled, as the server saw it.
+func codeOf(err error) connect.Code {
+	var cerr *connect.Error
+	switch {
+	case errors.As(err, &cerr):
+		return cerr.Code()
+	case errors.Is(err, context.Canceled):
+		return connect.CodeCanceled
+	case errors.Is(err, context.DeadlineExceeded):
+		return connect.CodeDeadlineExceeded
+	}
+	return connect.CodeUnknown
+}
+
+// statusDetails returns the details attached to a Connect error as JSON.
+func statusDetails(err error) []string {
+	var cerr *connect.Error
+	if !errors.As(err, &cerr) {
+		return nil
+	}
+	details := make([]*anypb.Any, 0, len(cerr.Details()))
+	for _, d := range cerr.Details() {
+		details = append(details, &anypb.Any{TypeUrl: "type.googleapis.com/" + d.Type(), Value: d.Bytes()})
+	}
+	return scope.MarshalStatusDetails(details)
+}
+
+// contentEncoding returns the request compression negotiated by the client,
+// or "" if the request was not compressed.
+func contentEncoding(h http.Header) string {
+	for _, k := range encodingHeaders {
+		if v := h.Get(k); v != "" && v != "identity" {
+			return v
+		}
+	}
+	return ""
+}
+
 func extractHeaders(h map[string][]string) domain.Metadata {
 	if len(h) == 0 {
 		return nil
@@ -122,3 +566,11 @@ func extractHeaders(h map[string][]string) domain.Metadata {
 	}
 	return out
 }
+
+// observeLocalAddr offers the address the HTTP server accepted ctx's call on
+// to s as the application's address.
+func observeLocalAddr(ctx context.Context, s *scope.Scope) {
+	if addr, ok := ctx.Value(http.LocalAddrContextKey).(net.Addr); ok {
+		s.ObserveLocalAddr(addr)
+	}
+}
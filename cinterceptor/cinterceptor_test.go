@@ -1,22 +1,26 @@
 package cinterceptor_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"time"
 
 	"connectrpc.com/connect"
 	"github.com/mickamy/grpc-scope/cinterceptor"
+	"github.com/mickamy/grpc-scope/scope/domain"
 	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-func setupTest(t *testing.T) (scopev1.ScopeServiceClient, *cinterceptor.Scope, string) {
+func setupTest(t *testing.T, opts ...cinterceptor.Option) (scopev1.ScopeServiceClient, *cinterceptor.Scope, string) {
 	t.Helper()
 
 	scopeLis, err := net.Listen("tcp", "localhost:0")
@@ -26,7 +30,7 @@ func setupTest(t *testing.T) (scopev1.ScopeServiceClient, *cinterceptor.Scope, s
 	scopePort := scopeLis.Addr().(*net.TCPAddr).Port
 	_ = scopeLis.Close()
 
-	scope, err := cinterceptor.New(cinterceptor.WithPort(scopePort))
+	scope, err := cinterceptor.New(append([]cinterceptor.Option{cinterceptor.WithPort(scopePort)}, opts...)...)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -40,6 +44,17 @@ func setupTest(t *testing.T) (scopev1.ScopeServiceClient, *cinterceptor.Scope, s
 		},
 		connect.WithInterceptors(scope.Interceptor()),
 	))
+	mux.Handle("/test.TestService/Fail", connect.NewUnaryHandler(
+		"/test.TestService/Fail",
+		func(_ context.Context, _ *connect.Request[scopev1.WatchRequest]) (*connect.Response[scopev1.WatchResponse], error) {
+			err := connect.NewError(connect.CodeInternal, fmt.Errorf("failed"))
+			if detail, derr := connect.NewErrorDetail(&errdetails.ErrorInfo{Reason: "DB_DOWN"}); derr == nil {
+				err.AddDetail(detail)
+			}
+			return nil, err
+		},
+		connect.WithInterceptors(scope.Interceptor()),
+	))
 	mux.Handle("/test.TestService/Stream", connect.NewServerStreamHandler(
 		"/test.TestService/Stream",
 		func(_ context.Context, _ *connect.Request[scopev1.WatchRequest], _ *connect.ServerStream[scopev1.WatchResponse]) error {
@@ -47,8 +62,22 @@ func setupTest(t *testing.T) (scopev1.ScopeServiceClient, *cinterceptor.Scope, s
 		},
 		connect.WithInterceptors(scope.Interceptor()),
 	))
+	mux.Handle("/test.TestService/Hang", connect.NewServerStreamHandler(
+		"/test.TestService/Hang",
+		func(ctx context.Context, _ *connect.Request[scopev1.WatchRequest], stream *connect.ServerStream[scopev1.WatchResponse]) error {
+			if err := stream.Send(&scopev1.WatchResponse{}); err != nil {
+				return err
+			}
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		connect.WithInterceptors(scope.Interceptor()),
+	))
 
-	srv := httptest.NewServer(mux)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Proto", r.Proto)
+		mux.ServeHTTP(w, r)
+	}))
 	t.Cleanup(srv.Close)
 
 	scopeConn, err := grpc.NewClient(
@@ -115,6 +144,415 @@ func TestUnaryInterceptor_CapturesCall(t *testing.T) {
 	if ev.GetDuration().AsDuration() <= 0 {
 		t.Error("expected positive duration")
 	}
+	if total := ev.GetTotalDuration().AsDuration(); total < ev.GetDuration().AsDuration() {
+		t.Errorf("got total duration %s, want at least the handler duration %s", total, ev.GetDuration().AsDuration())
+	}
+}
+
+func TestUnaryInterceptor_CapturesStatusDetails(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	scopeClient, scope, serverURL := setupTest(t)
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	client := connect.NewClient[scopev1.WatchRequest, scopev1.WatchResponse](
+		http.DefaultClient,
+		serverURL+"/test.TestService/Fail",
+	)
+	if _, err := client.CallUnary(ctx, connect.NewRequest(&scopev1.WatchRequest{})); err == nil {
+		t.Fatal("expected error from Fail")
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"DB_DOWN"}`
+	details := resp.GetEvent().GetStatusDetails()
+	if len(details) != 1 || strings.ReplaceAll(details[0], " ", "") != want {
+		t.Errorf("got status details %q, want [%s]", details, want)
+	}
+}
+
+func TestUnaryInterceptor_CapturesHTTP1Call(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	scopeClient, scope, serverURL := setupTest(t)
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	// Restrict the client to HTTP/1.1, as seen behind most reverse proxies.
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	httpClient := &http.Client{Transport: &http.Transport{Protocols: protocols}}
+	t.Cleanup(httpClient.CloseIdleConnections)
+
+	client := connect.NewClient[scopev1.WatchRequest, scopev1.WatchResponse](
+		httpClient,
+		serverURL+"/test.TestService/Echo",
+	)
+	res, err := client.CallUnary(ctx, connect.NewRequest(&scopev1.WatchRequest{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := res.Header().Get("X-Proto"); got != "HTTP/1.1" {
+		t.Fatalf("got protocol %q, want %q", got, "HTTP/1.1")
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := resp.GetEvent()
+	if ev.GetMethod() != "/test.TestService/Echo" {
+		t.Errorf("got method %q, want %q", ev.GetMethod(), "/test.TestService/Echo")
+	}
+	if ev.GetStatusCode() != 1 { // domain.StatusOK
+		t.Errorf("got status code %d, want %d", ev.GetStatusCode(), 1)
+	}
+	if ev.GetResponsePayload() == "" {
+		t.Error("expected response payload to be captured")
+	}
+}
+
+func TestUnaryInterceptor_CapturesProtocol(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []connect.ClientOption
+		want string
+	}{
+		{name: "connect", want: domain.ProtocolConnect},
+		{name: "grpc-web", opts: []connect.ClientOption{connect.WithGRPCWeb()}, want: domain.ProtocolGRPCWeb},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			scopeClient, scope, serverURL := setupTest(t)
+
+			stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			waitForSubscriber(t, scope, 1)
+
+			client := connect.NewClient[scopev1.WatchRequest, scopev1.WatchResponse](
+				http.DefaultClient,
+				serverURL+"/test.TestService/Echo",
+				tt.opts...,
+			)
+			if _, err := client.CallUnary(ctx, connect.NewRequest(&scopev1.WatchRequest{})); err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := resp.GetEvent().GetProtocol(); got != tt.want {
+				t.Errorf("got protocol %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInterceptor_CapturesGRPCWebCall(t *testing.T) {
+	t.Parallel()
+
+	unary := func(ctx context.Context, url string, opts ...connect.ClientOption) error {
+		client := connect.NewClient[scopev1.WatchRequest, scopev1.WatchResponse](http.DefaultClient, url, opts...)
+		_, err := client.CallUnary(ctx, connect.NewRequest(&scopev1.WatchRequest{}))
+		return err
+	}
+	serverStream := func(ctx context.Context, url string, opts ...connect.ClientOption) error {
+		client := connect.NewClient[scopev1.WatchRequest, scopev1.WatchResponse](http.DefaultClient, url, opts...)
+		stream, err := client.CallServerStream(ctx, connect.NewRequest(&scopev1.WatchRequest{}))
+		if err != nil {
+			return err
+		}
+		defer stream.Close()
+		for stream.Receive() {
+			// drain
+		}
+		return stream.Err()
+	}
+
+	tests := []struct {
+		name          string
+		method        string
+		call          func(ctx context.Context, url string, opts ...connect.ClientOption) error
+		client        bool // capture on the client rather than the handler
+		wantStatus    int32
+		wantMessage   string
+		wantDirection scopev1.Direction
+		wantStreaming bool
+	}{
+		{
+			name:          "unary",
+			method:        "/test.TestService/Echo",
+			call:          unary,
+			wantStatus:    1, // domain.StatusOK
+			wantDirection: scopev1.Direction_DIRECTION_INBOUND,
+		},
+		{
+			name:          "unary error",
+			method:        "/test.TestService/Fail",
+			call:          unary,
+			wantStatus:    int32(connect.CodeInternal) + 1,
+			wantMessage:   "internal: failed",
+			wantDirection: scopev1.Direction_DIRECTION_INBOUND,
+		},
+		{
+			name:          "server stream",
+			method:        "/test.TestService/Stream",
+			call:          serverStream,
+			wantStatus:    int32(connect.CodeUnimplemented) + 1,
+			wantMessage:   "unimplemented: not implemented",
+			wantDirection: scopev1.Direction_DIRECTION_INBOUND,
+			wantStreaming: true,
+		},
+		{
+			name:          "client server stream",
+			method:        "/test.TestService/Stream",
+			call:          serverStream,
+			client:        true,
+			wantStatus:    int32(connect.CodeAborted) + 1,
+			wantMessage:   "aborted: aborted",
+			wantDirection: scopev1.Direction_DIRECTION_OUTBOUND,
+			wantStreaming: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			scopeClient, scope, serverURL := setupTest(t)
+			opts := []connect.ClientOption{connect.WithGRPCWeb()}
+			if tt.client {
+				serverURL = startPlainStreamServer(t, true)
+				opts = append(opts, connect.WithInterceptors(scope.Interceptor()))
+			}
+
+			stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			waitForSubscriber(t, scope, 1)
+
+			err = tt.call(ctx, serverURL+tt.method, opts...)
+			if (err == nil) != (tt.wantStatus == 1) {
+				t.Fatalf("got call error %v, want status code %d", err, tt.wantStatus)
+			}
+
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ev := resp.GetEvent()
+			if ev.GetMethod() != tt.method {
+				t.Errorf("got method %q, want %q", ev.GetMethod(), tt.method)
+			}
+			if ev.GetProtocol() != domain.ProtocolGRPCWeb {
+				t.Errorf("got protocol %q, want %q", ev.GetProtocol(), domain.ProtocolGRPCWeb)
+			}
+			if ev.GetDirection() != tt.wantDirection {
+				t.Errorf("got direction %s, want %s", ev.GetDirection(), tt.wantDirection)
+			}
+			if ev.GetStatusCode() != tt.wantStatus {
+				t.Errorf("got status code %d, want %d", ev.GetStatusCode(), tt.wantStatus)
+			}
+			if ev.GetStatusMessage() != tt.wantMessage {
+				t.Errorf("got status message %q, want %q", ev.GetStatusMessage(), tt.wantMessage)
+			}
+			if ev.GetStreaming() != tt.wantStreaming {
+				t.Errorf("got streaming %v, want %v", ev.GetStreaming(), tt.wantStreaming)
+			}
+			if got := ev.GetResponseContentType(); got != "application/grpc-web+proto" {
+				t.Errorf("got response content type %q, want %q", got, "application/grpc-web+proto")
+			}
+		})
+	}
+}
+
+func TestUnaryInterceptor_CapturesContentEncoding(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	scopeClient, scope, serverURL := setupTest(t)
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	client := connect.NewClient[scopev1.WatchRequest, scopev1.WatchResponse](
+		http.DefaultClient,
+		serverURL+"/test.TestService/Echo",
+		connect.WithSendGzip(),
+	)
+	_, err = client.CallUnary(ctx, connect.NewRequest(&scopev1.WatchRequest{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := resp.GetEvent()
+	if ev.GetContentEncoding() != "gzip" {
+		t.Errorf("got content encoding %q, want %q", ev.GetContentEncoding(), "gzip")
+	}
+	if ev.GetStatusCode() != 1 { // domain.StatusOK
+		t.Errorf("got status code %d, want %d", ev.GetStatusCode(), 1)
+	}
+}
+
+func TestUnaryInterceptor_CapturesResponseContentType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		path            string
+		opts            []connect.ClientOption
+		wantRequestType string
+		wantRespType    string
+	}{
+		{
+			name:            "proto request, proto response",
+			path:            "/test.TestService/Echo",
+			wantRequestType: "application/proto",
+			wantRespType:    "application/proto",
+		},
+		{
+			name:            "json request, json response",
+			path:            "/test.TestService/Echo",
+			opts:            []connect.ClientOption{connect.WithProtoJSON()},
+			wantRequestType: "application/json",
+			wantRespType:    "application/json",
+		},
+		{
+			// Connect sends unary errors as JSON whatever the request codec.
+			name:            "proto request, json error response",
+			path:            "/test.TestService/Fail",
+			wantRequestType: "application/proto",
+			wantRespType:    "application/json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			scopeClient, scope, serverURL := setupTest(t)
+
+			stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			waitForSubscriber(t, scope, 1)
+
+			client := connect.NewClient[scopev1.WatchRequest, scopev1.WatchResponse](
+				http.DefaultClient,
+				serverURL+tt.path,
+				tt.opts...,
+			)
+			_, _ = client.CallUnary(ctx, connect.NewRequest(&scopev1.WatchRequest{}))
+
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ev := resp.GetEvent()
+			if got := ev.GetRequestMetadata()["Content-Type"].GetValues(); len(got) != 1 || got[0] != tt.wantRequestType {
+				t.Errorf("got request content type %v, want %q", got, tt.wantRequestType)
+			}
+			if got := ev.GetResponseContentType(); got != tt.wantRespType {
+				t.Errorf("got response content type %q, want %q", got, tt.wantRespType)
+			}
+		})
+	}
+}
+
+func TestUnaryInterceptor_CapturesSizeMismatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		body         []byte
+		wantBodySize int64
+		wantMismatch bool
+	}{
+		{
+			name:         "canonical body",
+			body:         []byte{0x08, 0x02}, // batch_size: 2
+			wantBodySize: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			scopeClient, scope, serverURL := setupTest(t)
+
+			stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			waitForSubscriber(t, scope, 1)
+
+			httpResp, err := http.Post(serverURL+"/test.TestService/Echo", "application/proto", bytes.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_ = httpResp.Body.Close()
+
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ev := resp.GetEvent()
+			if got := ev.GetRequestContentLength(); got != int64(len(tt.body)) {
+				t.Errorf("got content length %d, want %d", got, len(tt.body))
+			}
+			if got := ev.GetRequestBodySize(); got != tt.wantBodySize {
+				t.Errorf("got body size %d, want %d", got, tt.wantBodySize)
+			}
+			if got := ev.GetSizeMismatch(); got != tt.wantMismatch {
+				t.Errorf("got size mismatch %v, want %v", got, tt.wantMismatch)
+			}
+		})
+	}
 }
 
 func TestStreamInterceptor_CapturesCall(t *testing.T) {
@@ -161,4 +599,229 @@ func TestStreamInterceptor_CapturesCall(t *testing.T) {
 	if ev.GetDuration().AsDuration() <= 0 {
 		t.Error("expected positive duration")
 	}
+	if !ev.GetStreaming() || ev.GetSentCount() != 0 || ev.GetRecvCount() != 1 {
+		t.Errorf("got streaming %v, %d sent, %d received; want a stream that received its request and sent nothing",
+			ev.GetStreaming(), ev.GetSentCount(), ev.GetRecvCount())
+	}
+}
+
+func TestStreamInterceptor_CapturesClientGone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		timeout    string // Connect-Timeout-Ms sent without a client-side deadline
+		wantStatus domain.StatusCode
+	}{
+		{
+			name:       "client cancels",
+			wantStatus: domain.StatusCancelled,
+		},
+		{
+			// The client never cancels, so only the server's copy of the
+			// deadline ends the call.
+			name:       "deadline expires",
+			timeout:    "200",
+			wantStatus: domain.StatusDeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			scopeClient, scope, serverURL := setupTest(t)
+
+			stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			waitForSubscriber(t, scope, 1)
+
+			client := connect.NewClient[scopev1.WatchRequest, scopev1.WatchResponse](
+				http.DefaultClient,
+				serverURL+"/test.TestService/Hang",
+			)
+			callCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			req := connect.NewRequest(&scopev1.WatchRequest{})
+			if tt.timeout != "" {
+				req.Header().Set("Connect-Timeout-Ms", tt.timeout)
+			}
+			serverStream, err := client.CallServerStream(callCtx, req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer serverStream.Close()
+			// The first message shows the handler is running before the
+			// client goes away.
+			if !serverStream.Receive() {
+				t.Fatalf("expected a first message, got error %v", serverStream.Err())
+			}
+			if tt.timeout == "" {
+				cancel()
+			}
+			for serverStream.Receive() {
+				// drain
+			}
+
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := domain.StatusCode(resp.GetEvent().GetStatusCode()); got != tt.wantStatus {
+				t.Errorf("got status %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// handlerConn is a connect.StreamingHandlerConn for the interceptor to
+// inspect; its handlers panic before sending or receiving anything.
+type handlerConn struct {
+	connect.StreamingHandlerConn
+	header http.Header
+}
+
+func (c *handlerConn) Spec() connect.Spec {
+	return connect.Spec{Procedure: "/test.TestService/Stream", StreamType: connect.StreamTypeServer}
+}
+func (c *handlerConn) Peer() connect.Peer          { return connect.Peer{Protocol: connect.ProtocolConnect} }
+func (c *handlerConn) RequestHeader() http.Header  { return c.header }
+func (c *handlerConn) ResponseHeader() http.Header { return c.header }
+
+func TestInterceptor_RecoverPanics(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		recover bool
+		stream  bool
+	}{
+		{name: "unary", recover: true},
+		{name: "stream", recover: true, stream: true},
+		{name: "unary without the option"},
+		{name: "stream without the option", stream: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			events := make(chan domain.CallEvent, 1)
+			opts := []cinterceptor.Option{
+				cinterceptor.WithPort(0),
+				cinterceptor.WithProcessor(func(ev *domain.CallEvent) { events <- *ev }),
+			}
+			if tt.recover {
+				opts = append(opts, cinterceptor.WithRecoverPanics())
+			}
+			scope, err := cinterceptor.New(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(scope.Close)
+
+			call := func() {
+				if tt.stream {
+					next := func(context.Context, connect.StreamingHandlerConn) error { panic("boom") }
+					_ = scope.Interceptor().WrapStreamingHandler(next)(t.Context(), &handlerConn{header: http.Header{}})
+					return
+				}
+				next := func(context.Context, connect.AnyRequest) (connect.AnyResponse, error) { panic("boom") }
+				_, _ = scope.Interceptor().WrapUnary(next)(t.Context(), connect.NewRequest(&scopev1.WatchRequest{}))
+			}
+			func() {
+				defer func() {
+					// The panic reaches the caller either way.
+					if v := recover(); v != "boom" {
+						t.Errorf("got panic %v, want boom", v)
+					}
+				}()
+				call()
+			}()
+
+			if !tt.recover {
+				select {
+				case ev := <-events:
+					t.Errorf("expected no event without WithRecoverPanics, got %s", ev.Method)
+				default:
+				}
+				return
+			}
+			select {
+			case ev := <-events:
+				if want := domain.StatusCode(connect.CodeInternal + 1); ev.StatusCode != want {
+					t.Errorf("got status %s, want %s", ev.StatusCode, want)
+				}
+				if want := "internal: panic: boom"; ev.StatusMessage != want {
+					t.Errorf("got status message %q, want %q", ev.StatusMessage, want)
+				}
+			default:
+				t.Fatal("expected the call to be recorded")
+			}
+		})
+	}
+}
+
+func TestUnaryInterceptor_PayloadSampleRate(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	scopeClient, scope, serverURL := setupTest(t, cinterceptor.WithPayloadSampleRate(0.5))
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	echo := connect.NewClient[scopev1.WatchRequest, scopev1.WatchResponse](
+		http.DefaultClient,
+		serverURL+"/test.TestService/Echo",
+	)
+	fail := connect.NewClient[scopev1.WatchRequest, scopev1.WatchResponse](
+		http.DefaultClient,
+		serverURL+"/test.TestService/Fail",
+	)
+
+	const okCalls, failCalls = 4, 2
+	for range okCalls {
+		if _, err := echo.CallUnary(ctx, connect.NewRequest(&scopev1.WatchRequest{})); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for range failCalls {
+		if _, err := fail.CallUnary(ctx, connect.NewRequest(&scopev1.WatchRequest{})); err == nil {
+			t.Fatal("expected error from Fail")
+		}
+	}
+
+	okWithPayload, failWithPayload := 0, 0
+	for range okCalls + failCalls {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ev := resp.GetEvent()
+		if ev.GetMethod() == "" || ev.GetStatusCode() == 0 || ev.GetDuration() == nil {
+			t.Errorf("expected envelope for every call, got %v", ev)
+		}
+		if ev.GetRequestPayload() == "" {
+			continue
+		}
+		if ev.GetMethod() == "/test.TestService/Fail" {
+			failWithPayload++
+		} else {
+			okWithPayload++
+		}
+	}
+
+	if okWithPayload != okCalls/2 {
+		t.Errorf("got %d successful calls with payloads, want %d", okWithPayload, okCalls/2)
+	}
+	if failWithPayload != failCalls {
+		t.Errorf("got %d failed calls with payloads, want %d", failWithPayload, failCalls)
+	}
 }
@@ -0,0 +1,134 @@
+package cinterceptor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"connectrpc.com/connect"
+	"github.com/mickamy/grpc-scope/scope"
+	"github.com/mickamy/grpc-scope/scope/domain"
+)
+
+// WrapStreamingClient captures outgoing streams as events with
+// DirectionOutbound. The event is published when the stream ends, as seen by
+// the caller's Receive, or when the response is closed. Its response
+// payload holds the received messages as a capped JSON array, or the single
+// response of a client stream.
+func (i *interceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		conn := next(ctx, spec)
+		if conn == nil || scope.Suppressed(ctx) {
+			return conn
+		}
+
+		cc := &capturingClientConn{
+			StreamingClientConn: conn,
+			s:                   i.s,
+			start:               time.Now(),
+		}
+		cc.deadline, _ = ctx.Deadline()
+		cc.labels = i.s.Labels(ctx)
+		if spec.StreamType == connect.StreamTypeServer || spec.StreamType == connect.StreamTypeBidi {
+			cc.rec = i.s.NewStreamRecorder()
+		}
+		return cc
+	}
+}
+
+// capturingClientConn publishes its event once the stream ends.
+type capturingClientConn struct {
+	connect.StreamingClientConn
+	s        *scope.Scope
+	start    time.Time
+	deadline time.Time
+	labels   map[string]string
+	rec      *scope.StreamRecorder // non-nil for server and bidi streams
+	response string                // the single response of a client stream
+	once     sync.Once
+
+	sent, recv atomic.Int64
+}
+
+func (cc *capturingClientConn) Send(msg any) error {
+	err := cc.StreamingClientConn.Send(msg)
+	if err == nil {
+		cc.sent.Add(1)
+	}
+	return err
+}
+
+func (cc *capturingClientConn) Receive(msg any) error {
+	err := cc.StreamingClientConn.Receive(msg)
+	if err == nil {
+		cc.recv.Add(1)
+	}
+	switch {
+	case err == nil && cc.rec != nil:
+		cc.rec.Record(msg)
+	case err == nil:
+		// A single response ends a client-streaming call.
+		cc.response = cc.s.Marshal(msg)
+		cc.finish(nil)
+	case errors.Is(err, io.EOF):
+		cc.finish(nil)
+	default:
+		cc.finish(err)
+	}
+	return err
+}
+
+func (cc *capturingClientConn) CloseResponse() error {
+	err := cc.StreamingClientConn.CloseResponse()
+	cc.finish(nil)
+	return err
+}
+
+func (cc *capturingClientConn) finish(err error) {
+	cc.once.Do(func() {
+		spec := cc.Spec()
+		md := extractHeaders(cc.RequestHeader())
+		if !cc.s.ShouldCapture(spec.Procedure, md) {
+			return
+		}
+
+		ev := domain.CallEvent{
+			ID:              cc.s.GenerateID(),
+			Method:          spec.Procedure,
+			StartTime:       cc.start,
+			Duration:        time.Since(cc.start),
+			RequestMetadata: md,
+			Direction:       domain.DirectionOutbound,
+			Protocol:        cc.Peer().Protocol,
+			Deadline:        cc.deadline,
+			Labels:          cc.labels,
+			Streaming:       true,
+			SentCount:       cc.sent.Load(),
+			RecvCount:       cc.recv.Load(),
+		}
+		ev.Attempt = scope.PreviousAttempts(ev.RequestMetadata)
+		ev.ResponseContentType = cc.ResponseHeader().Get("Content-Type")
+
+		if err != nil {
+			code := connect.CodeOf(err)
+			ev.StatusCode = domain.StatusCode(code + 1)
+			ev.StatusMessage = err.Error()
+			ev.StatusDetails = statusDetails(err)
+		} else {
+			ev.StatusCode = domain.StatusOK
+		}
+
+		if cc.s.CapturePayload(ev) {
+			if cc.rec != nil {
+				ev.ResponsePayload = cc.rec.Payload()
+			} else {
+				ev.ResponsePayload = cc.response
+			}
+		}
+
+		cc.s.Publish(ev)
+	})
+}
@@ -0,0 +1,171 @@
+package cinterceptor_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"connectrpc.com/connect"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+)
+
+// startPlainStreamServer serves a server-streaming handler without the scope
+// interceptor, so only client-side events are published.
+func startPlainStreamServer(t *testing.T, fail bool) string {
+	t.Helper()
+
+	mux := http.NewServeMux()
+	mux.Handle("/test.TestService/Stream", connect.NewServerStreamHandler(
+		"/test.TestService/Stream",
+		func(_ context.Context, _ *connect.Request[scopev1.WatchRequest], stream *connect.ServerStream[scopev1.WatchResponse]) error {
+			for _, id := range []string{"first", "second"} {
+				if err := stream.Send(&scopev1.WatchResponse{Event: &scopev1.CallEvent{Id: id}}); err != nil {
+					return err
+				}
+			}
+			if fail {
+				return connect.NewError(connect.CodeAborted, errors.New("aborted"))
+			}
+			return nil
+		},
+	))
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func TestStreamingClientInterceptor_CapturesCall(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		fail       bool
+		wantStatus int32
+	}{
+		{name: "ok", wantStatus: 1}, // domain.StatusOK
+		{name: "error", fail: true, wantStatus: int32(connect.CodeAborted) + 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			scopeClient, scope, _ := setupTest(t)
+			serverURL := startPlainStreamServer(t, tt.fail)
+
+			stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			waitForSubscriber(t, scope, 1)
+
+			client := connect.NewClient[scopev1.WatchRequest, scopev1.WatchResponse](
+				http.DefaultClient,
+				serverURL+"/test.TestService/Stream",
+				connect.WithInterceptors(scope.Interceptor()),
+			)
+			serverStream, err := client.CallServerStream(ctx, connect.NewRequest(&scopev1.WatchRequest{}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for serverStream.Receive() {
+				// drain
+			}
+			_ = serverStream.Close()
+
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			ev := resp.GetEvent()
+			if ev.GetMethod() != "/test.TestService/Stream" {
+				t.Errorf("got method %q, want %q", ev.GetMethod(), "/test.TestService/Stream")
+			}
+			if ev.GetDirection() != scopev1.Direction_DIRECTION_OUTBOUND {
+				t.Errorf("got direction %s, want DIRECTION_OUTBOUND", ev.GetDirection())
+			}
+			if ev.GetStatusCode() != tt.wantStatus {
+				t.Errorf("got status code %d, want %d", ev.GetStatusCode(), tt.wantStatus)
+			}
+			if ev.GetDuration().AsDuration() <= 0 {
+				t.Error("expected positive duration")
+			}
+			if got := ev.GetResponseContentType(); got != "application/connect+proto" {
+				t.Errorf("got response content type %q, want %q", got, "application/connect+proto")
+			}
+			payload := ev.GetResponsePayload()
+			if !strings.Contains(payload, "first") || !strings.Contains(payload, "second") {
+				t.Errorf("got response payload %q, want both streamed messages", payload)
+			}
+		})
+	}
+}
+
+func TestStreamingClientInterceptor_CapturesClientStreamResponse(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	scopeClient, scope, _ := setupTest(t)
+
+	mux := http.NewServeMux()
+	mux.Handle("/test.TestService/Upload", connect.NewClientStreamHandler(
+		"/test.TestService/Upload",
+		func(_ context.Context, stream *connect.ClientStream[scopev1.WatchRequest]) (*connect.Response[scopev1.WatchResponse], error) {
+			n := 0
+			for stream.Receive() {
+				n++
+			}
+			if err := stream.Err(); err != nil {
+				return nil, err
+			}
+			return connect.NewResponse(&scopev1.WatchResponse{Dropped: uint64(n)}), nil
+		},
+	))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	client := connect.NewClient[scopev1.WatchRequest, scopev1.WatchResponse](
+		http.DefaultClient,
+		srv.URL+"/test.TestService/Upload",
+		connect.WithInterceptors(scope.Interceptor()),
+	)
+	upload := client.CallClientStream(ctx)
+	for range 3 {
+		if err := upload.Send(&scopev1.WatchRequest{BatchSize: 1}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := upload.CloseAndReceive(); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := resp.GetEvent()
+	if ev.GetMethod() != "/test.TestService/Upload" {
+		t.Errorf("got method %q, want %q", ev.GetMethod(), "/test.TestService/Upload")
+	}
+	if ev.GetDirection() != scopev1.Direction_DIRECTION_OUTBOUND {
+		t.Errorf("got direction %s, want DIRECTION_OUTBOUND", ev.GetDirection())
+	}
+	if got := ev.GetResponsePayload(); !strings.Contains(got, `"dropped":"3"`) {
+		t.Errorf("got response payload %q, want the single response", got)
+	}
+}
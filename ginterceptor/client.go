@@ -0,0 +1,202 @@
+package ginterceptor
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mickamy/grpc-scope/scope"
+	"github.com/mickamy/grpc-scope/scope/domain"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ClientUnaryInterceptor returns a gRPC unary client interceptor that captures
+// outgoing calls as events with DirectionOutbound.
+func (s *Scope) ClientUnaryInterceptor() grpc.UnaryClientInterceptor {
+	return func(
+		ctx context.Context,
+		method string,
+		req, reply any,
+		cc *grpc.ClientConn,
+		invoker grpc.UnaryInvoker,
+		opts ...grpc.CallOption,
+	) error {
+		entered := time.Now()
+		md := extractOutgoingMetadata(ctx)
+		if !s.scope.ShouldCapture(method, md) || scope.Suppressed(ctx) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+
+		start := time.Now()
+
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		ev := domain.CallEvent{
+			ID:              s.scope.GenerateID(),
+			Method:          method,
+			StartTime:       start,
+			Duration:        time.Since(start),
+			RequestMetadata: md,
+			Direction:       domain.DirectionOutbound,
+			Protocol:        domain.ProtocolGRPC,
+		}
+		ev.Attempt = scope.PreviousAttempts(ev.RequestMetadata)
+		ev.Deadline, _ = ctx.Deadline()
+		ev.DeadlineSource = s.scope.DeadlineSource(ctx)
+		ev.Labels = s.scope.Labels(ctx)
+
+		st, _ := status.FromError(err)
+		ev.StatusCode = domain.StatusCode(st.Code() + 1) // +1 for Unspecified offset
+		ev.StatusMessage = st.Message()
+		ev.StatusDetails = scope.MarshalStatusDetails(st.Proto().GetDetails())
+
+		if s.scope.CapturePayload(ev) {
+			ev.RequestPayload = s.scope.Marshal(req)
+			ev.RequestBytesRaw = s.scope.MarshalRaw(req)
+			if err == nil {
+				ev.ResponsePayload = s.scope.Marshal(reply)
+			}
+		}
+		ev.TotalDuration = time.Since(entered)
+
+		s.scope.Publish(ev)
+
+		return err
+	}
+}
+
+// ClientStreamInterceptor returns a gRPC stream client interceptor that
+// captures outgoing streams as events with DirectionOutbound. The event is
+// published when the stream ends, as seen by the caller's RecvMsg.
+func (s *Scope) ClientStreamInterceptor() grpc.StreamClientInterceptor {
+	return func(
+		ctx context.Context,
+		desc *grpc.StreamDesc,
+		cc *grpc.ClientConn,
+		method string,
+		streamer grpc.Streamer,
+		opts ...grpc.CallOption,
+	) (grpc.ClientStream, error) {
+		md := extractOutgoingMetadata(ctx)
+		if !s.scope.ShouldCapture(method, md) || scope.Suppressed(ctx) {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+
+		cs := &capturingClientStream{
+			s:     s.scope,
+			start: time.Now(),
+			ev: domain.CallEvent{
+				Method:          method,
+				RequestMetadata: md,
+				Direction:       domain.DirectionOutbound,
+				Protocol:        domain.ProtocolGRPC,
+			},
+		}
+		cs.ev.Attempt = scope.PreviousAttempts(md)
+		cs.ev.Deadline, _ = ctx.Deadline()
+		cs.ev.DeadlineSource = s.scope.DeadlineSource(ctx)
+		cs.ev.Labels = s.scope.Labels(ctx)
+		if desc.ServerStreams {
+			cs.rec = s.scope.NewStreamRecorder()
+		}
+
+		stream, err := streamer(ctx, desc, cc, method, opts...)
+		if err != nil {
+			cs.finish(err)
+			return nil, err
+		}
+		cs.ClientStream = stream
+		return cs, nil
+	}
+}
+
+// UnaryClientInterceptor is ClientUnaryInterceptor under the name of the
+// grpc.UnaryClientInterceptor type it returns.
+func (s *Scope) UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return s.ClientUnaryInterceptor()
+}
+
+// StreamClientInterceptor is ClientStreamInterceptor under the name of the
+// grpc.StreamClientInterceptor type it returns.
+func (s *Scope) StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return s.ClientStreamInterceptor()
+}
+
+// capturingClientStream publishes its event once the stream ends.
+type capturingClientStream struct {
+	grpc.ClientStream
+	s     *scope.Scope
+	start time.Time
+	ev    domain.CallEvent
+	rec   *scope.StreamRecorder // non-nil for server-streaming methods
+	once  sync.Once
+
+	sent, recv atomic.Int64
+}
+
+func (cs *capturingClientStream) SendMsg(m any) error {
+	err := cs.ClientStream.SendMsg(m)
+	if err == nil {
+		cs.sent.Add(1)
+	}
+	return err
+}
+
+func (cs *capturingClientStream) RecvMsg(m any) error {
+	err := cs.ClientStream.RecvMsg(m)
+	if err == nil {
+		cs.recv.Add(1)
+	}
+	switch {
+	case err == nil && cs.rec != nil:
+		cs.rec.Record(m)
+	case err == nil:
+		// A single response ends a client-streaming call.
+		cs.finish(nil)
+	case errors.Is(err, io.EOF):
+		cs.finish(nil)
+	default:
+		cs.finish(err)
+	}
+	return err
+}
+
+func (cs *capturingClientStream) finish(err error) {
+	cs.once.Do(func() {
+		ev := cs.ev
+		ev.ID = cs.s.GenerateID()
+		ev.StartTime = cs.start
+		ev.Duration = time.Since(cs.start)
+		ev.Streaming = true
+		ev.SentCount = cs.sent.Load()
+		ev.RecvCount = cs.recv.Load()
+
+		st, _ := status.FromError(err)
+		ev.StatusCode = domain.StatusCode(st.Code() + 1)
+		ev.StatusMessage = st.Message()
+		ev.StatusDetails = scope.MarshalStatusDetails(st.Proto().GetDetails())
+
+		if cs.rec != nil && cs.s.CapturePayload(ev) {
+			ev.ResponsePayload = cs.rec.Payload()
+		}
+
+		cs.s.Publish(ev)
+	})
+}
+
+func extractOutgoingMetadata(ctx context.Context) domain.Metadata {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		return nil
+	}
+	out := make(domain.Metadata, len(md))
+	for k, vs := range md {
+		out[k] = vs
+	}
+	return out
+}
@@ -0,0 +1,221 @@
+package ginterceptor_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/mickamy/grpc-scope/ginterceptor"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// setupClientTest starts an app server without interceptors and returns a
+// connection to it that goes through the client interceptors.
+func setupClientTest(t *testing.T, svc scopev1.ScopeServiceServer) (*grpc.ClientConn, scopev1.ScopeServiceClient, *ginterceptor.Scope) {
+	t.Helper()
+
+	scopeLis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	scopePort := scopeLis.Addr().(*net.TCPAddr).Port
+	_ = scopeLis.Close()
+
+	// The app serves health and scope methods, which are ignored by default.
+	scope, err := ginterceptor.New(ginterceptor.WithPort(scopePort), ginterceptor.WithIgnoreMethods())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(scope.Close)
+
+	srv := grpc.NewServer()
+	scopev1.RegisterScopeServiceServer(srv, svc)
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+
+	appLis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		if err := srv.Serve(appLis); err != nil {
+			// server stopped
+		}
+	}()
+	t.Cleanup(srv.GracefulStop)
+
+	appConn, err := grpc.NewClient(
+		appLis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(scope.ClientUnaryInterceptor()),
+		grpc.WithStreamInterceptor(scope.ClientStreamInterceptor()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = appConn.Close() })
+
+	scopeConn, err := grpc.NewClient(
+		fmt.Sprintf("localhost:%d", scopePort),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = scopeConn.Close() })
+
+	return appConn, scopev1.NewScopeServiceClient(scopeConn), scope
+}
+
+func TestClientUnaryInterceptor_CapturesCall(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	appConn, scopeClient, scope := setupClientTest(t, &testService{})
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	if _, err := healthpb.NewHealthClient(appConn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := resp.GetEvent()
+	if ev.GetMethod() != "/grpc.health.v1.Health/Check" {
+		t.Errorf("got method %q, want %q", ev.GetMethod(), "/grpc.health.v1.Health/Check")
+	}
+	if ev.GetDirection() != scopev1.Direction_DIRECTION_OUTBOUND {
+		t.Errorf("got direction %s, want DIRECTION_OUTBOUND", ev.GetDirection())
+	}
+	if ev.GetStatusCode() != 1 { // domain.StatusOK
+		t.Errorf("got status code %d, want %d", ev.GetStatusCode(), 1)
+	}
+	if !strings.Contains(ev.GetResponsePayload(), "SERVING") {
+		t.Errorf("got response payload %q, want SERVING status", ev.GetResponsePayload())
+	}
+	if total := ev.GetTotalDuration().AsDuration(); total < ev.GetDuration().AsDuration() {
+		t.Errorf("got total duration %s, want at least the invoker duration %s", total, ev.GetDuration().AsDuration())
+	}
+}
+
+func TestClientStreamInterceptor_CapturesCall(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	svc := &testService{responses: []*scopev1.WatchResponse{
+		{Event: &scopev1.CallEvent{Id: "first"}},
+		{Event: &scopev1.CallEvent{Id: "second"}},
+	}}
+	appConn, scopeClient, scope := setupClientTest(t, svc)
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	watchStream, err := scopev1.NewScopeServiceClient(appConn).Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := watchStream.Recv(); err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := resp.GetEvent()
+	if ev.GetMethod() != "/scope.v1.ScopeService/Watch" {
+		t.Errorf("got method %q, want %q", ev.GetMethod(), "/scope.v1.ScopeService/Watch")
+	}
+	if ev.GetDirection() != scopev1.Direction_DIRECTION_OUTBOUND {
+		t.Errorf("got direction %s, want DIRECTION_OUTBOUND", ev.GetDirection())
+	}
+	if ev.GetStatusCode() != 1 { // domain.StatusOK
+		t.Errorf("got status code %d, want %d", ev.GetStatusCode(), 1)
+	}
+	payload := ev.GetResponsePayload()
+	if !strings.Contains(payload, "first") || !strings.Contains(payload, "second") {
+		t.Errorf("got response payload %q, want both streamed messages", payload)
+	}
+}
+
+func TestClientInterceptorAliases_CaptureCalls(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	svc := &testService{responses: []*scopev1.WatchResponse{{Event: &scopev1.CallEvent{Id: "only"}}}}
+	appConn, scopeClient, scope := setupClientTest(t, svc)
+
+	conn, err := grpc.NewClient(
+		appConn.Target(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithUnaryInterceptor(scope.UnaryClientInterceptor()),
+		grpc.WithStreamInterceptor(scope.StreamClientInterceptor()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForSubscriber(t, scope, 1)
+
+	if _, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{}); err != nil {
+		t.Fatal(err)
+	}
+	watchStream, err := scopev1.NewScopeServiceClient(conn).Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for {
+		if _, err := watchStream.Recv(); err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.Fatal(err)
+			}
+			break
+		}
+	}
+
+	for _, want := range []string{"/grpc.health.v1.Health/Check", "/scope.v1.ScopeService/Watch"} {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		ev := resp.GetEvent()
+		if ev.GetMethod() != want {
+			t.Errorf("got method %q, want %q", ev.GetMethod(), want)
+		}
+		if ev.GetDirection() != scopev1.Direction_DIRECTION_OUTBOUND {
+			t.Errorf("%s: got direction %s, want DIRECTION_OUTBOUND", want, ev.GetDirection())
+		}
+	}
+}
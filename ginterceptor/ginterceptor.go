@@ -2,12 +2,19 @@ package ginterceptor
 
 import (
 	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync/atomic"
 	"time"
 
 	"github.com/mickamy/grpc-scope/scope"
 	"github.com/mickamy/grpc-scope/scope/domain"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/status"
 )
 
@@ -19,6 +26,194 @@ func WithPort(port int) Option {
 	return scope.WithPort(port)
 }
 
+// WithBindAddr sets the host the internal gRPC server listens on (default 127.0.0.1).
+// Use "0.0.0.0" to let monitors on other hosts connect.
+func WithBindAddr(host string) Option {
+	return scope.WithBindAddr(host)
+}
+
+// WithUnixSocket makes the internal gRPC server listen on a Unix domain socket instead of a TCP port.
+func WithUnixSocket(path string) Option {
+	return scope.WithUnixSocket(path)
+}
+
+// WithBufferSize sets how many events are buffered for each Watch subscriber before events are dropped.
+func WithBufferSize(n int) Option {
+	return scope.WithBufferSize(n)
+}
+
+// WithBlockingPublish makes capturing wait up to timeout for a slow Watch subscriber instead of dropping events.
+// While a subscriber is behind, every captured call is delayed by up to timeout.
+func WithBlockingPublish(timeout time.Duration) Option {
+	return scope.WithBlockingPublish(timeout)
+}
+
+// WithMaxRepeatedElements limits every JSON array in captured payloads to its first n elements.
+func WithMaxRepeatedElements(n int) Option {
+	return scope.WithMaxRepeatedElements(n)
+}
+
+// WithMaxShownElements makes monitors show only the first n elements of every JSON array in payloads.
+func WithMaxShownElements(n int) Option {
+	return scope.WithMaxShownElements(n)
+}
+
+// WithMaxPayloadSize sets the size budget, in bytes, for capturing a single payload.
+func WithMaxPayloadSize(n int) Option {
+	return scope.WithMaxPayloadSize(n)
+}
+
+// WithMaxBytesFieldSize replaces bytes fields longer than n bytes in captured payloads with a "<N bytes>" placeholder.
+func WithMaxBytesFieldSize(n int) Option {
+	return scope.WithMaxBytesFieldSize(n)
+}
+
+// WithRawRequestBytes also captures unary requests in their protobuf wire encoding, which replays send instead of JSON.
+func WithRawRequestBytes() Option {
+	return scope.WithRawRequestBytes()
+}
+
+// WithPayloadSampleRate captures payloads for only the given fraction of successful calls.
+func WithPayloadSampleRate(rate float64) Option {
+	return scope.WithPayloadSampleRate(rate)
+}
+
+// WithIgnoreMethods skips capturing methods matching any of patterns, replacing
+// the default list of health check, reflection, and scope methods.
+func WithIgnoreMethods(patterns ...string) Option {
+	return scope.WithIgnoreMethods(patterns...)
+}
+
+// WithCaptureOnly captures only methods matching any of patterns, even ignored ones.
+func WithCaptureOnly(patterns ...string) Option {
+	return scope.WithCaptureOnly(patterns...)
+}
+
+// CaptureFilter decides per call whether it is captured.
+type CaptureFilter = scope.CaptureFilter
+
+// WithCaptureFilter skips capturing calls for which fn returns false.
+func WithCaptureFilter(fn CaptureFilter) Option {
+	return scope.WithCaptureFilter(fn)
+}
+
+// IDFormat builds the ID of a captured event from its sequence number and the event.
+type IDFormat = scope.IDFormat
+
+// WithIDFormat replaces the default "call-N" event IDs with the ones fn returns.
+func WithIDFormat(fn IDFormat) Option {
+	return scope.WithIDFormat(fn)
+}
+
+// Processor enriches or rewrites a captured event before it is published.
+type Processor = scope.Processor
+
+// WithProcessor runs fn on every captured event before it is published, after any processors added earlier.
+func WithProcessor(fn Processor) Option {
+	return scope.WithProcessor(fn)
+}
+
+// PayloadMarshaler formats a captured request or response for display.
+type PayloadMarshaler = scope.PayloadMarshaler
+
+// WithPayloadMarshaler replaces the default JSON formatting of captured payloads with fn.
+func WithPayloadMarshaler(fn PayloadMarshaler) Option {
+	return scope.WithPayloadMarshaler(fn)
+}
+
+// WithDeadlineSourceKey records on each event whether its deadline came from the client or from an interceptor
+// that stored a marker in the context under key.
+func WithDeadlineSourceKey(key any) Option {
+	return scope.WithDeadlineSourceKey(key)
+}
+
+// LabelExtractor returns labels describing a call from its context.
+type LabelExtractor = scope.LabelExtractor
+
+// WithLabelExtractor records the labels fn returns for each call on its event.
+func WithLabelExtractor(fn LabelExtractor) Option {
+	return scope.WithLabelExtractor(fn)
+}
+
+// WithPersistPath saves captured events to path on Close and restores them on New.
+func WithPersistPath(path string) Option {
+	return scope.WithPersistPath(path)
+}
+
+// WithRecoverPanics records calls whose handler panicked, then lets the panic continue.
+func WithRecoverPanics() Option {
+	return scope.WithRecoverPanics()
+}
+
+// WithHistorySize keeps the last n captured events in memory for GetHistory queries.
+func WithHistorySize(n int) Option {
+	return scope.WithHistorySize(n)
+}
+
+// WithHeartbeatInterval sets how long a Watch stream may idle before a heartbeat is sent.
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return scope.WithHeartbeatInterval(interval)
+}
+
+// WithLingerOnClose makes Close wait up to d for watching TUI clients to disconnect.
+func WithLingerOnClose(d time.Duration) Option {
+	return scope.WithLingerOnClose(d)
+}
+
+// WithAppTarget sets the application address the monitor replays calls to when given none.
+func WithAppTarget(addr string) Option {
+	return scope.WithAppTarget(addr)
+}
+
+// WithServeErrorHandler calls fn when the internal server stops serving before Close.
+func WithServeErrorHandler(fn func(error)) Option {
+	return scope.WithServeErrorHandler(fn)
+}
+
+// WithDisableServer captures events without binding any port; read them with Subscribe.
+func WithDisableServer() Option {
+	return scope.WithDisableServer()
+}
+
+// LogFormat selects how WithStderrLog writes events.
+type LogFormat = scope.LogFormat
+
+// Formats accepted by WithStderrLog.
+const (
+	LogFormatJSON   = scope.LogFormatJSON
+	LogFormatLogfmt = scope.LogFormatLogfmt
+)
+
+// WithStderrLog also writes every captured event to stderr as a one-line JSON or logfmt log.
+func WithStderrLog(format LogFormat) Option {
+	return scope.WithStderrLog(format)
+}
+
+// WithHTTPPort also streams captured events as JSON server-sent events on GET /events at port.
+func WithHTTPPort(port int) Option {
+	return scope.WithHTTPPort(port)
+}
+
+// WithAuthToken requires TUI clients to present token before they can watch traffic.
+func WithAuthToken(token string) Option {
+	return scope.WithAuthToken(token)
+}
+
+// WithLogger makes the scope log diagnostics, such as payload marshal failures and dropped events, to logger.
+func WithLogger(logger *slog.Logger) Option {
+	return scope.WithLogger(logger)
+}
+
+// WithRuntimeStats records the application's goroutine count on every captured event.
+func WithRuntimeStats(enabled bool) Option {
+	return scope.WithRuntimeStats(enabled)
+}
+
+// Suppress keeps the call handled under ctx, and outbound calls made with ctx afterwards, from being captured.
+func Suppress(ctx context.Context) {
+	scope.Suppress(ctx)
+}
+
 // Scope captures gRPC traffic and exposes it via an internal gRPC server.
 type Scope struct {
 	scope *scope.Scope
@@ -33,11 +228,45 @@ func New(opts ...Option) (*Scope, error) {
 	return &Scope{scope: s}, nil
 }
 
+// Addr returns the address the internal gRPC server listens on.
+func (s *Scope) Addr() net.Addr {
+	return s.scope.Addr()
+}
+
+// HTTPAddr returns the address the server-sent events endpoint listens on, or nil if it is disabled.
+func (s *Scope) HTTPAddr() net.Addr {
+	return s.scope.HTTPAddr()
+}
+
 // SubscriberCount returns the number of active Watch subscribers.
 func (s *Scope) SubscriberCount() int {
 	return s.scope.SubscriberCount()
 }
 
+// DroppedEvents returns the number of events dropped because a Watch subscriber fell behind.
+func (s *Scope) DroppedEvents() uint64 {
+	return s.scope.DroppedEvents()
+}
+
+// AppTarget returns the application address reported to the monitor, or "" if it is not known yet.
+func (s *Scope) AppTarget() string {
+	return s.scope.AppTarget()
+}
+
+// Subscribe returns a channel of the events captured in this process and a function that unsubscribes.
+func (s *Scope) Subscribe() (<-chan domain.CallEvent, func()) {
+	return s.scope.Subscribe()
+}
+
+// HandlerPath is the URL path prefix to mount Scope.Handler on.
+const HandlerPath = scope.HandlerPath
+
+// Handler returns the scope server as an http.Handler to mount at HandlerPath on an HTTP/2 server.
+// It serves gRPC over HTTP/2 (h2 or h2c) only, not the Connect protocol, gRPC-Web or HTTP/1.1.
+func (s *Scope) Handler() http.Handler {
+	return s.scope.Handler()
+}
+
 // Close stops the internal gRPC server.
 func (s *Scope) Close() {
 	s.scope.Close()
@@ -51,25 +280,61 @@ func (s *Scope) UnaryInterceptor() grpc.UnaryServerInterceptor {
 		info *grpc.UnaryServerInfo,
 		handler grpc.UnaryHandler,
 	) (any, error) {
+		entered := time.Now()
+		s.observeLocalAddr(ctx)
+		md := extractMetadata(ctx)
+		if !s.scope.ShouldCapture(info.FullMethod, md) {
+			return handler(ctx, req)
+		}
+
 		start := time.Now()
 
-		resp, err := handler(ctx, req)
+		ctx = scope.Suppressible(ctx)
+		publish := func(resp any, err error) {
+			if scope.Suppressed(ctx) {
+				return
+			}
 
-		ev := domain.CallEvent{
-			ID:              s.scope.GenerateID(),
-			Method:          info.FullMethod,
-			StartTime:       start,
-			Duration:        time.Since(start),
-			RequestMetadata: extractMetadata(ctx),
-			RequestPayload:  scope.MarshalPayload(req),
-			ResponsePayload: scope.MarshalPayload(resp),
-		}
+			ev := domain.CallEvent{
+				ID:              s.scope.GenerateID(),
+				Method:          info.FullMethod,
+				StartTime:       start,
+				Duration:        time.Since(start),
+				RequestMetadata: md,
+				Direction:       domain.DirectionInbound,
+				Protocol:        domain.ProtocolGRPC,
+			}
+			ev.Attempt = scope.PreviousAttempts(ev.RequestMetadata)
+			ev.HTTPPath = scope.ForwardedPath(ev.RequestMetadata)
+			ev.Deadline, _ = ctx.Deadline()
+			ev.DeadlineSource = s.scope.DeadlineSource(ctx)
+			ev.Labels = s.scope.Labels(ctx)
+			ev.PeerIdentity = peerIdentity(ctx)
+
+			st := statusOf(err)
+			ev.StatusCode = domain.StatusCode(st.Code() + 1) // +1 for Unspecified offset
+			ev.StatusMessage = st.Message()
+			ev.StatusDetails = scope.MarshalStatusDetails(st.Proto().GetDetails())
+			ev.NilResponse = err == nil && scope.IsNil(resp)
 
-		st, _ := status.FromError(err)
-		ev.StatusCode = domain.StatusCode(st.Code() + 1) // +1 for Unspecified offset
-		ev.StatusMessage = st.Message()
+			if s.scope.CapturePayload(ev) {
+				ev.SchemaWarnings = s.scope.SchemaWarnings(req)
+				ev.RequestPayload = s.scope.Marshal(req)
+				ev.RequestBytesRaw = s.scope.MarshalRaw(req)
+				ev.ResponsePayload = s.scope.Marshal(resp)
+			}
+			ev.TotalDuration = time.Since(entered)
+
+			s.scope.Publish(ev)
+		}
 
-		s.scope.Publish(ev)
+		resp, err := func() (any, error) {
+			defer s.scope.RecoverPanic(func(v any) {
+				publish(nil, status.Error(codes.Internal, scope.PanicMessage(v)))
+			})
+			return handler(ctx, req)
+		}()
+		publish(resp, err)
 
 		return resp, err
 	}
@@ -83,28 +348,149 @@ func (s *Scope) StreamInterceptor() grpc.StreamServerInterceptor {
 		info *grpc.StreamServerInfo,
 		handler grpc.StreamHandler,
 	) error {
-		start := time.Now()
+		s.observeLocalAddr(ss.Context())
+		md := extractMetadata(ss.Context())
+		if !s.scope.ShouldCapture(info.FullMethod, md) {
+			return handler(srv, ss)
+		}
 
-		err := handler(srv, ss)
+		start := time.Now()
 
-		ev := domain.CallEvent{
-			ID:              s.scope.GenerateID(),
-			Method:          info.FullMethod,
-			StartTime:       start,
-			Duration:        time.Since(start),
-			RequestMetadata: extractMetadata(ss.Context()),
+		ss = &suppressibleStream{ServerStream: ss, ctx: scope.Suppressible(ss.Context())}
+		var rec *scope.StreamRecorder
+		if info.IsServerStream {
+			rec = s.scope.NewStreamRecorder()
 		}
+		rs := &recordingStream{ServerStream: ss, rec: rec}
 
-		st, _ := status.FromError(err)
-		ev.StatusCode = domain.StatusCode(st.Code() + 1)
-		ev.StatusMessage = st.Message()
+		publish := func(err error) {
+			if scope.Suppressed(ss.Context()) {
+				return
+			}
 
-		s.scope.Publish(ev)
+			ev := domain.CallEvent{
+				ID:              s.scope.GenerateID(),
+				Method:          info.FullMethod,
+				StartTime:       start,
+				Duration:        time.Since(start),
+				RequestMetadata: md,
+				Direction:       domain.DirectionInbound,
+				Protocol:        domain.ProtocolGRPC,
+			}
+			ev.Attempt = scope.PreviousAttempts(ev.RequestMetadata)
+			ev.HTTPPath = scope.ForwardedPath(ev.RequestMetadata)
+			ev.Deadline, _ = ss.Context().Deadline()
+			ev.DeadlineSource = s.scope.DeadlineSource(ss.Context())
+			ev.Labels = s.scope.Labels(ss.Context())
+			ev.PeerIdentity = peerIdentity(ss.Context())
+			ev.Streaming = true
+			ev.SentCount = rs.sent.Load()
+			ev.RecvCount = rs.recv.Load()
+
+			st := statusOf(err)
+			ev.StatusCode = domain.StatusCode(st.Code() + 1)
+			ev.StatusMessage = st.Message()
+			ev.StatusDetails = scope.MarshalStatusDetails(st.Proto().GetDetails())
+
+			if rec != nil && s.scope.CapturePayload(ev) {
+				ev.ResponsePayload = rec.Payload()
+			}
+
+			s.scope.Publish(ev)
+		}
+
+		err := func() error {
+			defer s.scope.RecoverPanic(func(v any) {
+				publish(status.Error(codes.Internal, scope.PanicMessage(v)))
+			})
+			return handler(srv, rs)
+		}()
+		publish(err)
 
 		return err
 	}
 }
 
+// statusOf returns the status of a call that ended with err. Unlike
+// status.FromError, it maps a bare context error, e.g. a handler returning
+// ctx.Err() after its client went away, to Canceled or DeadlineExceeded
+// rather than Unknown. A client whose own deadline expires cancels the call,
+// which may reach the server before the server's copy of the deadline fires;
+// that call is recorded as Canceled, as the server saw it.
+func statusOf(err error) *status.Status {
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+	return status.FromContextError(err)
+}
+
+// observeLocalAddr offers the address ctx's call arrived on to the scope as
+// the application's address.
+func (s *Scope) observeLocalAddr(ctx context.Context) {
+	if p, ok := peer.FromContext(ctx); ok {
+		s.scope.ObserveLocalAddr(p.LocalAddr)
+	}
+}
+
+// peerIdentity returns the identity of the client whose certificate the TLS
+// handshake of ctx's call verified: a SPIFFE ID from its URI SANs, or else its
+// subject. It returns "" for plaintext calls and unverified clients.
+func peerIdentity(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return ""
+	}
+	info, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(info.State.VerifiedChains) == 0 || len(info.State.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	leaf := info.State.VerifiedChains[0][0]
+	for _, uri := range leaf.URIs {
+		if uri.Scheme == "spiffe" {
+			return uri.String()
+		}
+	}
+	return leaf.Subject.String()
+}
+
+// suppressibleStream hands the handler a context in which scope.Suppress
+// takes effect.
+type suppressibleStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *suppressibleStream) Context() context.Context {
+	return s.ctx
+}
+
+// recordingStream counts the messages the handler sends and receives, and
+// records those it sends when rec is set.
+type recordingStream struct {
+	grpc.ServerStream
+	rec        *scope.StreamRecorder // non-nil for server-streaming methods
+	sent, recv atomic.Int64
+}
+
+func (s *recordingStream) SendMsg(m any) error {
+	err := s.ServerStream.SendMsg(m)
+	if err == nil {
+		s.sent.Add(1)
+		if s.rec != nil {
+			s.rec.Record(m)
+		}
+	}
+	return err
+}
+
+func (s *recordingStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.recv.Add(1)
+	}
+	return err
+}
+
 func extractMetadata(ctx context.Context) domain.Metadata {
 	md, ok := metadata.FromIncomingContext(ctx)
 	if !ok {
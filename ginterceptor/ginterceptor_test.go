@@ -1,29 +1,70 @@
 package ginterceptor_test
 
 import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"math/big"
 	"net"
+	"net/url"
+	"slices"
 	"testing"
 	"time"
 
 	"github.com/mickamy/grpc-scope/ginterceptor"
+	"github.com/mickamy/grpc-scope/scope/domain"
 	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
 )
 
 type testService struct {
 	scopev1.UnimplementedScopeServiceServer
+	responses []*scopev1.WatchResponse // sent by Watch; Unimplemented if empty
+	err       error                    // returned by Watch instead, if set
 }
 
-func (t *testService) Watch(_ *scopev1.WatchRequest, _ grpc.ServerStreamingServer[scopev1.WatchResponse]) error {
-	return status.Error(codes.Unimplemented, "not implemented")
+func (t *testService) Watch(_ *scopev1.WatchRequest, stream grpc.ServerStreamingServer[scopev1.WatchResponse]) error {
+	if t.err != nil {
+		return t.err
+	}
+	if len(t.responses) == 0 {
+		return status.Error(codes.Unimplemented, "not implemented")
+	}
+	for _, resp := range t.responses {
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func setupTest(t *testing.T, opts ...ginterceptor.Option) (scopev1.ScopeServiceClient, scopev1.ScopeServiceClient, *ginterceptor.Scope) {
+	t.Helper()
+	return setupTestWithService(t, &testService{}, opts...)
 }
 
-func setupTest(t *testing.T) (scopev1.ScopeServiceClient, scopev1.ScopeServiceClient, *ginterceptor.Scope) {
+func setupTestWithService(
+	t *testing.T,
+	svc scopev1.ScopeServiceServer,
+	opts ...ginterceptor.Option,
+) (scopev1.ScopeServiceClient, scopev1.ScopeServiceClient, *ginterceptor.Scope) {
 	t.Helper()
 
 	// Find a free port for the scope server
@@ -34,7 +75,9 @@ func setupTest(t *testing.T) (scopev1.ScopeServiceClient, scopev1.ScopeServiceCl
 	scopePort := scopeLis.Addr().(*net.TCPAddr).Port
 	_ = scopeLis.Close()
 
-	scope, err := ginterceptor.New(ginterceptor.WithPort(scopePort))
+	// The test app serves the scope service, whose methods are ignored by default.
+	base := []ginterceptor.Option{ginterceptor.WithPort(scopePort), ginterceptor.WithIgnoreMethods()}
+	scope, err := ginterceptor.New(append(base, opts...)...)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -45,7 +88,7 @@ func setupTest(t *testing.T) (scopev1.ScopeServiceClient, scopev1.ScopeServiceCl
 		grpc.UnaryInterceptor(scope.UnaryInterceptor()),
 		grpc.StreamInterceptor(scope.StreamInterceptor()),
 	)
-	scopev1.RegisterScopeServiceServer(srv, &testService{})
+	scopev1.RegisterScopeServiceServer(srv, svc)
 
 	appLis, err := net.Listen("tcp", "localhost:0")
 	if err != nil {
@@ -138,7 +181,1045 @@ func TestStreamInterceptor_CapturesCall(t *testing.T) {
 	if ev.GetStatusCode() != int32(codes.Unimplemented)+1 { // +1 for Unspecified offset
 		t.Errorf("got status code %d, want %d", ev.GetStatusCode(), int32(codes.Unimplemented)+1)
 	}
+	if ev.GetDirection() != scopev1.Direction_DIRECTION_INBOUND {
+		t.Errorf("got direction %s, want DIRECTION_INBOUND", ev.GetDirection())
+	}
+	if ev.GetProtocol() != domain.ProtocolGRPC {
+		t.Errorf("got protocol %q, want %q", ev.GetProtocol(), domain.ProtocolGRPC)
+	}
+	if id := ev.GetPeerIdentity(); id != "" {
+		t.Errorf("got peer identity %q for a plaintext call, want none", id)
+	}
 	if ev.GetDuration().AsDuration() <= 0 {
 		t.Error("expected positive duration")
 	}
 }
+
+func TestStreamInterceptor_CapturesStatusDetails(t *testing.T) {
+	t.Parallel()
+
+	st, err := status.New(codes.InvalidArgument, "invalid request").WithDetails(&errdetails.BadRequest{
+		FieldViolations: []*errdetails.BadRequest_FieldViolation{
+			{Field: "batch_size", Description: "must be positive"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := t.Context()
+	appClient, scopeClient, scope := setupTestWithService(t, &testService{err: st.Err()})
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	watchStream, err := appClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watchStream.Recv(); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("got error %v, want InvalidArgument", err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	details := resp.GetEvent().GetStatusDetails()
+	if len(details) != 1 {
+		t.Fatalf("got %d status details, want 1", len(details))
+	}
+	var got struct {
+		Type            string `json:"@type"`
+		FieldViolations []struct {
+			Field       string `json:"field"`
+			Description string `json:"description"`
+		} `json:"fieldViolations"`
+	}
+	if err := json.Unmarshal([]byte(details[0]), &got); err != nil {
+		t.Fatalf("status detail %q is not JSON: %v", details[0], err)
+	}
+	if got.Type != "type.googleapis.com/google.rpc.BadRequest" {
+		t.Errorf("got detail type %q, want google.rpc.BadRequest", got.Type)
+	}
+	if len(got.FieldViolations) != 1 || got.FieldViolations[0].Field != "batch_size" {
+		t.Errorf("got field violations %+v, want one for batch_size", got.FieldViolations)
+	}
+}
+
+// sensitiveService suppresses capture of Watch calls marked x-sensitive.
+type sensitiveService struct {
+	scopev1.UnimplementedScopeServiceServer
+}
+
+func (sensitiveService) Watch(_ *scopev1.WatchRequest, stream grpc.ServerStreamingServer[scopev1.WatchResponse]) error {
+	md, _ := metadata.FromIncomingContext(stream.Context())
+	if len(md.Get("x-sensitive")) > 0 {
+		ginterceptor.Suppress(stream.Context())
+	}
+	return status.Error(codes.Unimplemented, "not implemented")
+}
+
+func TestStreamInterceptor_Suppress(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	appClient, scopeClient, scope := setupTestWithService(t, sensitiveService{})
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	for _, md := range []metadata.MD{
+		metadata.Pairs("x-sensitive", "1"),
+		metadata.Pairs("x-call", "plain"),
+	} {
+		watchStream, err := appClient.Watch(metadata.NewOutgoingContext(ctx, md), &scopev1.WatchRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := watchStream.Recv(); status.Code(err) != codes.Unimplemented {
+			t.Fatalf("got error %v, want Unimplemented", err)
+		}
+	}
+
+	// Events arrive in order, so the first one shows whether the sensitive
+	// call was published.
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := resp.GetEvent().GetRequestMetadata()
+	if _, ok := got["x-sensitive"]; ok {
+		t.Fatal("expected the suppressed call not to be captured")
+	}
+	if _, ok := got["x-call"]; !ok {
+		t.Errorf("expected the plain call to be captured, got metadata %v", got)
+	}
+}
+
+func TestUnaryInterceptor_FlagsNilResponse(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	_, scopeClient, scope := setupTest(t)
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	tests := []struct {
+		name    string
+		resp    any
+		err     error
+		wantNil bool
+	}{
+		{name: "nil response and nil error", resp: nil, wantNil: true},
+		{name: "typed nil response", resp: (*scopev1.WatchResponse)(nil), wantNil: true},
+		{name: "response", resp: &scopev1.WatchResponse{}},
+		{name: "error", err: status.Error(codes.NotFound, "missing")},
+	}
+	interceptor := scope.UnaryInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Test/Get"}
+	for _, tt := range tests {
+		handler := func(context.Context, any) (any, error) { return tt.resp, tt.err }
+		if _, err := interceptor(ctx, &scopev1.WatchRequest{}, info, handler); !errors.Is(err, tt.err) {
+			t.Fatalf("%s: got error %v, want %v", tt.name, err, tt.err)
+		}
+	}
+
+	// Events arrive in the order the calls were made.
+	for _, tt := range tests {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.GetEvent().GetNilResponse(); got != tt.wantNil {
+			t.Errorf("%s: NilResponse = %v, want %v", tt.name, got, tt.wantNil)
+		}
+	}
+}
+
+func TestUnaryInterceptor_RecordsSchemaWarnings(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	_, scopeClient, scope := setupTest(t)
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	// A client built from a newer schema sends field 99, which this server
+	// does not know.
+	wire := protowire.AppendTag(nil, 1, protowire.VarintType)
+	wire = protowire.AppendVarint(wire, 10)
+	wire = protowire.AppendTag(wire, 99, protowire.VarintType)
+	wire = protowire.AppendVarint(wire, 7)
+	req := &scopev1.WatchRequest{}
+	if err := proto.Unmarshal(wire, req); err != nil {
+		t.Fatal(err)
+	}
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Test/Get"}
+	handler := func(context.Context, any) (any, error) { return &scopev1.WatchResponse{}, nil }
+	if _, err := scope.UnaryInterceptor()(ctx, req, info, handler); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"unknown field 99 in scope.v1.WatchRequest"}
+	if got := resp.GetEvent().GetSchemaWarnings(); !slices.Equal(got, want) {
+		t.Errorf("got schema warnings %q, want %q", got, want)
+	}
+}
+
+func TestUnaryInterceptor_SkipsSchemaWarningsWithoutPayload(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	_, scopeClient, scope := setupTest(t, ginterceptor.WithPayloadSampleRate(0))
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	req := &scopev1.WatchRequest{}
+	req.ProtoReflect().SetUnknown(protowire.AppendVarint(protowire.AppendTag(nil, 99, protowire.VarintType), 7))
+
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Test/Get"}
+	handler := func(context.Context, any) (any, error) { return &scopev1.WatchResponse{}, nil }
+	if _, err := scope.UnaryInterceptor()(ctx, req, info, handler); err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The sampled-out request is not captured, so its schema is not checked.
+	if got := resp.GetEvent().GetSchemaWarnings(); got != nil {
+		t.Errorf("got schema warnings %q for a call without payloads, want none", got)
+	}
+}
+
+type tenantKey struct{}
+
+func TestUnaryInterceptor_CapturesLabels(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	_, scopeClient, scope := setupTest(t, ginterceptor.WithLabelExtractor(func(ctx context.Context) map[string]string {
+		tenant, ok := ctx.Value(tenantKey{}).(string)
+		if !ok {
+			return nil
+		}
+		return map[string]string{"tenant": tenant}
+	}))
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want map[string]string
+	}{
+		{name: "labeled", ctx: context.WithValue(ctx, tenantKey{}, "acme"), want: map[string]string{"tenant": "acme"}},
+		{name: "unlabeled", ctx: ctx, want: nil},
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Test/Get"}
+	handler := func(context.Context, any) (any, error) { return &scopev1.WatchResponse{}, nil }
+	for _, tt := range tests {
+		if _, err := scope.UnaryInterceptor()(tt.ctx, &scopev1.WatchRequest{}, info, handler); err != nil {
+			t.Fatal(err)
+		}
+
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.GetEvent().GetLabels(); !maps.Equal(got, tt.want) {
+			t.Errorf("%s: got labels %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestInterceptor_RecoverPanics(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		recover bool
+		stream  bool
+	}{
+		{name: "unary", recover: true},
+		{name: "stream", recover: true, stream: true},
+		{name: "unary without the option"},
+		{name: "stream without the option", stream: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			events := make(chan domain.CallEvent, 1)
+			opts := []ginterceptor.Option{
+				ginterceptor.WithPort(0),
+				ginterceptor.WithProcessor(func(ev *domain.CallEvent) { events <- *ev }),
+			}
+			if tt.recover {
+				opts = append(opts, ginterceptor.WithRecoverPanics())
+			}
+			scope, err := ginterceptor.New(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(scope.Close)
+
+			call := func() {
+				if tt.stream {
+					info := &grpc.StreamServerInfo{FullMethod: "/test.v1.Test/Watch", IsServerStream: true}
+					handler := func(any, grpc.ServerStream) error { panic("boom") }
+					_ = scope.StreamInterceptor()(nil, &contextStream{ctx: t.Context()}, info, handler)
+					return
+				}
+				info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Test/Get"}
+				handler := func(context.Context, any) (any, error) { panic("boom") }
+				_, _ = scope.UnaryInterceptor()(t.Context(), &scopev1.WatchRequest{}, info, handler)
+			}
+			func() {
+				defer func() {
+					// The panic reaches the caller either way.
+					if v := recover(); v != "boom" {
+						t.Errorf("got panic %v, want boom", v)
+					}
+				}()
+				call()
+			}()
+
+			if !tt.recover {
+				select {
+				case ev := <-events:
+					t.Errorf("expected no event without WithRecoverPanics, got %s", ev.Method)
+				default:
+				}
+				return
+			}
+			select {
+			case ev := <-events:
+				if want := domain.StatusCode(codes.Internal + 1); ev.StatusCode != want {
+					t.Errorf("got status %s, want %s", ev.StatusCode, want)
+				}
+				if want := "panic: boom"; ev.StatusMessage != want {
+					t.Errorf("got status message %q, want %q", ev.StatusMessage, want)
+				}
+			default:
+				t.Fatal("expected the call to be recorded")
+			}
+		})
+	}
+}
+
+func TestUnaryInterceptor_CapturesRawRequestBytes(t *testing.T) {
+	t.Parallel()
+
+	// Field 1 is batch_size; field 99 is unknown to this schema, so it is lost
+	// in the JSON payload.
+	wire := protowire.AppendTag(nil, 1, protowire.VarintType)
+	wire = protowire.AppendVarint(wire, 10)
+	wire = protowire.AppendTag(wire, 99, protowire.VarintType)
+	wire = protowire.AppendVarint(wire, 7)
+
+	tests := []struct {
+		name string
+		opts []ginterceptor.Option
+		want []byte
+	}{
+		{name: "enabled", opts: []ginterceptor.Option{ginterceptor.WithRawRequestBytes()}, want: wire},
+		{name: "disabled"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			_, scopeClient, scope := setupTest(t, tt.opts...)
+			stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			waitForSubscriber(t, scope, 1)
+
+			req := &scopev1.WatchRequest{}
+			if err := proto.Unmarshal(wire, req); err != nil {
+				t.Fatal(err)
+			}
+			info := &grpc.UnaryServerInfo{FullMethod: "/test.v1.Test/Get"}
+			handler := func(context.Context, any) (any, error) { return &scopev1.WatchResponse{}, nil }
+			if _, err := scope.UnaryInterceptor()(ctx, req, info, handler); err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			ev := resp.GetEvent()
+			if got := ev.GetRequestBytesRaw(); !bytes.Equal(got, tt.want) {
+				t.Errorf("got raw request %x, want %x", got, tt.want)
+			}
+			if got := ev.GetRequestPayload(); got != `{"batchSize":10}` {
+				t.Errorf("got request payload %s, want the JSON without the unknown field", got)
+			}
+		})
+	}
+}
+
+func TestUnaryInterceptor_ReportsAppTarget(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	appClient, scopeClient, _ := setupTest(t)
+
+	info, err := scopeClient.ServerInfo(ctx, &scopev1.ServerInfoRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.GetAppTarget(); got != "" {
+		t.Errorf("got app target %q before any call, want none", got)
+	}
+
+	// The test service does not implement ServerInfo, but the call still
+	// reaches the app server through the interceptor.
+	if _, err := appClient.ServerInfo(ctx, &scopev1.ServerInfoRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("got error %v from the app server, want Unimplemented", err)
+	}
+
+	info, err = scopeClient.ServerInfo(ctx, &scopev1.ServerInfoRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	target := info.GetAppTarget()
+	if target == "" {
+		t.Fatal("expected the app target to be reported after an inbound call")
+	}
+
+	// The reported target reaches the app server, as replay would.
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	if _, err := scopev1.NewScopeServiceClient(conn).ServerInfo(ctx, &scopev1.ServerInfoRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Errorf("got error %v calling the reported target %s, want Unimplemented from the app server", err, target)
+	}
+}
+
+func TestUnaryInterceptor_WithAppTarget(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	appClient, scopeClient, _ := setupTest(t, ginterceptor.WithAppTarget("app.internal:50051"))
+
+	if _, err := appClient.ServerInfo(ctx, &scopev1.ServerInfoRequest{}); status.Code(err) != codes.Unimplemented {
+		t.Fatalf("got error %v from the app server, want Unimplemented", err)
+	}
+	info, err := scopeClient.ServerInfo(ctx, &scopev1.ServerInfoRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.GetAppTarget(); got != "app.internal:50051" {
+		t.Errorf("got app target %q, want the configured app.internal:50051", got)
+	}
+}
+
+// hangingService sends one Watch response, then waits for the client to go
+// away and returns the context error, as handlers commonly do. With a
+// deadline, it also stops waiting once the deadline passes, as the server's
+// copy of a client deadline would.
+type hangingService struct {
+	scopev1.UnimplementedScopeServiceServer
+	deadline time.Duration
+}
+
+func (s hangingService) Watch(_ *scopev1.WatchRequest, stream grpc.ServerStreamingServer[scopev1.WatchResponse]) error {
+	if err := stream.Send(&scopev1.WatchResponse{}); err != nil {
+		return err
+	}
+	ctx := stream.Context()
+	if s.deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.deadline)
+		defer cancel()
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func TestStreamInterceptor_CapturesClientGone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		deadline   time.Duration
+		wantStatus domain.StatusCode
+	}{
+		{
+			name:       "client cancels",
+			wantStatus: domain.StatusCancelled,
+		},
+		{
+			// The client never cancels, so only the server's deadline ends
+			// the call.
+			name:       "deadline expires",
+			deadline:   200 * time.Millisecond,
+			wantStatus: domain.StatusDeadlineExceeded,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			appClient, scopeClient, scope := setupTestWithService(t, hangingService{deadline: tt.deadline})
+
+			stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			waitForSubscriber(t, scope, 1)
+
+			callCtx, cancel := context.WithCancel(ctx)
+			defer cancel()
+			watchStream, err := appClient.Watch(callCtx, &scopev1.WatchRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			// The first response shows the handler is running before the
+			// client goes away.
+			if _, err := watchStream.Recv(); err != nil {
+				t.Fatal(err)
+			}
+			if tt.deadline == 0 {
+				cancel()
+			}
+			if _, err := watchStream.Recv(); err == nil {
+				t.Fatal("expected the call to end with an error")
+			}
+
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := domain.StatusCode(resp.GetEvent().GetStatusCode()); got != tt.wantStatus {
+				t.Errorf("got status %s, want %s", got, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestStreamInterceptor_CapturesRetryAttempt(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	appClient, scopeClient, scope := setupTest(t)
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	watchStream, err := appClient.Watch(
+		metadata.AppendToOutgoingContext(ctx, "grpc-previous-rpc-attempts", "2"),
+		&scopev1.WatchRequest{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watchStream.Recv(); err == nil {
+		t.Fatal("expected error from test service")
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := resp.GetEvent().GetAttempt(); got != 2 {
+		t.Errorf("got attempt %d, want %d", got, 2)
+	}
+}
+
+func TestStreamInterceptor_CapturesGatewayPath(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	appClient, scopeClient, scope := setupTest(t)
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	// Metadata as grpc-gateway forwards it, with the original path added by a
+	// runtime.WithMetadata annotator.
+	gatewayCtx := metadata.AppendToOutgoingContext(ctx,
+		"x-forwarded-path", "/v1/events:watch",
+		"x-forwarded-for", "203.0.113.7",
+		"grpcgateway-user-agent", "curl/8.5.0",
+	)
+	watchStream, err := appClient.Watch(gatewayCtx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watchStream.Recv(); err == nil {
+		t.Fatal("expected error from test service")
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := resp.GetEvent()
+	if got := ev.GetHttpPath(); got != "/v1/events:watch" {
+		t.Errorf("got HTTP path %q, want %q", got, "/v1/events:watch")
+	}
+	if got := ev.GetRequestMetadata()["x-forwarded-for"].GetValues(); len(got) != 1 || got[0] != "203.0.113.7" {
+		t.Errorf("got x-forwarded-for %v, want [203.0.113.7]", got)
+	}
+}
+
+func TestStreamInterceptor_Processors(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	appClient, scopeClient, scope := setupTest(t,
+		ginterceptor.WithProcessor(func(ev *domain.CallEvent) {
+			ev.StatusMessage = "first"
+		}),
+		ginterceptor.WithProcessor(func(ev *domain.CallEvent) {
+			ev.StatusMessage += ",second"
+			delete(ev.RequestMetadata, "authorization")
+		}),
+	)
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	watchStream, err := appClient.Watch(
+		metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret"),
+		&scopev1.WatchRequest{},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := watchStream.Recv(); err == nil {
+		t.Fatal("expected error from test service")
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ev := resp.GetEvent()
+	if got := ev.GetStatusMessage(); got != "first,second" {
+		t.Errorf("got status message %q, want processors applied in order", got)
+	}
+	if _, ok := ev.GetRequestMetadata()["authorization"]; ok {
+		t.Error("expected processor to remove authorization metadata")
+	}
+}
+
+func TestStreamInterceptor_CaptureFilter(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	appClient, scopeClient, scope := setupTest(t, ginterceptor.WithCaptureFilter(
+		func(_ string, md metadata.MD) bool {
+			for _, v := range md.Get("x-tenant") {
+				if v == "blocked" {
+					return false
+				}
+			}
+			return true
+		},
+	))
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	for _, tenant := range []string{"blocked", "allowed"} {
+		watchStream, err := appClient.Watch(
+			metadata.AppendToOutgoingContext(ctx, "x-tenant", tenant),
+			&scopev1.WatchRequest{},
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// The handler still runs for filtered calls and returns Unimplemented.
+		if _, err := watchStream.Recv(); status.Code(err) != codes.Unimplemented {
+			t.Fatalf("tenant %s: got %v, want Unimplemented", tenant, err)
+		}
+	}
+
+	// Calls are made in order, so the first event must be the allowed tenant's.
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := resp.GetEvent().GetRequestMetadata()["x-tenant"].GetValues()
+	if len(got) != 1 || got[0] != "allowed" {
+		t.Errorf("got x-tenant %v, want [allowed]", got)
+	}
+}
+
+func TestStreamInterceptor_CapturesDeadline(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	appClient, scopeClient, scope := setupTest(t)
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	call := func(ctx context.Context) *scopev1.CallEvent {
+		t.Helper()
+
+		watchStream, err := appClient.Watch(ctx, &scopev1.WatchRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := watchStream.Recv(); err == nil {
+			t.Fatal("expected error from test service")
+		}
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return resp.GetEvent()
+	}
+
+	const timeout = 5 * time.Second
+	deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	ev := call(deadlineCtx)
+	if ev.GetDeadline() == nil {
+		t.Fatal("expected deadline to be captured")
+	}
+	if got := ev.GetDeadline().AsTime().Sub(ev.GetStartTime().AsTime()); got <= 0 || got > timeout {
+		t.Errorf("got deadline %s after start, want within (0, %s]", got, timeout)
+	}
+
+	if ev := call(ctx); ev.GetDeadline() != nil {
+		t.Errorf("got deadline %v for a call without one, want none", ev.GetDeadline().AsTime())
+	}
+}
+
+type deadlineSourceKey struct{}
+
+// contextStream overrides the context of a grpc.ServerStream.
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context { return s.ctx }
+
+func TestStreamInterceptor_CapturesDeadlineSource(t *testing.T) {
+	t.Parallel()
+
+	events := make(chan domain.CallEvent, 2)
+	scope, err := ginterceptor.New(
+		ginterceptor.WithPort(0),
+		ginterceptor.WithIgnoreMethods(),
+		ginterceptor.WithDeadlineSourceKey(deadlineSourceKey{}),
+		ginterceptor.WithProcessor(func(ev *domain.CallEvent) { events <- *ev }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(scope.Close)
+
+	// Injects a default deadline, marked as such, when the client sent none.
+	inject := func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if _, ok := ss.Context().Deadline(); ok {
+			return handler(srv, ss)
+		}
+		ctx, cancel := context.WithTimeout(ss.Context(), time.Minute)
+		defer cancel()
+		ctx = context.WithValue(ctx, deadlineSourceKey{}, "server-default")
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+	srv := grpc.NewServer(grpc.ChainStreamInterceptor(inject, scope.StreamInterceptor()))
+	scopev1.RegisterScopeServiceServer(srv, &testService{})
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			// server stopped
+		}
+	}()
+	t.Cleanup(srv.GracefulStop)
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := scopev1.NewScopeServiceClient(conn)
+
+	deadlineCtx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want string
+	}{
+		{name: "client deadline", ctx: deadlineCtx, want: domain.DeadlineSourceClient},
+		{name: "injected deadline", ctx: t.Context(), want: "server-default"},
+	}
+	for _, tt := range tests {
+		stream, err := client.Watch(tt.ctx, &scopev1.WatchRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := stream.Recv(); err == nil {
+			t.Fatal("expected error from test service")
+		}
+		if ev := <-events; ev.DeadlineSource != tt.want {
+			t.Errorf("%s: got deadline source %q, want %q", tt.name, ev.DeadlineSource, tt.want)
+		}
+	}
+}
+
+func TestStreamInterceptor_CapturesServerStreamResponses(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	svc := &testService{responses: []*scopev1.WatchResponse{
+		{Event: &scopev1.CallEvent{Id: "first"}},
+		{Event: &scopev1.CallEvent{Id: "second"}},
+		{Event: &scopev1.CallEvent{Id: "third"}},
+	}}
+	appClient, scopeClient, scope := setupTestWithService(t, svc)
+
+	stream, err := scopeClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	waitForSubscriber(t, scope, 1)
+
+	watchStream, err := appClient.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range svc.responses {
+		if _, err := watchStream.Recv(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []struct {
+		Event struct {
+			ID string `json:"id"`
+		} `json:"event"`
+	}
+	payload := resp.GetEvent().GetResponsePayload()
+	if err := json.Unmarshal([]byte(payload), &got); err != nil {
+		t.Fatalf("response payload %q is not a JSON array: %v", payload, err)
+	}
+	want := []string{"first", "second", "third"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d messages, want %d: %s", len(got), len(want), payload)
+	}
+	if ev := resp.GetEvent(); !ev.GetStreaming() || ev.GetSentCount() != 3 || ev.GetRecvCount() != 1 {
+		t.Errorf("got streaming %v, %d sent, %d received; want 3 sent, 1 received",
+			ev.GetStreaming(), ev.GetSentCount(), ev.GetRecvCount())
+	}
+	for i, w := range want {
+		if got[i].Event.ID != w {
+			t.Errorf("message %d: got id %q, want %q", i, got[i].Event.ID, w)
+		}
+	}
+}
+
+// issueCert signs a certificate for template with parent's key, or
+// self-signs it when parent is nil.
+func issueCert(t *testing.T, template *x509.Certificate, parent *tls.Certificate) tls.Certificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template.NotBefore = time.Now().Add(-time.Hour)
+	template.NotAfter = time.Now().Add(time.Hour)
+	issuer, signer := template, any(key)
+	if parent != nil {
+		issuer, signer = parent.Leaf, parent.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}
+}
+
+func TestStreamInterceptor_CapturesPeerIdentity(t *testing.T) {
+	t.Parallel()
+
+	ca := issueCert(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}, nil)
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Leaf)
+	serverCert := issueCert(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}, &ca)
+	spiffeID, err := url.Parse("spiffe://example.org/ns/default/sa/billing")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan domain.CallEvent, 1)
+	scope, err := ginterceptor.New(
+		ginterceptor.WithPort(0),
+		ginterceptor.WithIgnoreMethods(),
+		ginterceptor.WithProcessor(func(ev *domain.CallEvent) { events <- *ev }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(scope.Close)
+
+	srv := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{serverCert},
+			ClientCAs:    pool,
+			ClientAuth:   tls.RequireAndVerifyClientCert,
+			MinVersion:   tls.VersionTLS13,
+		})),
+		grpc.StreamInterceptor(scope.StreamInterceptor()),
+	)
+	scopev1.RegisterScopeServiceServer(srv, &testService{})
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			// server stopped
+		}
+	}()
+	t.Cleanup(srv.GracefulStop)
+
+	tests := []struct {
+		name   string
+		client *x509.Certificate
+		want   string
+	}{
+		{
+			name:   "SPIFFE ID",
+			client: &x509.Certificate{Subject: pkix.Name{CommonName: "billing"}, URIs: []*url.URL{spiffeID}},
+			want:   "spiffe://example.org/ns/default/sa/billing",
+		},
+		{
+			name:   "subject",
+			client: &x509.Certificate{Subject: pkix.Name{CommonName: "billing", Organization: []string{"Example"}}},
+			want:   "CN=billing,O=Example",
+		},
+	}
+	for i, tt := range tests {
+		tt.client.SerialNumber = big.NewInt(int64(10 + i))
+		tt.client.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+		clientCert := issueCert(t, tt.client, &ca)
+
+		conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			Certificates: []tls.Certificate{clientCert},
+			RootCAs:      pool,
+			ServerName:   "localhost",
+			MinVersion:   tls.VersionTLS13,
+		})))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stream, err := scopev1.NewScopeServiceClient(conn).Watch(t.Context(), &scopev1.WatchRequest{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := stream.Recv(); status.Code(err) != codes.Unimplemented {
+			t.Fatalf("%s: got error %v, want Unimplemented", tt.name, err)
+		}
+		_ = conn.Close()
+
+		if ev := <-events; ev.PeerIdentity != tt.want {
+			t.Errorf("%s: got peer identity %q, want %q", tt.name, ev.PeerIdentity, tt.want)
+		}
+	}
+}
@@ -1,11 +1,30 @@
 package main
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"net"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mickamy/grpc-scope/replay"
+	"github.com/mickamy/grpc-scope/scope/domain"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"github.com/mickamy/grpc-scope/session"
 	"github.com/mickamy/grpc-scope/tui"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 var version = "dev"
@@ -19,6 +38,10 @@ func main() {
 	switch os.Args[1] {
 	case "monitor":
 		runMonitor()
+	case "serve":
+		runServe()
+	case "tail":
+		runTail()
 	case "version":
 		fmt.Printf("grpc-scope %s\n", version)
 	case "help":
@@ -31,19 +54,114 @@ func main() {
 }
 
 func runMonitor() {
-	if len(os.Args) < 3 {
-		fmt.Fprintln(os.Stderr, "usage: grpc-scope monitor <scope-addr> [app-addr]")
+	fs := flag.NewFlagSet("monitor", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: grpc-scope monitor [flags] <scope-addr> [app-addr]")
+		fs.PrintDefaults()
+	}
+	descriptorSet := fs.String("descriptor-set", "", "compiled FileDescriptorSet used for replay when the app server has no reflection")
+	keepDeadline := fs.Bool("keep-deadline", false, "replay calls with the timeout the original client set")
+	rotate := fs.String("rotate-metadata", "", "comma-separated metadata keys sent with a fresh UUID on every replay, e.g. idempotency-key")
+	replayDeny := fs.String("replay-deny", "", "comma-separated method patterns (path.Match syntax) that may not be replayed, e.g. /*/Delete*")
+	replayAllow := fs.String("replay-allow", "", "comma-separated method patterns replay is limited to")
+	protoNames := fs.Bool("proto-names", false, "copy grpcurl payloads with proto field names (e.g. batch_size) instead of JSON names")
+	token := fs.String("token", "", "shared token required by the scope server (default $GRPC_SCOPE_TOKEN)")
+	batchSize := fs.Int("batch-size", 0, "ask the scope server to send up to this many events per message (0 disables batching)")
+	batchInterval := fs.Duration("batch-interval", 0, "flush a partial batch after this long (0 uses the server default of 100ms)")
+	timeFormat := fs.String("time-format", "", "Go time layout of call start times in the list, e.g. 2006-01-02T15:04:05.000 (default 15:04:05)")
+	utc := fs.Bool("utc", false, "show call start times in UTC instead of local time")
+	latencyWarn := fs.Duration("latency-warn", tui.DefaultLatencyWarn, "color list latencies from this long yellow")
+	latencyCritical := fs.Duration("latency-critical", tui.DefaultLatencyCritical, "color list latencies from this long red")
+	maxStatsMethods := fs.Int("max-stats-methods", tui.DefaultMaxStatsMethods, "methods listed in the stats panel before the least recently called are folded into (other); 0 lists all")
+	configPath := fs.String("config", "", "config file with key bindings and display settings (default ~/.config/grpc-scope/config.toml)")
+
+	args := parseArgs(fs, os.Args[2:])
+	if len(args) < 1 {
+		fs.Usage()
 		os.Exit(1)
 	}
 
-	target := os.Args[2]
+	target := args[0]
 	var appTarget string
-	if len(os.Args) >= 4 {
-		appTarget = os.Args[3]
+	if len(args) >= 2 {
+		appTarget = args[1]
 	}
 
-	m := tui.NewModel(target, appTarget)
-	p := tea.NewProgram(m, tea.WithAltScreen())
+	var opts []tui.Option
+	if *descriptorSet != "" {
+		opts = append(opts, tui.WithReplayOptions(replay.WithDescriptorSet(*descriptorSet)))
+	}
+	if *keepDeadline {
+		opts = append(opts, tui.WithOriginalDeadline())
+	}
+	if *rotate != "" {
+		opts = append(opts, tui.WithRotatedMetadata(splitList(*rotate)...))
+	}
+	if *replayDeny != "" {
+		patterns := splitList(*replayDeny)
+		if err := tui.ValidateMethodPatterns(patterns); err != nil {
+			fmt.Fprintf(os.Stderr, "error: --replay-deny: %v\n", err)
+			os.Exit(1)
+		}
+		opts = append(opts, tui.WithReplayDenyList(patterns))
+	}
+	if *replayAllow != "" {
+		patterns := splitList(*replayAllow)
+		if err := tui.ValidateMethodPatterns(patterns); err != nil {
+			fmt.Fprintf(os.Stderr, "error: --replay-allow: %v\n", err)
+			os.Exit(1)
+		}
+		opts = append(opts, tui.WithReplayAllowList(patterns))
+	}
+	if *protoNames {
+		opts = append(opts, tui.WithProtoNames())
+	}
+	if *token == "" {
+		// Read after parsing so usage output never prints the secret.
+		*token = os.Getenv("GRPC_SCOPE_TOKEN")
+	}
+	if *token != "" {
+		opts = append(opts, tui.WithAuthToken(*token))
+	}
+	if *batchSize > 1 {
+		opts = append(opts, tui.WithWatchBatch(*batchSize, *batchInterval))
+	}
+	opts = append(opts, tui.WithMaxStatsMethods(*maxStatsMethods))
+	if err := checkLatencyThresholds(*latencyWarn, *latencyCritical); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		fs.Usage()
+		os.Exit(1)
+	}
+	opts = append(opts, tui.WithLatencyThresholds(*latencyWarn, *latencyCritical))
+
+	path, cfg, err := readConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v; using default settings\n", err)
+	}
+	km, err := tui.ParseKeyMap(bytes.NewReader(cfg))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s: %v; using default key bindings\n", path, err)
+	} else if len(km) > 0 {
+		opts = append(opts, tui.WithKeyMap(km))
+	}
+	display, err := tui.ParseDisplayConfig(bytes.NewReader(cfg))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %s: %v; using default display settings\n", path, err)
+	}
+	if *timeFormat == "" {
+		*timeFormat = display.TimeFormat
+	}
+	if *timeFormat != "" && !tui.ValidTimeLayout(*timeFormat) {
+		fmt.Fprintf(os.Stderr, "warning: invalid time format %q; using %s\n", *timeFormat, tui.DefaultTimeLayout)
+		*timeFormat = ""
+	}
+	if !isSet(fs, "utc") {
+		*utc = display.UTC
+	}
+	opts = append(opts, tui.WithTimeFormat(*timeFormat, *utc))
+
+	m := tui.NewModel(target, appTarget, opts...)
+	p := tea.NewProgram(m, tea.WithAltScreen(), tea.WithMouseCellMotion())
 
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -51,12 +169,288 @@ func runMonitor() {
 	}
 }
 
+func runServe() {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: grpc-scope serve [flags] <session-file>")
+		fs.PrintDefaults()
+	}
+	port := fs.Int("port", 9090, "port to serve the session on")
+	bind := fs.String("bind", "127.0.0.1", "host to serve the session on; use 0.0.0.0 to let monitors on other hosts connect")
+
+	args := parseArgs(fs, os.Args[2:])
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	events, err := session.Read(f)
+	_ = f.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	lis, err := net.Listen("tcp", net.JoinHostPort(*bind, strconv.Itoa(*port)))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+	srv := grpc.NewServer()
+	scopev1.RegisterScopeServiceServer(srv, session.NewService(events))
+
+	fmt.Fprintf(os.Stderr, "serving %d events from %s on %s\n", len(events), args[0], lis.Addr())
+	if err := srv.Serve(lis); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func runTail() {
+	fs := flag.NewFlagSet("tail", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: grpc-scope tail [flags] <scope-addr>")
+		fs.PrintDefaults()
+	}
+	count := fs.Int("count", 10, "exit after printing this many events (0 prints until the timeout or an interrupt)")
+	asJSON := fs.Bool("json", false, "print each event as a line of JSON, in the session file format")
+	filter := fs.String("filter", "", "print only events whose method contains this text, or with key=value, whose key label contains value")
+	timeout := fs.Duration("timeout", 0, "give up after this long, failing unless --count is 0 (0 waits forever)")
+	since := fs.Duration("since", 0, "also print events the scope server kept that ended this long before tail started, by its clock")
+	token := fs.String("token", "", "shared token required by the scope server (default $GRPC_SCOPE_TOKEN)")
+
+	args := parseArgs(fs, os.Args[2:])
+	if len(args) != 1 || *count < 0 || *since < 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *token == "" {
+		*token = os.Getenv("GRPC_SCOPE_TOKEN")
+	}
+
+	write := printEventLine
+	if *asJSON {
+		write = func(w io.Writer, ev *scopev1.CallEvent) error {
+			return session.Write(w, []*scopev1.CallEvent{ev})
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
+	}
+
+	n, err := tail(ctx, args[0], *token, *filter, *since, *count, func(ev *scopev1.CallEvent) error {
+		return write(os.Stdout, ev)
+	})
+	if msg := tailFailure(ctx.Err(), n, *count, err); msg != "" {
+		fmt.Fprintln(os.Stderr, msg)
+		os.Exit(1)
+	}
+}
+
+// tailFailure returns the message tail exits with after printing n of count
+// events and stopping with err, or "" if it succeeded. ctxErr is the error of
+// the context bounding it by --timeout and interrupts.
+func tailFailure(ctxErr error, n, count int, err error) string {
+	switch {
+	case err == nil:
+		return ""
+	case ctxErr != nil && count == 0:
+		// Printing until the timeout or an interrupt is what was asked.
+		return ""
+	case errors.Is(ctxErr, context.DeadlineExceeded):
+		return fmt.Sprintf("error: timed out after %d of %d events", n, count)
+	case ctxErr != nil:
+		return fmt.Sprintf("interrupted after %d of %d events", n, count)
+	default:
+		return fmt.Sprintf("error: %v", err)
+	}
+}
+
+// tail watches the scope server at target and calls emit for each event
+// matching filter, until count have been printed or ctx is done. A count of
+// 0 prints until ctx is done. It returns the number of events printed. Of
+// the history a scope server resends to new streams, such as calls restored
+// by WithPersistPath, only events that ended within since before tail
+// started are printed; the server measures that by its own clock, so a skew
+// between the machines does not matter. Live calls are always printed.
+func tail(ctx context.Context, target, token, filter string, since time.Duration, count int, emit func(*scopev1.CallEvent) error) (int, error) {
+	conn, err := grpc.NewClient(target, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect: %w", err)
+	}
+	defer conn.Close()
+
+	if token != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, domain.HeaderScopeToken, token)
+	}
+	stream, err := scopev1.NewScopeServiceClient(conn).Watch(ctx, &scopev1.WatchRequest{History: durationpb.New(since)})
+	if err != nil {
+		return 0, fmt.Errorf("failed to start watch: %w", err)
+	}
+
+	printed := 0
+	var dropped uint64
+	for count == 0 || printed < count {
+		resp, err := stream.Recv()
+		if err != nil {
+			return printed, fmt.Errorf("watch stream error: %w", err)
+		}
+		if d := resp.GetDropped(); d > dropped {
+			fmt.Fprintf(os.Stderr, "warning: %d events dropped by the scope server\n", d-dropped)
+			dropped = d
+		}
+		ev := resp.GetEvent()
+		if ev == nil || !tui.MatchesFilter(filter, ev) {
+			continue // a heartbeat or a filtered-out event
+		}
+		if err := emit(ev); err != nil {
+			return printed, err
+		}
+		printed++
+	}
+	return printed, nil
+}
+
+// printEventLine writes ev as one line: its start time, status, latency, and
+// method.
+func printEventLine(w io.Writer, ev *scopev1.CallEvent) error {
+	_, err := fmt.Fprintf(w, "%s  %-19s %12s  %s\n",
+		ev.GetStartTime().AsTime().Local().Format("15:04:05.000"),
+		domain.StatusCode(ev.GetStatusCode()),
+		ev.GetDuration().AsDuration(),
+		ev.GetMethod())
+	return err
+}
+
+// readConfig reads the config file at path, or the default config file when
+// path is empty, and returns the path it read. A missing default config
+// file yields no content.
+func readConfig(path string) (string, []byte, error) {
+	explicit := path != ""
+	if !explicit {
+		dir := os.Getenv("XDG_CONFIG_HOME")
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", nil, nil
+			}
+			dir = filepath.Join(home, ".config")
+		}
+		path = filepath.Join(dir, "grpc-scope", "config.toml")
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return path, nil, nil
+	}
+	if err != nil {
+		return path, nil, err
+	}
+	return path, b, nil
+}
+
+// parseArgs parses flags that may appear before, between, or after
+// positional arguments, and returns the positional arguments.
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		_ = fs.Parse(args) // ExitOnError: exits on invalid flags
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// checkLatencyThresholds reports an error unless warn and critical are
+// non-negative and warn is below critical. A zero threshold stands for its
+// default, as in tui.WithLatencyThresholds.
+func checkLatencyThresholds(warn, critical time.Duration) error {
+	if warn < 0 || critical < 0 {
+		return errors.New("--latency-warn and --latency-critical must not be negative")
+	}
+	if warn == 0 {
+		warn = tui.DefaultLatencyWarn
+	}
+	if critical == 0 {
+		critical = tui.DefaultLatencyCritical
+	}
+	if warn >= critical {
+		return fmt.Errorf("--latency-warn (%s) must be below --latency-critical (%s)", warn, critical)
+	}
+	return nil
+}
+
+// splitList splits a comma-separated flag value, trimming spaces around
+// each element and dropping empty ones.
+func splitList(s string) []string {
+	var list []string
+	for _, e := range strings.Split(s, ",") {
+		if e = strings.TrimSpace(e); e != "" {
+			list = append(list, e)
+		}
+	}
+	return list
+}
+
+// isSet reports whether the flag name was given on the command line, so
+// that an explicit default value still overrides the config file.
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
 func printUsage() {
 	fmt.Fprintln(os.Stderr, "grpc-scope - gRPC/ConnectRPC development TUI tool")
 	fmt.Fprintln(os.Stderr)
 	fmt.Fprintf(os.Stderr, "Usage: grpc-scope <command> [args]\n\n")
 	fmt.Fprintln(os.Stderr, "Commands:")
 	fmt.Fprintln(os.Stderr, "  monitor <scope-addr> [app-addr]   Watch gRPC traffic in real-time")
-	fmt.Fprintln(os.Stderr, "                                    app-addr enables replay (r/e keys)")
+	fmt.Fprintln(os.Stderr, "                                    scope-addr may be unix:///path/to.sock")
+	fmt.Fprintln(os.Stderr, "                                    app-addr enables replay (r/e keys); without it, the")
+	fmt.Fprintln(os.Stderr, "                                    address the scope server reports is used")
+	fmt.Fprintln(os.Stderr, "    --descriptor-set <file>         Resolve replay types from a FileDescriptorSet")
+	fmt.Fprintln(os.Stderr, "    --keep-deadline                 Replay with the original call's timeout")
+	fmt.Fprintln(os.Stderr, "    --rotate-metadata <keys>        Send these metadata keys with a fresh UUID on every replay")
+	fmt.Fprintln(os.Stderr, "    --replay-deny <patterns>        Never replay methods matching these patterns, e.g. /*/Delete*")
+	fmt.Fprintln(os.Stderr, "    --replay-allow <patterns>       Only replay methods matching these patterns")
+	fmt.Fprintln(os.Stderr, "    --proto-names                   Copy grpcurl payloads with proto field names")
+	fmt.Fprintln(os.Stderr, "    --token <token>                 Token for a scope server using WithAuthToken")
+	fmt.Fprintln(os.Stderr, "    --batch-size <n>                Receive up to n events per message on busy servers")
+	fmt.Fprintln(os.Stderr, "    --batch-interval <duration>     Flush partial batches after this long (default 100ms)")
+	fmt.Fprintln(os.Stderr, "    --time-format <layout>          Go time layout of start times in the list (default 15:04:05)")
+	fmt.Fprintln(os.Stderr, "    --utc                           Show start times in UTC")
+	fmt.Fprintln(os.Stderr, "    --latency-warn <duration>       Color list latencies from this long yellow (default 50ms)")
+	fmt.Fprintln(os.Stderr, "    --latency-critical <duration>   Color list latencies from this long red (default 500ms)")
+	fmt.Fprintln(os.Stderr, "    --max-stats-methods <n>         Fold methods beyond the n most recent into (other) in stats")
+	fmt.Fprintln(os.Stderr, "    --config <file>                 Key bindings and display settings")
+	fmt.Fprintln(os.Stderr, "                                    (default ~/.config/grpc-scope/config.toml)")
+	fmt.Fprintln(os.Stderr, "  serve <session-file>              Serve an exported session for monitors to watch")
+	fmt.Fprintln(os.Stderr, "    --port <port>                   Port to serve on (default 9090)")
+	fmt.Fprintln(os.Stderr, "    --bind <host>                   Host to serve on (default 127.0.0.1; 0.0.0.0 for other hosts)")
+	fmt.Fprintln(os.Stderr, "  tail <scope-addr>                 Print the next events without the TUI, e.g. in scripts")
+	fmt.Fprintln(os.Stderr, "    --count <n>                     Exit after n events (default 10; 0 prints until stopped)")
+	fmt.Fprintln(os.Stderr, "    --json                          Print events as JSON lines, in the session file format")
+	fmt.Fprintln(os.Stderr, "    --filter <text>                 Print only methods containing text, or key=value labels")
+	fmt.Fprintln(os.Stderr, "    --timeout <duration>            Fail if the events have not arrived after this long")
+	fmt.Fprintln(os.Stderr, "    --since <duration>              Also print kept events that ended this long before tail")
+	fmt.Fprintln(os.Stderr, "    --token <token>                 Token for a scope server using WithAuthToken")
 	fmt.Fprintln(os.Stderr, "  version                           Print version")
 }
@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/mickamy/grpc-scope/scope"
+	"github.com/mickamy/grpc-scope/scope/domain"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+)
+
+// startScope starts a scope server on a free port, keeping its events in a
+// file under dir, and returns it with its address.
+func startScope(t *testing.T, dir string) (*scope.Scope, string) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := lis.Addr().(*net.TCPAddr).Port
+	_ = lis.Close()
+
+	s, err := scope.New(scope.WithPort(port), scope.WithPersistPath(filepath.Join(dir, "events.jsonl")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s, fmt.Sprintf("localhost:%d", port)
+}
+
+func TestTail(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+
+	tests := []struct {
+		name   string
+		since  time.Duration
+		filter string
+		count  int
+		want   []string
+	}{
+		{
+			name:  "restored history is skipped",
+			count: 2,
+			want:  []string{"/test.v1.Test/Get", "/test.v1.Test/List"},
+		},
+		{
+			name:  "since includes restored history",
+			since: 2 * time.Hour,
+			count: 2,
+			want:  []string{"/test.v1.Test/Old", "/test.v1.Test/Get"},
+		},
+		{
+			name:   "filter",
+			filter: "List",
+			count:  1,
+			want:   []string{"/test.v1.Test/List"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// A call captured before the application restarted.
+			dir := t.TempDir()
+			before, _ := startScope(t, dir)
+			before.Publish(domain.CallEvent{
+				Method:     "/test.v1.Test/Old",
+				StatusCode: domain.StatusOK,
+				StartTime:  start.Add(-time.Hour),
+				Duration:   time.Millisecond,
+			})
+			before.Close()
+
+			s, target := startScope(t, dir)
+			t.Cleanup(s.Close)
+
+			type result struct {
+				n   int
+				err error
+			}
+			var got []string
+			done := make(chan result, 1)
+			go func() {
+				n, err := tail(t.Context(), target, "", tt.filter, tt.since, tt.count, func(ev *scopev1.CallEvent) error {
+					got = append(got, ev.GetMethod())
+					return nil
+				})
+				done <- result{n, err}
+			}()
+
+			for s.SubscriberCount() == 0 {
+				time.Sleep(5 * time.Millisecond)
+			}
+			for _, method := range []string{"/test.v1.Test/Get", "/test.v1.Test/List"} {
+				s.Publish(domain.CallEvent{Method: method, StatusCode: domain.StatusOK, StartTime: time.Now()})
+			}
+
+			select {
+			case r := <-done:
+				if r.err != nil {
+					t.Fatal(r.err)
+				}
+				if r.n != len(tt.want) {
+					t.Errorf("got %d events printed, want %d", r.n, len(tt.want))
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("tail did not return")
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got methods %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTail_StopsWithContext(t *testing.T) {
+	t.Parallel()
+
+	s, target := startScope(t, t.TempDir())
+	t.Cleanup(s.Close)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	n, err := tail(ctx, target, "", "", 0, 1, func(*scopev1.CallEvent) error { return nil })
+	if n != 0 || err == nil {
+		t.Fatalf("got %d events and error %v, want none and an error", n, err)
+	}
+	if msg := tailFailure(ctx.Err(), n, 1, err); msg != "error: timed out after 0 of 1 events" {
+		t.Errorf("got failure %q", msg)
+	}
+}
+
+func TestTailFailure(t *testing.T) {
+	t.Parallel()
+
+	streamErr := errors.New("watch stream error: canceled")
+	tests := []struct {
+		name   string
+		ctxErr error
+		n      int
+		count  int
+		err    error
+		want   string
+	}{
+		{name: "all events printed", n: 3, count: 3},
+		{name: "timeout with count 0", ctxErr: context.DeadlineExceeded, n: 5, err: streamErr},
+		{name: "interrupt with count 0", ctxErr: context.Canceled, n: 5, err: streamErr},
+		{
+			name:   "timeout",
+			ctxErr: context.DeadlineExceeded, n: 1, count: 3, err: streamErr,
+			want: "error: timed out after 1 of 3 events",
+		},
+		{
+			name:   "interrupt",
+			ctxErr: context.Canceled, n: 1, count: 3, err: streamErr,
+			want: "interrupted after 1 of 3 events",
+		},
+		{
+			name: "server error",
+			n:    1, count: 3, err: errors.New("watch stream error: unavailable"),
+			want: "error: watch stream error: unavailable",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tailFailure(tt.ctxErr, tt.n, tt.count, tt.err); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSet(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{name: "not given", args: []string{"localhost:9090"}},
+		{name: "given", args: []string{"--utc", "localhost:9090"}, want: true},
+		{name: "given false", args: []string{"localhost:9090", "--utc=false"}, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fs := flag.NewFlagSet("monitor", flag.ContinueOnError)
+			fs.Bool("utc", false, "")
+			parseArgs(fs, tt.args)
+			if got := isSet(fs, "utc"); got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{name: "plain", in: "a,b", want: []string{"a", "b"}},
+		{name: "spaces", in: "a, b ,c", want: []string{"a", "b", "c"}},
+		{name: "empty elements", in: "a,, ,b,", want: []string{"a", "b"}},
+		{name: "only separators", in: " , "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := splitList(tt.in); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckLatencyThresholds(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		warn     time.Duration
+		critical time.Duration
+		wantErr  bool
+	}{
+		{name: "defaults"},
+		{name: "both set", warn: 100 * time.Millisecond, critical: time.Second},
+		{name: "warn above default critical", warn: time.Second, wantErr: true},
+		{name: "equal", warn: time.Second, critical: time.Second, wantErr: true},
+		{name: "reversed", warn: time.Second, critical: 100 * time.Millisecond, wantErr: true},
+		{name: "negative warn", warn: -time.Millisecond, wantErr: true},
+		{name: "negative critical", critical: -time.Millisecond, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := checkLatencyThresholds(tt.warn, tt.critical)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
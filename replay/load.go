@@ -0,0 +1,122 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+)
+
+// sendNConcurrency bounds how many calls SendN keeps in flight, so repeated
+// replays don't overwhelm a development server.
+const sendNConcurrency = 4
+
+// Stats aggregates the results of repeatedly replaying a call.
+type Stats struct {
+	Count    int                // calls that completed
+	Success  int                // calls that returned OK
+	Min      time.Duration      // fastest call
+	Max      time.Duration      // slowest call
+	Avg      time.Duration      // mean duration
+	P99      time.Duration      // nearest-rank 99th percentile duration
+	ByStatus map[codes.Code]int // number of calls per status code, including OK
+}
+
+// SendN replays req n times and returns aggregate stats. The first call runs
+// alone so an invalid request or unresolvable method fails fast; the rest run
+// with bounded concurrency. If ctx is canceled, SendN returns the stats of the
+// calls completed so far together with the context's error.
+func (c *Client) SendN(ctx context.Context, req Request, n int) (*Stats, error) {
+	if n < 1 {
+		return nil, fmt.Errorf("replay: invalid count %d (must be at least 1)", n)
+	}
+
+	first, err := c.Send(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	results := []*Result{first}
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+		wg       sync.WaitGroup
+	)
+	jobs := make(chan struct{})
+	for range min(sendNConcurrency, n-1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				result, err := c.Send(ctx, req)
+				mu.Lock()
+				if err != nil {
+					if firstErr == nil {
+						firstErr = err
+					}
+				} else {
+					results = append(results, result)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for range n - 1 {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		select {
+		case jobs <- struct{}{}:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	stats := aggregate(results)
+	if err := ctx.Err(); err != nil {
+		return stats, err
+	}
+	if firstErr != nil && !errors.Is(firstErr, context.Canceled) {
+		return stats, firstErr
+	}
+	return stats, nil
+}
+
+func aggregate(results []*Result) *Stats {
+	stats := &Stats{Count: len(results), ByStatus: make(map[codes.Code]int)}
+	if len(results) == 0 {
+		return stats
+	}
+
+	durations := make([]time.Duration, 0, len(results))
+	var total time.Duration
+	for _, r := range results {
+		code := codes.Code(r.StatusCode)
+		stats.ByStatus[code]++
+		if code == codes.OK {
+			stats.Success++
+		}
+		durations = append(durations, r.Duration)
+		total += r.Duration
+	}
+
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	stats.Min = durations[0]
+	stats.Max = durations[len(durations)-1]
+	stats.Avg = total / time.Duration(len(durations))
+	rank := int(math.Ceil(0.99 * float64(len(durations))))
+	stats.P99 = durations[rank-1]
+	return stats
+}
@@ -0,0 +1,132 @@
+package replay_test
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mickamy/grpc-scope/replay"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// startSlowHealthServer starts a reflection-enabled health server whose unary
+// calls take delay. It returns the address and the peak number of concurrent calls.
+func startSlowHealthServer(t *testing.T, delay time.Duration) (string, *atomic.Int32) {
+	t.Helper()
+
+	var inFlight, peak atomic.Int32
+	srv := grpc.NewServer(grpc.UnaryInterceptor(
+		func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(delay)
+			return handler(ctx, req)
+		},
+	))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	reflection.Register(srv)
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			// server stopped
+		}
+	}()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String(), &peak
+}
+
+func TestClient_SendN(t *testing.T) {
+	t.Parallel()
+
+	addr, peak := startSlowHealthServer(t, 5*time.Millisecond)
+
+	client, err := replay.NewClient(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	req := replay.Request{Method: "/grpc.health.v1.Health/Check", PayloadJSON: "{}"}
+	stats, err := client.SendN(t.Context(), req, 20)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats.Count != 20 {
+		t.Errorf("got count %d, want 20", stats.Count)
+	}
+	if stats.Success != 20 {
+		t.Errorf("got %d successes, want 20", stats.Success)
+	}
+	if got := stats.ByStatus[codes.OK]; got != 20 {
+		t.Errorf("got %d OK in breakdown, want 20", got)
+	}
+	if stats.Min <= 0 || stats.Min > stats.Avg || stats.Avg > stats.Max || stats.P99 > stats.Max {
+		t.Errorf("inconsistent durations: min=%s avg=%s p99=%s max=%s", stats.Min, stats.Avg, stats.P99, stats.Max)
+	}
+	if got := peak.Load(); got > 4 {
+		t.Errorf("got %d concurrent calls, want at most 4", got)
+	}
+}
+
+func TestClient_SendN_StatusBreakdown(t *testing.T) {
+	t.Parallel()
+
+	addr, _ := startReflectionServer(t)
+
+	client, err := replay.NewClient(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	// The health server reports NotFound for unknown services.
+	req := replay.Request{Method: "/grpc.health.v1.Health/Check", PayloadJSON: `{"service":"missing"}`}
+	stats, err := client.SendN(t.Context(), req, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Success != 0 {
+		t.Errorf("got %d successes, want 0", stats.Success)
+	}
+	if got := stats.ByStatus[codes.NotFound]; got != 5 {
+		t.Errorf("got %d NotFound in breakdown, want 5", got)
+	}
+}
+
+func TestClient_SendN_InvalidRequest(t *testing.T) {
+	t.Parallel()
+
+	addr, _ := startReflectionServer(t)
+
+	client, err := replay.NewClient(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	if _, err := client.SendN(t.Context(), replay.Request{Method: "/grpc.health.v1.Health/Check"}, 0); err == nil {
+		t.Error("expected error for a count of 0")
+	}
+	if _, err := client.SendN(t.Context(), replay.Request{Method: "/grpc.health.v1.Health/Check", PayloadJSON: "not json"}, 3); err == nil {
+		t.Error("expected error for invalid request JSON")
+	}
+}
@@ -0,0 +1,33 @@
+package replay
+
+import "fmt"
+
+// rawMessage holds a message body in its wire encoding.
+type rawMessage struct {
+	b []byte
+}
+
+// rawCodec passes rawMessage bodies through untouched. It is named "proto" so
+// the content-type on the wire matches a regular call.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	m, ok := v.(*rawMessage)
+	if !ok {
+		return nil, fmt.Errorf("replay: raw codec cannot marshal %T", v)
+	}
+	return m.b, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	m, ok := v.(*rawMessage)
+	if !ok {
+		return fmt.Errorf("replay: raw codec cannot unmarshal into %T", v)
+	}
+	m.b = append([]byte(nil), data...)
+	return nil
+}
+
+func (rawCodec) Name() string {
+	return "proto"
+}
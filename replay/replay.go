@@ -1,12 +1,18 @@
 package replay
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/json"
 	"fmt"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/metadata"
 	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
@@ -20,30 +26,90 @@ import (
 	"google.golang.org/protobuf/types/dynamicpb"
 )
 
+// defaultTimeout bounds a replayed call when the Request sets no Timeout.
+const defaultTimeout = 30 * time.Second
+
 // Request holds the information needed to replay a gRPC call.
 type Request struct {
-	Method      string              // full method path, e.g. "/pkg.Service/Method"
-	PayloadJSON string              // JSON request body
-	Metadata    map[string][]string // metadata to forward
+	Method             string              // full method path, e.g. "/pkg.Service/Method"
+	PayloadJSON        string              // JSON request body
+	RawRequest         []byte              // wire-encoded request body; takes precedence over PayloadJSON
+	Metadata           map[string][]string // metadata to forward
+	RotateMetadataKeys []string            // keys set to a fresh UUID on every send, e.g. "idempotency-key"
+	Timeout            time.Duration       // call timeout; zero uses defaultTimeout
 }
 
 // Result holds the outcome of a replayed gRPC call.
 type Result struct {
-	ResponseJSON     string
+	ResponseJSON     string // empty for raw replays whose method could not be resolved
+	RawResponse      []byte // wire-encoded response body; set only for raw replays
 	StatusCode       uint32
 	StatusMessage    string
 	Duration         time.Duration
 	ResponseHeaders  metadata.MD
 	ResponseTrailers metadata.MD
+	Idempotency      Idempotency // idempotency level declared by the method
+}
+
+// Idempotency is the idempotency_level a method declares in its options.
+type Idempotency = descriptorpb.MethodOptions_IdempotencyLevel
+
+// Idempotency levels, re-exported from descriptorpb.
+const (
+	IdempotencyUnknown = descriptorpb.MethodOptions_IDEMPOTENCY_UNKNOWN
+	NoSideEffects      = descriptorpb.MethodOptions_NO_SIDE_EFFECTS
+	Idempotent         = descriptorpb.MethodOptions_IDEMPOTENT
+)
+
+// IsSafe reports whether a method with the given level declares that it has
+// no side effects, so replaying it cannot change server state.
+func IsSafe(level Idempotency) bool {
+	return level == NoSideEffects
+}
+
+// Option configures a Client.
+type Option func(*clientOptions)
+
+type clientOptions struct {
+	descriptorSetPath string
+}
+
+// WithDescriptorSet makes the Client resolve types from a compiled
+// FileDescriptorSet (e.g. `protoc --include_imports -o` or `buf build -o`)
+// when the server does not support reflection.
+func WithDescriptorSet(path string) Option {
+	return func(o *clientOptions) {
+		o.descriptorSetPath = path
+	}
 }
 
 // Client manages a gRPC connection to the application server for replaying calls.
+// Service descriptors resolved via reflection are cached for the lifetime of the Client.
 type Client struct {
-	conn *grpc.ClientConn
+	conn  *grpc.ClientConn
+	local *fallbackResolver // types from the descriptor set; nil if none was given
+
+	mu       sync.Mutex
+	services map[string]protoreflect.ServiceDescriptor // keyed by full service name
 }
 
 // NewClient creates a new replay client connected to the given target address.
-func NewClient(target string) (*Client, error) {
+func NewClient(target string, opts ...Option) (*Client, error) {
+	var o clientOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	c := &Client{services: make(map[string]protoreflect.ServiceDescriptor)}
+
+	if o.descriptorSetPath != "" {
+		local, err := loadDescriptorSet(o.descriptorSetPath)
+		if err != nil {
+			return nil, err
+		}
+		c.local = local
+	}
+
 	conn, err := grpc.NewClient(
 		target,
 		grpc.WithTransportCredentials(insecure.NewCredentials()),
@@ -51,7 +117,28 @@ func NewClient(target string) (*Client, error) {
 	if err != nil {
 		return nil, fmt.Errorf("replay: dial %s: %w", target, err)
 	}
-	return &Client{conn: conn}, nil
+	c.conn = conn
+	return c, nil
+}
+
+func loadDescriptorSet(path string) (*fallbackResolver, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("replay: read descriptor set: %w", err)
+	}
+	set := new(descriptorpb.FileDescriptorSet)
+	if err := proto.Unmarshal(raw, set); err != nil {
+		return nil, fmt.Errorf("replay: unmarshal descriptor set %s: %w", path, err)
+	}
+	return buildFiles(set.GetFile())
+}
+
+// ResetCache drops all cached service descriptors, so the next Send
+// resolves types via reflection again.
+func (c *Client) ResetCache() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.services = make(map[string]protoreflect.ServiceDescriptor)
 }
 
 // Close releases the underlying gRPC connection.
@@ -60,48 +147,63 @@ func (c *Client) Close() error {
 }
 
 // Send replays a gRPC unary call using server reflection to resolve types dynamically.
+//
+// When req.RawRequest is set, those exact bytes are sent instead and types are
+// only needed to decode the response. If the method cannot be resolved, the
+// call is still made and the response is returned undecoded in RawResponse, so
+// captured calls can be reproduced against servers without reflection.
 func (c *Client) Send(ctx context.Context, req Request) (*Result, error) {
 	svc, method, err := ParseMethod(req.Method)
 	if err != nil {
 		return nil, err
 	}
 
-	inputDesc, outputDesc, err := c.resolveMethod(ctx, svc, method)
-	if err != nil {
+	methodDesc, err := c.resolveMethod(ctx, svc, method)
+	if err != nil && req.RawRequest == nil {
 		return nil, err
 	}
-
-	payload := req.PayloadJSON
-	if payload == "" {
-		payload = "{}"
+	if methodDesc != nil && (methodDesc.IsStreamingClient() || methodDesc.IsStreamingServer()) {
+		return nil, fmt.Errorf("replay: streaming methods cannot be replayed")
 	}
 
-	reqMsg := dynamicpb.NewMessage(inputDesc)
-	if err := protojson.Unmarshal([]byte(payload), reqMsg); err != nil {
-		return nil, fmt.Errorf("replay: unmarshal request JSON: %w", err)
-	}
+	var reqMsg, respMsg any
+	var callOpts []grpc.CallOption
+	if req.RawRequest != nil {
+		reqMsg, respMsg = &rawMessage{b: req.RawRequest}, new(rawMessage)
+		callOpts = append(callOpts, grpc.ForceCodec(rawCodec{}))
+	} else {
+		payload := req.PayloadJSON
+		if payload == "" {
+			payload = "{}"
+		}
 
-	respMsg := dynamicpb.NewMessage(outputDesc)
+		dynReq := dynamicpb.NewMessage(methodDesc.Input())
+		if err := protojson.Unmarshal([]byte(payload), dynReq); err != nil {
+			return nil, fmt.Errorf("replay: unmarshal request JSON: %w", err)
+		}
+		reqMsg, respMsg = dynReq, dynamicpb.NewMessage(methodDesc.Output())
+	}
 
 	md := FilterMetadata(req.Metadata)
 	if md == nil {
 		md = metadata.MD{}
 	}
+	for _, key := range req.RotateMetadataKeys {
+		md.Set(key, newUUID())
+	}
 	outCtx := metadata.NewOutgoingContext(ctx, md)
 
-	callCtx, cancel := context.WithTimeout(outCtx, 30*time.Second)
+	timeout := req.Timeout
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+	callCtx, cancel := context.WithTimeout(outCtx, timeout)
 	defer cancel()
 
 	var respHeaders, respTrailers metadata.MD
+	callOpts = append(callOpts, grpc.Header(&respHeaders), grpc.Trailer(&respTrailers))
 	start := time.Now()
-	invokeErr := c.conn.Invoke(
-		callCtx,
-		req.Method,
-		reqMsg,
-		respMsg,
-		grpc.Header(&respHeaders),
-		grpc.Trailer(&respTrailers),
-	)
+	invokeErr := c.conn.Invoke(callCtx, req.Method, reqMsg, respMsg, callOpts...)
 	elapsed := time.Since(start)
 
 	result := &Result{
@@ -109,6 +211,9 @@ func (c *Client) Send(ctx context.Context, req Request) (*Result, error) {
 		ResponseHeaders:  respHeaders,
 		ResponseTrailers: respTrailers,
 	}
+	if methodDesc != nil {
+		result.Idempotency = idempotencyOf(methodDesc)
+	}
 
 	if invokeErr != nil {
 		st, _ := status.FromError(invokeErr)
@@ -117,7 +222,23 @@ func (c *Client) Send(ctx context.Context, req Request) (*Result, error) {
 		return result, nil
 	}
 
-	respJSON, err := protojson.Marshal(respMsg)
+	var decoded proto.Message
+	switch resp := respMsg.(type) {
+	case *rawMessage:
+		result.RawResponse = resp.b
+		if methodDesc == nil {
+			return result, nil
+		}
+		dynResp := dynamicpb.NewMessage(methodDesc.Output())
+		if err := proto.Unmarshal(resp.b, dynResp); err != nil {
+			return nil, fmt.Errorf("replay: unmarshal response: %w", err)
+		}
+		decoded = dynResp
+	case proto.Message:
+		decoded = resp
+	}
+
+	respJSON, err := protojson.Marshal(decoded)
 	if err != nil {
 		return nil, fmt.Errorf("replay: marshal response JSON: %w", err)
 	}
@@ -126,6 +247,70 @@ func (c *Client) Send(ctx context.Context, req Request) (*Result, error) {
 	return result, nil
 }
 
+// Idempotency resolves fullMethod and returns the idempotency level it
+// declares, without invoking it. Methods that declare nothing report
+// IdempotencyUnknown.
+func (c *Client) Idempotency(ctx context.Context, fullMethod string) (Idempotency, error) {
+	svc, method, err := ParseMethod(fullMethod)
+	if err != nil {
+		return IdempotencyUnknown, err
+	}
+	methodDesc, err := c.resolveMethod(ctx, svc, method)
+	if err != nil {
+		return IdempotencyUnknown, err
+	}
+	return idempotencyOf(methodDesc), nil
+}
+
+// Describe resolves fullMethod like Send and returns its descriptor, whose
+// Input and Output give the schema of its payloads.
+func (c *Client) Describe(ctx context.Context, fullMethod string) (protoreflect.MethodDescriptor, error) {
+	svc, method, err := ParseMethod(fullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return c.resolveMethod(ctx, svc, method)
+}
+
+// RequestJSON re-encodes payload, a JSON request for fullMethod, with proto
+// field names such as "batch_size" if protoNames is set, or otherwise with
+// the lowerCamelCase JSON names ("batchSize") the interceptors capture.
+// Send, like grpcurl, accepts either naming, so both forms replay the same
+// request; this only changes how a payload reads when copied.
+func (c *Client) RequestJSON(ctx context.Context, fullMethod, payload string, protoNames bool) (string, error) {
+	svc, method, err := ParseMethod(fullMethod)
+	if err != nil {
+		return "", err
+	}
+	methodDesc, err := c.resolveMethod(ctx, svc, method)
+	if err != nil {
+		return "", err
+	}
+
+	msg := dynamicpb.NewMessage(methodDesc.Input())
+	if err := protojson.Unmarshal([]byte(payload), msg); err != nil {
+		return "", fmt.Errorf("replay: unmarshal request JSON: %w", err)
+	}
+	raw, err := protojson.MarshalOptions{UseProtoNames: protoNames}.Marshal(msg)
+	if err != nil {
+		return "", fmt.Errorf("replay: marshal request JSON: %w", err)
+	}
+	// protojson output is deliberately unstable; compact it.
+	var out bytes.Buffer
+	if err := json.Compact(&out, raw); err != nil {
+		return "", fmt.Errorf("replay: compact request JSON: %w", err)
+	}
+	return out.String(), nil
+}
+
+func idempotencyOf(methodDesc protoreflect.MethodDescriptor) Idempotency {
+	opts, ok := methodDesc.Options().(*descriptorpb.MethodOptions)
+	if !ok {
+		return IdempotencyUnknown
+	}
+	return opts.GetIdempotencyLevel()
+}
+
 // ParseMethod splits "/pkg.Service/Method" into ("pkg.Service", "Method").
 func ParseMethod(fullMethod string) (string, string, error) {
 	fullMethod = strings.TrimPrefix(fullMethod, "/")
@@ -136,13 +321,44 @@ func ParseMethod(fullMethod string) (string, string, error) {
 	return parts[0], parts[1], nil
 }
 
-// resolveMethod uses gRPC server reflection to find the input/output message descriptors
-// for the given service and method.
-func (c *Client) resolveMethod(ctx context.Context, svc, method string) (protoreflect.MessageDescriptor, protoreflect.MessageDescriptor, error) {
+// resolveMethod finds the descriptor of the given service and method,
+// resolving the service via reflection on first use.
+func (c *Client) resolveMethod(ctx context.Context, svc, method string) (protoreflect.MethodDescriptor, error) {
+	c.mu.Lock()
+	serviceDesc, ok := c.services[svc]
+	c.mu.Unlock()
+
+	if !ok {
+		var err error
+		serviceDesc, err = c.resolveService(ctx, svc)
+		if err != nil && c.local != nil {
+			serviceDesc, err = findService(c.local, svc)
+		}
+		if err != nil {
+			if status.Code(err) == codes.Unimplemented && c.local == nil {
+				return nil, fmt.Errorf("%w (pass --descriptor-set to replay without reflection)", err)
+			}
+			return nil, err
+		}
+		c.mu.Lock()
+		c.services[svc] = serviceDesc
+		c.mu.Unlock()
+	}
+
+	methodDesc := serviceDesc.Methods().ByName(protoreflect.Name(method))
+	if methodDesc == nil {
+		return nil, fmt.Errorf("replay: method %q not found in service %q", method, svc)
+	}
+
+	return methodDesc, nil
+}
+
+// resolveService uses gRPC server reflection to find the descriptor of the given service.
+func (c *Client) resolveService(ctx context.Context, svc string) (protoreflect.ServiceDescriptor, error) {
 	refClient := reflectionpb.NewServerReflectionClient(c.conn)
 	stream, err := refClient.ServerReflectionInfo(ctx)
 	if err != nil {
-		return nil, nil, fmt.Errorf("replay: open reflection stream: %w", err)
+		return nil, fmt.Errorf("replay: open reflection stream: %w", err)
 	}
 	defer func() { _ = stream.CloseSend() }()
 
@@ -152,73 +368,101 @@ func (c *Client) resolveMethod(ctx context.Context, svc, method string) (protore
 			FileContainingSymbol: svc,
 		},
 	}); err != nil {
-		return nil, nil, fmt.Errorf("replay: send reflection request: %w", err)
+		return nil, fmt.Errorf("replay: send reflection request: %w", err)
 	}
 
 	resp, err := stream.Recv()
 	if err != nil {
-		return nil, nil, fmt.Errorf("replay: recv reflection response: %w", err)
+		return nil, fmt.Errorf("replay: recv reflection response: %w", err)
 	}
 
 	fdResp := resp.GetFileDescriptorResponse()
 	if fdResp == nil {
 		if errResp := resp.GetErrorResponse(); errResp != nil {
-			return nil, nil, fmt.Errorf("replay: reflection error: %s", errResp.GetErrorMessage())
+			return nil, fmt.Errorf("replay: reflection error: %s", errResp.GetErrorMessage())
 		}
-		return nil, nil, fmt.Errorf("replay: unexpected reflection response")
+		return nil, fmt.Errorf("replay: unexpected reflection response")
 	}
 
-	// Build a protoregistry.Files from the returned file descriptors.
-	// Use a resolver that falls back to GlobalFiles for well-known types
-	// (e.g. google/protobuf/timestamp.proto) that may not be included in
-	// the reflection response.
-	files := new(protoregistry.Files)
-	resolver := &fallbackResolver{local: files, global: protoregistry.GlobalFiles}
+	fdProtos := make([]*descriptorpb.FileDescriptorProto, 0, len(fdResp.GetFileDescriptorProto()))
 	for _, raw := range fdResp.GetFileDescriptorProto() {
 		fdProto := new(descriptorpb.FileDescriptorProto)
 		if err := proto.Unmarshal(raw, fdProto); err != nil {
-			return nil, nil, fmt.Errorf("replay: unmarshal file descriptor: %w", err)
+			return nil, fmt.Errorf("replay: unmarshal file descriptor: %w", err)
 		}
+		fdProtos = append(fdProtos, fdProto)
+	}
 
+	resolver, err := buildFiles(fdProtos)
+	if err != nil {
+		return nil, err
+	}
+	return findService(resolver, svc)
+}
+
+// buildFiles builds a registry from the given file descriptors, registering
+// dependencies before the files that import them. The returned resolver falls
+// back to GlobalFiles for well-known types (e.g. google/protobuf/timestamp.proto)
+// that may not be included in the descriptors.
+func buildFiles(fdProtos []*descriptorpb.FileDescriptorProto) (*fallbackResolver, error) {
+	files := new(protoregistry.Files)
+	resolver := &fallbackResolver{local: files, global: protoregistry.GlobalFiles}
+
+	byName := make(map[string]*descriptorpb.FileDescriptorProto, len(fdProtos))
+	for _, fdProto := range fdProtos {
+		byName[fdProto.GetName()] = fdProto
+	}
+
+	var register func(fdProto *descriptorpb.FileDescriptorProto) error
+	register = func(fdProto *descriptorpb.FileDescriptorProto) error {
 		// Skip if already registered (dependencies may overlap).
 		if _, regErr := files.FindFileByPath(fdProto.GetName()); regErr == nil {
-			continue
+			return nil
 		}
 		// Skip if available in global registry (well-known types).
 		if _, regErr := protoregistry.GlobalFiles.FindFileByPath(fdProto.GetName()); regErr == nil {
-			continue
+			return nil
+		}
+
+		for _, dep := range fdProto.GetDependency() {
+			if depProto, ok := byName[dep]; ok {
+				if err := register(depProto); err != nil {
+					return err
+				}
+			}
 		}
 
 		fd, err := protodesc.NewFile(fdProto, resolver)
 		if err != nil {
-			return nil, nil, fmt.Errorf("replay: build file descriptor %s: %w", fdProto.GetName(), err)
+			return fmt.Errorf("replay: build file descriptor %s: %w", fdProto.GetName(), err)
 		}
 		if err := files.RegisterFile(fd); err != nil {
-			return nil, nil, fmt.Errorf("replay: register file descriptor %s: %w", fdProto.GetName(), err)
+			return fmt.Errorf("replay: register file descriptor %s: %w", fdProto.GetName(), err)
 		}
+		return nil
 	}
 
-	// Find the service descriptor (check local first, then global).
+	for _, fdProto := range fdProtos {
+		if err := register(fdProto); err != nil {
+			return nil, err
+		}
+	}
+	return resolver, nil
+}
+
+// findService looks up a service descriptor by its full name (local first, then global).
+func findService(resolver *fallbackResolver, svc string) (protoreflect.ServiceDescriptor, error) {
 	svcDesc, err := resolver.FindDescriptorByName(protoreflect.FullName(svc))
 	if err != nil {
-		return nil, nil, fmt.Errorf("replay: find service %q: %w", svc, err)
+		return nil, fmt.Errorf("replay: find service %q: %w", svc, err)
 	}
 
 	serviceDesc, ok := svcDesc.(protoreflect.ServiceDescriptor)
 	if !ok {
-		return nil, nil, fmt.Errorf("replay: %q is not a service", svc)
+		return nil, fmt.Errorf("replay: %q is not a service", svc)
 	}
 
-	methodDesc := serviceDesc.Methods().ByName(protoreflect.Name(method))
-	if methodDesc == nil {
-		return nil, nil, fmt.Errorf("replay: method %q not found in service %q", method, svc)
-	}
-
-	if methodDesc.IsStreamingClient() || methodDesc.IsStreamingServer() {
-		return nil, nil, fmt.Errorf("replay: streaming methods cannot be replayed")
-	}
-
-	return methodDesc.Input(), methodDesc.Output(), nil
+	return serviceDesc, nil
 }
 
 // fallbackResolver tries the local registry first, then falls back to global.
@@ -244,6 +488,15 @@ func (r *fallbackResolver) FindDescriptorByName(name protoreflect.FullName) (pro
 	return r.global.FindDescriptorByName(name)
 }
 
+// newUUID returns a random (version 4) UUID.
+func newUUID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
 // FilterMetadata removes internal gRPC headers that should not be forwarded.
 func FilterMetadata(md map[string][]string) metadata.MD {
 	if md == nil {
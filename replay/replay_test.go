@@ -1,11 +1,63 @@
 package replay_test
 
 import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/mickamy/grpc-scope/replay"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/emptypb"
 )
 
+// startReflectionServer starts a gRPC server exposing the health service and
+// server reflection. It returns the address and a counter of reflection streams.
+func startReflectionServer(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+
+	var reflectionCalls atomic.Int32
+	srv := grpc.NewServer(grpc.StreamInterceptor(
+		func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			if strings.HasPrefix(info.FullMethod, "/grpc.reflection.") {
+				reflectionCalls.Add(1)
+			}
+			return handler(srv, ss)
+		},
+	))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	reflection.Register(srv)
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			// server stopped
+		}
+	}()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String(), &reflectionCalls
+}
+
 func TestParseMethod(t *testing.T) {
 	t.Parallel()
 
@@ -166,3 +218,491 @@ func TestRequest_EmptyPayload(t *testing.T) {
 		t.Error("expected empty payload")
 	}
 }
+
+func TestClient_Send_CachesDescriptors(t *testing.T) {
+	t.Parallel()
+
+	addr, reflectionCalls := startReflectionServer(t)
+
+	client, err := replay.NewClient(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	req := replay.Request{Method: "/grpc.health.v1.Health/Check", PayloadJSON: "{}"}
+	for range 3 {
+		result, err := client.Send(t.Context(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.StatusCode != 0 {
+			t.Fatalf("got status %d (%s), want OK", result.StatusCode, result.StatusMessage)
+		}
+		if !strings.Contains(result.ResponseJSON, "SERVING") {
+			t.Errorf("got response %q, want SERVING status", result.ResponseJSON)
+		}
+	}
+
+	if got := reflectionCalls.Load(); got != 1 {
+		t.Errorf("got %d reflection calls, want 1", got)
+	}
+
+	client.ResetCache()
+	if _, err := client.Send(t.Context(), req); err != nil {
+		t.Fatal(err)
+	}
+	if got := reflectionCalls.Load(); got != 2 {
+		t.Errorf("got %d reflection calls after ResetCache, want 2", got)
+	}
+}
+
+// startEchoServer starts a gRPC server without reflection that echoes the
+// request message of any method back as the response.
+func startEchoServer(t *testing.T) string {
+	t.Helper()
+
+	srv := grpc.NewServer(grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+		if method, _ := grpc.MethodFromServerStream(stream); strings.HasPrefix(method, "/grpc.reflection.") {
+			return status.Error(codes.Unimplemented, "reflection is not enabled")
+		}
+		// Unknown fields survive the round trip, so the message is echoed as-is.
+		msg := new(emptypb.Empty)
+		if err := stream.RecvMsg(msg); err != nil {
+			return err
+		}
+		return stream.SendMsg(msg)
+	}))
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			// server stopped
+		}
+	}()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String()
+}
+
+// writeEchoDescriptorSet writes a FileDescriptorSet declaring
+// echo.v1.EchoService/Echo and returns its path. Its reply_to field has a
+// JSON name that differs from the proto name.
+func writeEchoDescriptorSet(t *testing.T) string {
+	t.Helper()
+
+	set := &descriptorpb.FileDescriptorSet{
+		File: []*descriptorpb.FileDescriptorProto{{
+			Name:    proto.String("echo/v1/echo.proto"),
+			Package: proto.String("echo.v1"),
+			Syntax:  proto.String("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{
+				Name: proto.String("EchoMessage"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:     proto.String("message"),
+						JsonName: proto.String("message"),
+						Number:   proto.Int32(1),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					},
+					{
+						Name:     proto.String("reply_to"),
+						JsonName: proto.String("replyTo"),
+						Number:   proto.Int32(2),
+						Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+						Type:     descriptorpb.FieldDescriptorProto_TYPE_STRING.Enum(),
+					},
+				},
+			}},
+			Service: []*descriptorpb.ServiceDescriptorProto{{
+				Name: proto.String("EchoService"),
+				Method: []*descriptorpb.MethodDescriptorProto{
+					{
+						Name:       proto.String("Echo"),
+						InputType:  proto.String(".echo.v1.EchoMessage"),
+						OutputType: proto.String(".echo.v1.EchoMessage"),
+					},
+					{
+						Name:       proto.String("Peek"),
+						InputType:  proto.String(".echo.v1.EchoMessage"),
+						OutputType: proto.String(".echo.v1.EchoMessage"),
+						Options: &descriptorpb.MethodOptions{
+							IdempotencyLevel: descriptorpb.MethodOptions_NO_SIDE_EFFECTS.Enum(),
+						},
+					},
+				},
+			}},
+		}},
+	}
+
+	raw, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "echo.binpb")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestClient_Send_DescriptorSetFallback(t *testing.T) {
+	t.Parallel()
+
+	addr := startEchoServer(t)
+	req := replay.Request{Method: "/echo.v1.EchoService/Echo", PayloadJSON: `{"message":"hello"}`}
+
+	t.Run("without descriptor set", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := replay.NewClient(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = client.Close() })
+
+		_, err = client.Send(t.Context(), req)
+		if err == nil || !strings.Contains(err.Error(), "--descriptor-set") {
+			t.Fatalf("got error %v, want hint about --descriptor-set", err)
+		}
+	})
+
+	t.Run("with descriptor set", func(t *testing.T) {
+		t.Parallel()
+
+		client, err := replay.NewClient(addr, replay.WithDescriptorSet(writeEchoDescriptorSet(t)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = client.Close() })
+
+		result, err := client.Send(t.Context(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.StatusCode != 0 {
+			t.Fatalf("got status %d (%s), want OK", result.StatusCode, result.StatusMessage)
+		}
+		if result.ResponseJSON != `{"message":"hello"}` {
+			t.Errorf("got response %q, want echoed request", result.ResponseJSON)
+		}
+	})
+
+	t.Run("missing descriptor set file", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := replay.NewClient(addr, replay.WithDescriptorSet(filepath.Join(t.TempDir(), "missing.binpb")))
+		if err == nil {
+			t.Fatal("expected error for missing descriptor set")
+		}
+	})
+}
+
+// TestClient_RequestJSON pins the field naming convention: captured payloads
+// use lowerCamelCase JSON names, exports may switch to proto names, and
+// replay accepts both.
+func TestClient_RequestJSON(t *testing.T) {
+	t.Parallel()
+
+	addr := startEchoServer(t)
+	client, err := replay.NewClient(addr, replay.WithDescriptorSet(writeEchoDescriptorSet(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	const captured = `{"message":"hi","replyTo":"me"}`
+	tests := []struct {
+		name       string
+		protoNames bool
+		want       string
+	}{
+		{name: "json names", protoNames: false, want: captured},
+		{name: "proto names", protoNames: true, want: `{"message":"hi","reply_to":"me"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := client.RequestJSON(t.Context(), "/echo.v1.EchoService/Echo", captured, tt.protoNames)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("RequestJSON() = %s, want %s", got, tt.want)
+			}
+
+			result, err := client.Send(t.Context(), replay.Request{Method: "/echo.v1.EchoService/Echo", PayloadJSON: got})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp := strings.ReplaceAll(result.ResponseJSON, " ", ""); resp != captured {
+				t.Errorf("replayed %s, got response %s, want the captured request echoed", got, result.ResponseJSON)
+			}
+		})
+	}
+
+	if _, err := client.RequestJSON(t.Context(), "/echo.v1.EchoService/Echo", `{"unknown":1}`, true); err == nil {
+		t.Error("expected error for a field the method does not declare")
+	}
+}
+
+func TestClient_Idempotency(t *testing.T) {
+	t.Parallel()
+
+	addr := startEchoServer(t)
+	client, err := replay.NewClient(addr, replay.WithDescriptorSet(writeEchoDescriptorSet(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	tests := []struct {
+		method   string
+		want     replay.Idempotency
+		wantSafe bool
+	}{
+		{method: "/echo.v1.EchoService/Peek", want: replay.NoSideEffects, wantSafe: true},
+		{method: "/echo.v1.EchoService/Echo", want: replay.IdempotencyUnknown, wantSafe: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := client.Idempotency(t.Context(), tt.method)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got idempotency %s, want %s", got, tt.want)
+			}
+			if replay.IsSafe(got) != tt.wantSafe {
+				t.Errorf("got IsSafe %v, want %v", replay.IsSafe(got), tt.wantSafe)
+			}
+		})
+	}
+}
+
+func TestClient_Describe(t *testing.T) {
+	t.Parallel()
+
+	addr := startEchoServer(t)
+	client, err := replay.NewClient(addr, replay.WithDescriptorSet(writeEchoDescriptorSet(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	desc, err := client.Describe(t.Context(), "/echo.v1.EchoService/Echo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := desc.Input().Fields().ByJSONName("replyTo"); got == nil {
+		t.Errorf("expected the request schema of %s to declare replyTo", desc.FullName())
+	}
+	if _, err := client.Describe(t.Context(), "/echo.v1.EchoService/Missing"); err == nil {
+		t.Error("expected error for a method the service does not declare")
+	}
+}
+
+// bytesCodec hands a server the undecoded message body.
+type bytesCodec struct{}
+
+func (bytesCodec) Marshal(v any) ([]byte, error)      { return *v.(*[]byte), nil }
+func (bytesCodec) Unmarshal(data []byte, v any) error { *v.(*[]byte) = data; return nil }
+func (bytesCodec) Name() string                       { return "proto" }
+
+// startRawEchoServer starts a gRPC server without reflection that echoes the
+// request body of any method back byte for byte, recording what it received.
+func startRawEchoServer(t *testing.T) (string, *atomic.Pointer[[]byte]) {
+	t.Helper()
+
+	var received atomic.Pointer[[]byte]
+	srv := grpc.NewServer(
+		grpc.ForceServerCodec(bytesCodec{}),
+		grpc.UnknownServiceHandler(func(_ any, stream grpc.ServerStream) error {
+			if method, _ := grpc.MethodFromServerStream(stream); strings.HasPrefix(method, "/grpc.reflection.") {
+				return status.Error(codes.Unimplemented, "reflection is not enabled")
+			}
+			var body []byte
+			if err := stream.RecvMsg(&body); err != nil {
+				return err
+			}
+			body = slices.Clone(body)
+			received.Store(&body)
+			return stream.SendMsg(&body)
+		}),
+	)
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			// server stopped
+		}
+	}()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String(), &received
+}
+
+func TestClient_Send_RawRequest(t *testing.T) {
+	t.Parallel()
+
+	// An unknown field before field 1: re-encoding through a message type
+	// would reorder or drop it, so only a byte-exact replay preserves it.
+	var raw []byte
+	raw = protowire.AppendTag(raw, 99, protowire.VarintType)
+	raw = protowire.AppendVarint(raw, 7)
+	raw = protowire.AppendTag(raw, 1, protowire.BytesType)
+	raw = protowire.AppendString(raw, "hello")
+
+	tests := []struct {
+		name          string
+		descriptorSet bool
+		wantJSON      string
+	}{
+		{name: "without types", wantJSON: ""},
+		{name: "with descriptor set", descriptorSet: true, wantJSON: `{"message":"hello"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			addr, received := startRawEchoServer(t)
+			var opts []replay.Option
+			if tt.descriptorSet {
+				opts = append(opts, replay.WithDescriptorSet(writeEchoDescriptorSet(t)))
+			}
+			client, err := replay.NewClient(addr, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = client.Close() })
+
+			result, err := client.Send(t.Context(), replay.Request{
+				Method:      "/echo.v1.EchoService/Echo",
+				PayloadJSON: `{"message":"ignored"}`,
+				RawRequest:  raw,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.StatusCode != 0 {
+				t.Fatalf("got status %d (%s), want OK", result.StatusCode, result.StatusMessage)
+			}
+			if got := received.Load(); got == nil || !bytes.Equal(*got, raw) {
+				t.Errorf("server received %x, want %x", got, raw)
+			}
+			if !bytes.Equal(result.RawResponse, raw) {
+				t.Errorf("got raw response %x, want %x", result.RawResponse, raw)
+			}
+			if result.ResponseJSON != tt.wantJSON {
+				t.Errorf("got response JSON %q, want %q", result.ResponseJSON, tt.wantJSON)
+			}
+		})
+	}
+}
+
+func TestClient_Send_Timeout(t *testing.T) {
+	t.Parallel()
+
+	addr, _ := startSlowHealthServer(t, 200*time.Millisecond)
+
+	client, err := replay.NewClient(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	result, err := client.Send(t.Context(), replay.Request{
+		Method:  "/grpc.health.v1.Health/Check",
+		Timeout: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := codes.Code(result.StatusCode); got != codes.DeadlineExceeded {
+		t.Errorf("got status %s, want DeadlineExceeded", got)
+	}
+}
+
+// startMetadataServer starts a health and reflection server that sends the
+// incoming metadata of every unary call to the returned channel.
+func startMetadataServer(t *testing.T) (string, <-chan metadata.MD) {
+	t.Helper()
+
+	received := make(chan metadata.MD, 10)
+	srv := grpc.NewServer(grpc.UnaryInterceptor(
+		func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			md, _ := metadata.FromIncomingContext(ctx)
+			received <- md
+			return handler(ctx, req)
+		},
+	))
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	reflection.Register(srv)
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			// server stopped
+		}
+	}()
+	t.Cleanup(srv.Stop)
+
+	return lis.Addr().String(), received
+}
+
+func TestClient_Send_RotateMetadataKeys(t *testing.T) {
+	t.Parallel()
+
+	addr, received := startMetadataServer(t)
+
+	client, err := replay.NewClient(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = client.Close() })
+
+	req := replay.Request{
+		Method: "/grpc.health.v1.Health/Check",
+		Metadata: map[string][]string{
+			"idempotency-key": {"captured-key"},
+			"x-tenant":        {"acme"},
+		},
+		RotateMetadataKeys: []string{"Idempotency-Key"},
+	}
+
+	var keys []string
+	for range 2 {
+		if _, err := client.Send(t.Context(), req); err != nil {
+			t.Fatal(err)
+		}
+		md := <-received
+		if got := md.Get("x-tenant"); !slices.Equal(got, []string{"acme"}) {
+			t.Errorf("got x-tenant %v, want [acme]", got)
+		}
+		got := md.Get("idempotency-key")
+		if len(got) != 1 || got[0] == "captured-key" {
+			t.Fatalf("got idempotency-key %v, want one fresh key", got)
+		}
+		keys = append(keys, got[0])
+	}
+
+	if keys[0] == keys[1] {
+		t.Errorf("both replays sent idempotency-key %q, want distinct keys", keys[0])
+	}
+}
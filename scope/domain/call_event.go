@@ -1,6 +1,26 @@
 package domain
 
-import "time"
+import (
+	"sort"
+	"strings"
+	"time"
+)
+
+// HeaderPreviousAttempts is the metadata key gRPC clients use to report how
+// many attempts preceded a retried call.
+const HeaderPreviousAttempts = "grpc-previous-rpc-attempts"
+
+// HeaderForwardedPath is the metadata key an HTTP/JSON front end such as
+// grpc-gateway uses to forward the original HTTP request path.
+const HeaderForwardedPath = "x-forwarded-path"
+
+// DeadlineSourceClient is the DeadlineSource of a deadline that no
+// deadline-injecting interceptor claimed, i.e. one the client set.
+const DeadlineSourceClient = "client"
+
+// HeaderScopeToken is the metadata key Watch clients use to present the
+// scope server's shared token.
+const HeaderScopeToken = "x-scope-token"
 
 // StatusCode represents a gRPC status code.
 type StatusCode int32
@@ -26,15 +46,54 @@ const (
 	StatusUnauthenticated                      // gRPC 16
 )
 
+// Direction tells whether a call was received or made by the application.
+type Direction int32
+
+const (
+	DirectionUnspecified Direction = iota // zero value = unset
+	DirectionInbound                      // handled by a server interceptor
+	DirectionOutbound                     // made through a client interceptor
+)
+
+// Protocols a call can be made over, as recorded in CallEvent.Protocol. The
+// values match connect.Peer.Protocol.
+const (
+	ProtocolGRPC    = "grpc"
+	ProtocolGRPCWeb = "grpcweb"
+	ProtocolConnect = "connect"
+)
+
 // Metadata represents gRPC metadata (headers/trailers).
 type Metadata map[string][]string
 
+// Keys returns the metadata keys in sorted order, so that callers iterating
+// over metadata produce stable output.
+func (md Metadata) Keys() []string {
+	keys := make([]string, 0, len(md))
+	for k := range md {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get returns the first value for key, matching keys case-insensitively.
+// It returns an empty string if the key is not present.
+func (md Metadata) Get(key string) string {
+	for k, vs := range md {
+		if strings.EqualFold(k, key) && len(vs) > 0 {
+			return vs[0]
+		}
+	}
+	return ""
+}
+
 // CallEvent represents a single captured gRPC call.
 type CallEvent struct {
 	ID               string
 	Method           string
 	StartTime        time.Time
-	Duration         time.Duration
+	Duration         time.Duration // time spent in the handler, or in the invoker for outbound calls
 	StatusCode       StatusCode
 	StatusMessage    string
 	RequestMetadata  Metadata
@@ -42,6 +101,89 @@ type CallEvent struct {
 	ResponseTrailers Metadata
 	RequestPayload   string
 	ResponsePayload  string
+	Attempt          int       // attempts preceding this call, from grpc-previous-rpc-attempts; 0 if not a retry
+	ContentEncoding  string    // request compression negotiated by the client, e.g. "gzip"; empty if uncompressed
+	Deadline         time.Time // deadline set by the client; zero if the call had none
+	Direction        Direction
+	Protocol         string // wire protocol the call was made over, e.g. ProtocolGRPC
+	HTTPPath         string // original HTTP path forwarded by a gateway in x-forwarded-path; empty for direct calls
+
+	// ResponseContentType is the Content-Type the response was sent with,
+	// e.g. "application/json" for a Connect error answering a proto request.
+	// The request's Content-Type is in RequestMetadata.
+	ResponseContentType string
+
+	// Seq is the publish order of the event within one Scope, starting at 1.
+	// It is assigned by Scope.Publish.
+	Seq uint64
+
+	// Goroutines is runtime.NumGoroutine() when the event was published, or
+	// 0 unless runtime stats are enabled. A count that keeps climbing with
+	// one method's calls points at a goroutine leak.
+	Goroutines int
+
+	// TotalDuration runs from the interceptor seeing the call until the event
+	// was built, so TotalDuration - Duration is the interceptor's own overhead,
+	// mostly payload marshaling. It is set only for unary calls.
+	TotalDuration time.Duration
+
+	// DeadlineSource tells who set Deadline: DeadlineSourceClient, or the
+	// marker a deadline-injecting interceptor stored in the context. It is
+	// empty when the call had no deadline or sources are not tracked.
+	DeadlineSource string
+
+	// RequestContentLength is the Content-Length header the request declared,
+	// or 0 if it declared none. RequestBodySize is the size of the body the
+	// decoded request message encodes to, or 0 when that cannot be derived,
+	// e.g. for JSON or compressed requests. SizeMismatch is set when both are
+	// known and differ, which points at a proxy or transcoder rewriting the
+	// body. A valid but non-canonical encoding, e.g. with a repeated scalar
+	// field, differs too, so it is a hint rather than proof. Only Connect
+	// handlers record these.
+	RequestContentLength int64
+	RequestBodySize      int64
+	SizeMismatch         bool
+
+	// StatusDetails holds the details attached to an error status, such as
+	// google.rpc.ErrorInfo or BadRequest, each as JSON with an "@type" field.
+	// Details whose type is not linked into the application are shown by
+	// type alone.
+	StatusDetails []string
+
+	// NilResponse is set when a unary handler returned neither a response nor
+	// an error. The call looks OK with an empty response, but the handler
+	// most likely forgot to build its reply.
+	NilResponse bool
+
+	// PeerIdentity is the client identity an mTLS handshake verified: the
+	// SPIFFE ID in its certificate if it has one, or else the certificate
+	// subject. It is empty for plaintext calls and clients without a
+	// verified certificate.
+	PeerIdentity string
+
+	// SchemaWarnings describes where an inbound unary request strayed from
+	// its proto schema, such as unknown fields, undefined enum numbers, or
+	// deprecated fields, pointing at client/server contract drift. See
+	// scope.SchemaWarnings.
+	SchemaWarnings []string
+
+	// Streaming is set for client, server and bidi streaming calls.
+	// SentCount and RecvCount count the messages such a call sent and
+	// received, as seen from the side the interceptor runs on, so a server
+	// stream that failed before its first message has a SentCount of 0.
+	Streaming bool
+	SentCount int64
+	RecvCount int64
+
+	// RequestBytesRaw is the unary request in its protobuf wire encoding,
+	// captured with WithRawRequestBytes. Replays send it in place of
+	// RequestPayload, which stays for display.
+	RequestBytesRaw []byte
+
+	// Labels are arbitrary key/value pairs describing the call, such as a
+	// tenant or user ID, returned by the extractor set with
+	// scope.WithLabelExtractor.
+	Labels map[string]string
 }
 
 // IsError reports whether the call ended with a non-OK status.
@@ -49,6 +191,20 @@ func (e CallEvent) IsError() bool {
 	return e.StatusCode != StatusOK
 }
 
+// String returns the lowercase name of the direction.
+func (d Direction) String() string {
+	switch d {
+	case DirectionInbound:
+		return "inbound"
+	case DirectionOutbound:
+		return "outbound"
+	case DirectionUnspecified:
+		return "unspecified"
+	default:
+		return "unknown"
+	}
+}
+
 // StatusCodeString returns the short string representation of the status code.
 func (c StatusCode) String() string {
 	switch c {
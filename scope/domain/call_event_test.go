@@ -1,6 +1,7 @@
 package domain_test
 
 import (
+	"slices"
 	"testing"
 	"time"
 
@@ -100,3 +101,54 @@ func TestStatusCode_String(t *testing.T) {
 		})
 	}
 }
+
+func TestMetadata_Keys(t *testing.T) {
+	t.Parallel()
+
+	md := domain.Metadata{
+		"x-request-id":  {"abc"},
+		":authority":    {"localhost"},
+		"authorization": {"Bearer token"},
+		"content-type":  {"application/grpc"},
+	}
+	want := []string{":authority", "authorization", "content-type", "x-request-id"}
+
+	for range 10 {
+		if got := md.Keys(); !slices.Equal(got, want) {
+			t.Fatalf("Keys() = %v, want %v", got, want)
+		}
+	}
+
+	if got := domain.Metadata(nil).Keys(); len(got) != 0 {
+		t.Errorf("Keys() on nil metadata = %v, want empty", got)
+	}
+}
+
+func TestMetadata_Get(t *testing.T) {
+	t.Parallel()
+
+	md := domain.Metadata{
+		"Grpc-Previous-Rpc-Attempts": {"2", "3"},
+		"x-empty":                    {},
+	}
+
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{name: "case-insensitive match", key: domain.HeaderPreviousAttempts, want: "2"},
+		{name: "missing key", key: "x-missing", want: ""},
+		{name: "key without values", key: "x-empty", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := md.Get(tt.key); got != tt.want {
+				t.Errorf("Get(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
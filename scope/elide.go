@@ -0,0 +1,204 @@
+package scope
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// elideArrays rewrites the JSON document s so that every array keeps at most
+// n elements. Dropped elements are replaced by a single marker string such as
+// "... 42 more elided", so the output remains valid JSON and object keys keep
+// their original order. If s is not valid JSON it is returned unchanged.
+func elideArrays(s string, n int) string {
+	if n <= 0 || !strings.ContainsRune(s, '[') {
+		return s
+	}
+
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	if err := writeElided(dec, &buf, n); err != nil {
+		return s
+	}
+	if _, err := dec.Token(); err == nil {
+		// trailing data after the top-level value
+		return s
+	}
+	return buf.String()
+}
+
+func writeElided(dec *json.Decoder, buf *bytes.Buffer, n int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			buf.WriteByte('{')
+			for i := 0; dec.More(); i++ {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				writeJSONString(buf, fmt.Sprint(key))
+				buf.WriteByte(':')
+				if err := writeElided(dec, buf, n); err != nil {
+					return err
+				}
+			}
+			buf.WriteByte('}')
+		case '[':
+			buf.WriteByte('[')
+			count := 0
+			for ; dec.More(); count++ {
+				if count >= n {
+					var skip json.RawMessage
+					if err := dec.Decode(&skip); err != nil {
+						return err
+					}
+					continue
+				}
+				if count > 0 {
+					buf.WriteByte(',')
+				}
+				if err := writeElided(dec, buf, n); err != nil {
+					return err
+				}
+			}
+			if count > n {
+				buf.WriteByte(',')
+				writeJSONString(buf, fmt.Sprintf("... %d more elided", count-n))
+			}
+			buf.WriteByte(']')
+		default:
+			return fmt.Errorf("unexpected delimiter %q", t)
+		}
+		// consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	case string:
+		writeJSONString(buf, t)
+	case json.Number:
+		buf.WriteString(t.String())
+	case bool:
+		fmt.Fprintf(buf, "%t", t)
+	case nil:
+		buf.WriteString("null")
+	default:
+		return fmt.Errorf("unexpected token %v", t)
+	}
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	// Encode appends a newline.
+	buf.Truncate(buf.Len() - 1)
+}
+
+// bytesSentinelPrefix marks a bytes field value replaced by elideBytes.
+const bytesSentinelPrefix = "\x00grpc-scope:elided-bytes:"
+
+// marshalElidingBytes marshals msg with protojson after replacing every bytes
+// value longer than limit, at any depth, with a "<N bytes>" placeholder
+// string. msg itself is not modified.
+func marshalElidingBytes(msg proto.Message, limit int) (string, error) {
+	clone := proto.Clone(msg)
+	sizes := make(map[int]struct{})
+	elideBytes(clone.ProtoReflect(), limit, sizes)
+
+	b, err := protojson.Marshal(clone)
+	if err != nil {
+		return "", err
+	}
+	out := string(b)
+	for size := range sizes {
+		sentinel, err := json.Marshal(bytesSentinel(size))
+		if err != nil {
+			return "", err
+		}
+		out = strings.ReplaceAll(out, string(sentinel), fmt.Sprintf(`"<%d bytes>"`, size))
+	}
+	return out, nil
+}
+
+// bytesSentinel is the value a bytes field of the given size is replaced with.
+// protojson renders it as a base64 string that marshalElidingBytes then swaps
+// for the placeholder.
+func bytesSentinel(size int) []byte {
+	return []byte(bytesSentinelPrefix + strconv.Itoa(size))
+}
+
+// elideBytes replaces bytes values longer than limit in m with sentinels,
+// recording the original sizes. google.protobuf.Any is left alone, since its
+// bytes hold the embedded message protojson needs to decode.
+func elideBytes(m protoreflect.Message, limit int, sizes map[int]struct{}) {
+	if m.Descriptor().FullName() == "google.protobuf.Any" {
+		return
+	}
+	elide := func(v protoreflect.Value) (protoreflect.Value, bool) {
+		b := v.Bytes()
+		if len(b) <= limit {
+			return v, false
+		}
+		sizes[len(b)] = struct{}{}
+		return protoreflect.ValueOfBytes(bytesSentinel(len(b))), true
+	}
+	isMessage := func(k protoreflect.Kind) bool {
+		return k == protoreflect.MessageKind || k == protoreflect.GroupKind
+	}
+
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case fd.IsMap():
+			vd := fd.MapValue()
+			v.Map().Range(func(k protoreflect.MapKey, mv protoreflect.Value) bool {
+				switch {
+				case vd.Kind() == protoreflect.BytesKind:
+					if nv, ok := elide(mv); ok {
+						v.Map().Set(k, nv)
+					}
+				case isMessage(vd.Kind()):
+					elideBytes(mv.Message(), limit, sizes)
+				}
+				return true
+			})
+		case fd.IsList():
+			list := v.List()
+			for i := range list.Len() {
+				switch {
+				case fd.Kind() == protoreflect.BytesKind:
+					if nv, ok := elide(list.Get(i)); ok {
+						list.Set(i, nv)
+					}
+				case isMessage(fd.Kind()):
+					elideBytes(list.Get(i).Message(), limit, sizes)
+				}
+			}
+		case fd.Kind() == protoreflect.BytesKind:
+			if nv, ok := elide(v); ok {
+				m.Set(fd, nv)
+			}
+		case isMessage(fd.Kind()):
+			elideBytes(v.Message(), limit, sizes)
+		}
+		return true
+	})
+}
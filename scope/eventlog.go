@@ -0,0 +1,72 @@
+package scope
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/mickamy/grpc-scope/scope/domain"
+)
+
+// LogFormat selects how WithStderrLog writes events.
+type LogFormat string
+
+const (
+	LogFormatJSON   LogFormat = "json"
+	LogFormatLogfmt LogFormat = "logfmt"
+)
+
+// WithStderrLog also writes every published event to stderr as a one-line
+// structured log in format, for CI logs or containers where the TUI cannot
+// run. Events are logged whether or not a TUI is connected; failed calls are
+// logged at warning level. Payloads are included as captured, so the payload
+// options and processors apply to the log too.
+func WithStderrLog(format LogFormat) Option {
+	return func(s *Scope) {
+		s.eventLog = newEventLogger(os.Stderr, format)
+	}
+}
+
+func newEventLogger(w io.Writer, format LogFormat) *slog.Logger {
+	if format == LogFormatJSON {
+		return slog.New(slog.NewJSONHandler(w, nil))
+	}
+	return slog.New(slog.NewTextHandler(w, nil))
+}
+
+// logEvent writes ev to l as a single record.
+func logEvent(l *slog.Logger, ev domain.CallEvent) {
+	attrs := []slog.Attr{
+		slog.String("id", ev.ID),
+		slog.Uint64("seq", ev.Seq),
+		slog.String("method", ev.Method),
+		slog.String("direction", ev.Direction.String()),
+		slog.String("status", ev.StatusCode.String()),
+		slog.Duration("duration", ev.Duration),
+	}
+	if ev.Protocol != "" {
+		attrs = append(attrs, slog.String("protocol", ev.Protocol))
+	}
+	if ev.StatusMessage != "" {
+		attrs = append(attrs, slog.String("message", ev.StatusMessage))
+	}
+	if ev.Attempt > 0 {
+		attrs = append(attrs, slog.Int("attempt", ev.Attempt))
+	}
+	if ev.PeerIdentity != "" {
+		attrs = append(attrs, slog.String("peer", ev.PeerIdentity))
+	}
+	if ev.RequestPayload != "" {
+		attrs = append(attrs, slog.String("request", ev.RequestPayload))
+	}
+	if ev.ResponsePayload != "" {
+		attrs = append(attrs, slog.String("response", ev.ResponsePayload))
+	}
+
+	level := slog.LevelInfo
+	if ev.IsError() {
+		level = slog.LevelWarn
+	}
+	l.LogAttrs(context.Background(), level, "grpc-scope: call", attrs...)
+}
@@ -23,21 +23,93 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type Direction int32
+
+const (
+	Direction_DIRECTION_UNSPECIFIED Direction = 0
+	Direction_DIRECTION_INBOUND     Direction = 1
+	Direction_DIRECTION_OUTBOUND    Direction = 2
+)
+
+// Enum value maps for Direction.
+var (
+	Direction_name = map[int32]string{
+		0: "DIRECTION_UNSPECIFIED",
+		1: "DIRECTION_INBOUND",
+		2: "DIRECTION_OUTBOUND",
+	}
+	Direction_value = map[string]int32{
+		"DIRECTION_UNSPECIFIED": 0,
+		"DIRECTION_INBOUND":     1,
+		"DIRECTION_OUTBOUND":    2,
+	}
+)
+
+func (x Direction) Enum() *Direction {
+	p := new(Direction)
+	*p = x
+	return p
+}
+
+func (x Direction) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Direction) Descriptor() protoreflect.EnumDescriptor {
+	return file_scope_v1_scope_proto_enumTypes[0].Descriptor()
+}
+
+func (Direction) Type() protoreflect.EnumType {
+	return &file_scope_v1_scope_proto_enumTypes[0]
+}
+
+func (x Direction) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Direction.Descriptor instead.
+func (Direction) EnumDescriptor() ([]byte, []int) {
+	return file_scope_v1_scope_proto_rawDescGZIP(), []int{0}
+}
+
 type CallEvent struct {
-	state            protoimpl.MessageState     `protogen:"open.v1"`
-	Id               string                     `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	Method           string                     `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
-	StartTime        *timestamppb.Timestamp     `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
-	Duration         *durationpb.Duration       `protobuf:"bytes,4,opt,name=duration,proto3" json:"duration,omitempty"`
-	StatusCode       int32                      `protobuf:"varint,5,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
-	StatusMessage    string                     `protobuf:"bytes,6,opt,name=status_message,json=statusMessage,proto3" json:"status_message,omitempty"`
-	RequestMetadata  map[string]*MetadataValues `protobuf:"bytes,7,rep,name=request_metadata,json=requestMetadata,proto3" json:"request_metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	ResponseHeaders  map[string]*MetadataValues `protobuf:"bytes,8,rep,name=response_headers,json=responseHeaders,proto3" json:"response_headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	ResponseTrailers map[string]*MetadataValues `protobuf:"bytes,9,rep,name=response_trailers,json=responseTrailers,proto3" json:"response_trailers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	RequestPayload   string                     `protobuf:"bytes,10,opt,name=request_payload,json=requestPayload,proto3" json:"request_payload,omitempty"`
-	ResponsePayload  string                     `protobuf:"bytes,11,opt,name=response_payload,json=responsePayload,proto3" json:"response_payload,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	state                protoimpl.MessageState     `protogen:"open.v1"`
+	Id                   string                     `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Method               string                     `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`
+	StartTime            *timestamppb.Timestamp     `protobuf:"bytes,3,opt,name=start_time,json=startTime,proto3" json:"start_time,omitempty"`
+	Duration             *durationpb.Duration       `protobuf:"bytes,4,opt,name=duration,proto3" json:"duration,omitempty"`
+	StatusCode           int32                      `protobuf:"varint,5,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	StatusMessage        string                     `protobuf:"bytes,6,opt,name=status_message,json=statusMessage,proto3" json:"status_message,omitempty"`
+	RequestMetadata      map[string]*MetadataValues `protobuf:"bytes,7,rep,name=request_metadata,json=requestMetadata,proto3" json:"request_metadata,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ResponseHeaders      map[string]*MetadataValues `protobuf:"bytes,8,rep,name=response_headers,json=responseHeaders,proto3" json:"response_headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ResponseTrailers     map[string]*MetadataValues `protobuf:"bytes,9,rep,name=response_trailers,json=responseTrailers,proto3" json:"response_trailers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	RequestPayload       string                     `protobuf:"bytes,10,opt,name=request_payload,json=requestPayload,proto3" json:"request_payload,omitempty"`
+	ResponsePayload      string                     `protobuf:"bytes,11,opt,name=response_payload,json=responsePayload,proto3" json:"response_payload,omitempty"`
+	Attempt              int32                      `protobuf:"varint,12,opt,name=attempt,proto3" json:"attempt,omitempty"`
+	ContentEncoding      string                     `protobuf:"bytes,13,opt,name=content_encoding,json=contentEncoding,proto3" json:"content_encoding,omitempty"`
+	Deadline             *timestamppb.Timestamp     `protobuf:"bytes,14,opt,name=deadline,proto3" json:"deadline,omitempty"`
+	Direction            Direction                  `protobuf:"varint,15,opt,name=direction,proto3,enum=scope.v1.Direction" json:"direction,omitempty"`
+	HttpPath             string                     `protobuf:"bytes,16,opt,name=http_path,json=httpPath,proto3" json:"http_path,omitempty"`
+	ResponseContentType  string                     `protobuf:"bytes,17,opt,name=response_content_type,json=responseContentType,proto3" json:"response_content_type,omitempty"`
+	Seq                  uint64                     `protobuf:"varint,18,opt,name=seq,proto3" json:"seq,omitempty"`
+	Goroutines           int32                      `protobuf:"varint,19,opt,name=goroutines,proto3" json:"goroutines,omitempty"`
+	TotalDuration        *durationpb.Duration       `protobuf:"bytes,20,opt,name=total_duration,json=totalDuration,proto3" json:"total_duration,omitempty"`
+	DeadlineSource       string                     `protobuf:"bytes,21,opt,name=deadline_source,json=deadlineSource,proto3" json:"deadline_source,omitempty"`
+	RequestContentLength int64                      `protobuf:"varint,22,opt,name=request_content_length,json=requestContentLength,proto3" json:"request_content_length,omitempty"`
+	RequestBodySize      int64                      `protobuf:"varint,23,opt,name=request_body_size,json=requestBodySize,proto3" json:"request_body_size,omitempty"`
+	SizeMismatch         bool                       `protobuf:"varint,24,opt,name=size_mismatch,json=sizeMismatch,proto3" json:"size_mismatch,omitempty"`
+	StatusDetails        []string                   `protobuf:"bytes,25,rep,name=status_details,json=statusDetails,proto3" json:"status_details,omitempty"`
+	Protocol             string                     `protobuf:"bytes,26,opt,name=protocol,proto3" json:"protocol,omitempty"`
+	NilResponse          bool                       `protobuf:"varint,27,opt,name=nil_response,json=nilResponse,proto3" json:"nil_response,omitempty"`
+	PeerIdentity         string                     `protobuf:"bytes,28,opt,name=peer_identity,json=peerIdentity,proto3" json:"peer_identity,omitempty"`
+	SchemaWarnings       []string                   `protobuf:"bytes,29,rep,name=schema_warnings,json=schemaWarnings,proto3" json:"schema_warnings,omitempty"`
+	Streaming            bool                       `protobuf:"varint,30,opt,name=streaming,proto3" json:"streaming,omitempty"`
+	SentCount            int64                      `protobuf:"varint,31,opt,name=sent_count,json=sentCount,proto3" json:"sent_count,omitempty"`
+	RecvCount            int64                      `protobuf:"varint,32,opt,name=recv_count,json=recvCount,proto3" json:"recv_count,omitempty"`
+	RequestBytesRaw      []byte                     `protobuf:"bytes,33,opt,name=request_bytes_raw,json=requestBytesRaw,proto3" json:"request_bytes_raw,omitempty"`
+	Labels               map[string]string          `protobuf:"bytes,34,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
 }
 
 func (x *CallEvent) Reset() {
@@ -147,6 +219,167 @@ func (x *CallEvent) GetResponsePayload() string {
 	return ""
 }
 
+func (x *CallEvent) GetAttempt() int32 {
+	if x != nil {
+		return x.Attempt
+	}
+	return 0
+}
+
+func (x *CallEvent) GetContentEncoding() string {
+	if x != nil {
+		return x.ContentEncoding
+	}
+	return ""
+}
+
+func (x *CallEvent) GetDeadline() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Deadline
+	}
+	return nil
+}
+
+func (x *CallEvent) GetDirection() Direction {
+	if x != nil {
+		return x.Direction
+	}
+	return Direction_DIRECTION_UNSPECIFIED
+}
+
+func (x *CallEvent) GetHttpPath() string {
+	if x != nil {
+		return x.HttpPath
+	}
+	return ""
+}
+
+func (x *CallEvent) GetResponseContentType() string {
+	if x != nil {
+		return x.ResponseContentType
+	}
+	return ""
+}
+
+func (x *CallEvent) GetSeq() uint64 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *CallEvent) GetGoroutines() int32 {
+	if x != nil {
+		return x.Goroutines
+	}
+	return 0
+}
+
+func (x *CallEvent) GetTotalDuration() *durationpb.Duration {
+	if x != nil {
+		return x.TotalDuration
+	}
+	return nil
+}
+
+func (x *CallEvent) GetDeadlineSource() string {
+	if x != nil {
+		return x.DeadlineSource
+	}
+	return ""
+}
+
+func (x *CallEvent) GetRequestContentLength() int64 {
+	if x != nil {
+		return x.RequestContentLength
+	}
+	return 0
+}
+
+func (x *CallEvent) GetRequestBodySize() int64 {
+	if x != nil {
+		return x.RequestBodySize
+	}
+	return 0
+}
+
+func (x *CallEvent) GetSizeMismatch() bool {
+	if x != nil {
+		return x.SizeMismatch
+	}
+	return false
+}
+
+func (x *CallEvent) GetStatusDetails() []string {
+	if x != nil {
+		return x.StatusDetails
+	}
+	return nil
+}
+
+func (x *CallEvent) GetProtocol() string {
+	if x != nil {
+		return x.Protocol
+	}
+	return ""
+}
+
+func (x *CallEvent) GetNilResponse() bool {
+	if x != nil {
+		return x.NilResponse
+	}
+	return false
+}
+
+func (x *CallEvent) GetPeerIdentity() string {
+	if x != nil {
+		return x.PeerIdentity
+	}
+	return ""
+}
+
+func (x *CallEvent) GetSchemaWarnings() []string {
+	if x != nil {
+		return x.SchemaWarnings
+	}
+	return nil
+}
+
+func (x *CallEvent) GetStreaming() bool {
+	if x != nil {
+		return x.Streaming
+	}
+	return false
+}
+
+func (x *CallEvent) GetSentCount() int64 {
+	if x != nil {
+		return x.SentCount
+	}
+	return 0
+}
+
+func (x *CallEvent) GetRecvCount() int64 {
+	if x != nil {
+		return x.RecvCount
+	}
+	return 0
+}
+
+func (x *CallEvent) GetRequestBytesRaw() []byte {
+	if x != nil {
+		return x.RequestBytesRaw
+	}
+	return nil
+}
+
+func (x *CallEvent) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
 type MetadataValues struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Values        []string               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
@@ -193,6 +426,9 @@ func (x *MetadataValues) GetValues() []string {
 
 type WatchRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
+	BatchSize     int32                  `protobuf:"varint,1,opt,name=batch_size,json=batchSize,proto3" json:"batch_size,omitempty"`
+	BatchInterval *durationpb.Duration   `protobuf:"bytes,2,opt,name=batch_interval,json=batchInterval,proto3" json:"batch_interval,omitempty"`
+	History       *durationpb.Duration   `protobuf:"bytes,3,opt,name=history,proto3" json:"history,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -227,9 +463,32 @@ func (*WatchRequest) Descriptor() ([]byte, []int) {
 	return file_scope_v1_scope_proto_rawDescGZIP(), []int{2}
 }
 
+func (x *WatchRequest) GetBatchSize() int32 {
+	if x != nil {
+		return x.BatchSize
+	}
+	return 0
+}
+
+func (x *WatchRequest) GetBatchInterval() *durationpb.Duration {
+	if x != nil {
+		return x.BatchInterval
+	}
+	return nil
+}
+
+func (x *WatchRequest) GetHistory() *durationpb.Duration {
+	if x != nil {
+		return x.History
+	}
+	return nil
+}
+
 type WatchResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Event         *CallEvent             `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	Events        []*CallEvent           `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	Dropped       uint64                 `protobuf:"varint,3,opt,name=dropped,proto3" json:"dropped,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -271,11 +530,249 @@ func (x *WatchResponse) GetEvent() *CallEvent {
 	return nil
 }
 
+func (x *WatchResponse) GetEvents() []*CallEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *WatchResponse) GetDropped() uint64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+type ServerInfoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ServerInfoRequest) Reset() {
+	*x = ServerInfoRequest{}
+	mi := &file_scope_v1_scope_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerInfoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerInfoRequest) ProtoMessage() {}
+
+func (x *ServerInfoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scope_v1_scope_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerInfoRequest.ProtoReflect.Descriptor instead.
+func (*ServerInfoRequest) Descriptor() ([]byte, []int) {
+	return file_scope_v1_scope_proto_rawDescGZIP(), []int{4}
+}
+
+type ServerInfoResponse struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	AppTarget         string                 `protobuf:"bytes,1,opt,name=app_target,json=appTarget,proto3" json:"app_target,omitempty"`
+	MaxShownElements  int32                  `protobuf:"varint,2,opt,name=max_shown_elements,json=maxShownElements,proto3" json:"max_shown_elements,omitempty"`
+	HeartbeatInterval *durationpb.Duration   `protobuf:"bytes,3,opt,name=heartbeat_interval,json=heartbeatInterval,proto3" json:"heartbeat_interval,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *ServerInfoResponse) Reset() {
+	*x = ServerInfoResponse{}
+	mi := &file_scope_v1_scope_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ServerInfoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ServerInfoResponse) ProtoMessage() {}
+
+func (x *ServerInfoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_scope_v1_scope_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ServerInfoResponse.ProtoReflect.Descriptor instead.
+func (*ServerInfoResponse) Descriptor() ([]byte, []int) {
+	return file_scope_v1_scope_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ServerInfoResponse) GetAppTarget() string {
+	if x != nil {
+		return x.AppTarget
+	}
+	return ""
+}
+
+func (x *ServerInfoResponse) GetMaxShownElements() int32 {
+	if x != nil {
+		return x.MaxShownElements
+	}
+	return 0
+}
+
+func (x *ServerInfoResponse) GetHeartbeatInterval() *durationpb.Duration {
+	if x != nil {
+		return x.HeartbeatInterval
+	}
+	return nil
+}
+
+type GetHistoryRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Method        string                 `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"`
+	StatusCode    int32                  `protobuf:"varint,2,opt,name=status_code,json=statusCode,proto3" json:"status_code,omitempty"`
+	ErrorsOnly    bool                   `protobuf:"varint,3,opt,name=errors_only,json=errorsOnly,proto3" json:"errors_only,omitempty"`
+	Since         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=since,proto3" json:"since,omitempty"`
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryRequest) Reset() {
+	*x = GetHistoryRequest{}
+	mi := &file_scope_v1_scope_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryRequest) ProtoMessage() {}
+
+func (x *GetHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_scope_v1_scope_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_scope_v1_scope_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetHistoryRequest) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *GetHistoryRequest) GetStatusCode() int32 {
+	if x != nil {
+		return x.StatusCode
+	}
+	return 0
+}
+
+func (x *GetHistoryRequest) GetErrorsOnly() bool {
+	if x != nil {
+		return x.ErrorsOnly
+	}
+	return false
+}
+
+func (x *GetHistoryRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *GetHistoryRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+type GetHistoryResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*CallEvent           `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	Truncated     bool                   `protobuf:"varint,2,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetHistoryResponse) Reset() {
+	*x = GetHistoryResponse{}
+	mi := &file_scope_v1_scope_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetHistoryResponse) ProtoMessage() {}
+
+func (x *GetHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_scope_v1_scope_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_scope_v1_scope_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetHistoryResponse) GetEvents() []*CallEvent {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *GetHistoryResponse) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
 var File_scope_v1_scope_proto protoreflect.FileDescriptor
 
 const file_scope_v1_scope_proto_rawDesc = "" +
 	"\n" +
-	"\x14scope/v1/scope.proto\x12\bscope.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\"\xde\x06\n" +
+	"\x14scope/v1/scope.proto\x12\bscope.v1\x1a\x1fgoogle/protobuf/timestamp.proto\x1a\x1egoogle/protobuf/duration.proto\"\xb3\x0e\n" +
 	"\tCallEvent\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x16\n" +
 	"\x06method\x18\x02 \x01(\tR\x06method\x129\n" +
@@ -290,7 +787,34 @@ const file_scope_v1_scope_proto_rawDesc = "" +
 	"\x11response_trailers\x18\t \x03(\v2).scope.v1.CallEvent.ResponseTrailersEntryR\x10responseTrailers\x12'\n" +
 	"\x0frequest_payload\x18\n" +
 	" \x01(\tR\x0erequestPayload\x12)\n" +
-	"\x10response_payload\x18\v \x01(\tR\x0fresponsePayload\x1a\\\n" +
+	"\x10response_payload\x18\v \x01(\tR\x0fresponsePayload\x12\x18\n" +
+	"\aattempt\x18\f \x01(\x05R\aattempt\x12)\n" +
+	"\x10content_encoding\x18\r \x01(\tR\x0fcontentEncoding\x126\n" +
+	"\bdeadline\x18\x0e \x01(\v2\x1a.google.protobuf.TimestampR\bdeadline\x121\n" +
+	"\tdirection\x18\x0f \x01(\x0e2\x13.scope.v1.DirectionR\tdirection\x12\x1b\n" +
+	"\thttp_path\x18\x10 \x01(\tR\bhttpPath\x122\n" +
+	"\x15response_content_type\x18\x11 \x01(\tR\x13responseContentType\x12\x10\n" +
+	"\x03seq\x18\x12 \x01(\x04R\x03seq\x12\x1e\n" +
+	"\n" +
+	"goroutines\x18\x13 \x01(\x05R\n" +
+	"goroutines\x12@\n" +
+	"\x0etotal_duration\x18\x14 \x01(\v2\x19.google.protobuf.DurationR\rtotalDuration\x12'\n" +
+	"\x0fdeadline_source\x18\x15 \x01(\tR\x0edeadlineSource\x124\n" +
+	"\x16request_content_length\x18\x16 \x01(\x03R\x14requestContentLength\x12*\n" +
+	"\x11request_body_size\x18\x17 \x01(\x03R\x0frequestBodySize\x12#\n" +
+	"\rsize_mismatch\x18\x18 \x01(\bR\fsizeMismatch\x12%\n" +
+	"\x0estatus_details\x18\x19 \x03(\tR\rstatusDetails\x12\x1a\n" +
+	"\bprotocol\x18\x1a \x01(\tR\bprotocol\x12!\n" +
+	"\fnil_response\x18\x1b \x01(\bR\vnilResponse\x12#\n" +
+	"\rpeer_identity\x18\x1c \x01(\tR\fpeerIdentity\x12'\n" +
+	"\x0fschema_warnings\x18\x1d \x03(\tR\x0eschemaWarnings\x12\x1c\n" +
+	"\tstreaming\x18\x1e \x01(\bR\tstreaming\x12\x1d\n" +
+	"\n" +
+	"sent_count\x18\x1f \x01(\x03R\tsentCount\x12\x1d\n" +
+	"\n" +
+	"recv_count\x18  \x01(\x03R\trecvCount\x12*\n" +
+	"\x11request_bytes_raw\x18! \x01(\fR\x0frequestBytesRaw\x127\n" +
+	"\x06labels\x18\" \x03(\v2\x1f.scope.v1.CallEvent.LabelsEntryR\x06labels\x1a\\\n" +
 	"\x14RequestMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12.\n" +
 	"\x05value\x18\x02 \x01(\v2\x18.scope.v1.MetadataValuesR\x05value:\x028\x01\x1a\\\n" +
@@ -299,14 +823,48 @@ const file_scope_v1_scope_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\v2\x18.scope.v1.MetadataValuesR\x05value:\x028\x01\x1a]\n" +
 	"\x15ResponseTrailersEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12.\n" +
-	"\x05value\x18\x02 \x01(\v2\x18.scope.v1.MetadataValuesR\x05value:\x028\x01\"(\n" +
+	"\x05value\x18\x02 \x01(\v2\x18.scope.v1.MetadataValuesR\x05value:\x028\x01\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"(\n" +
 	"\x0eMetadataValues\x12\x16\n" +
-	"\x06values\x18\x01 \x03(\tR\x06values\"\x0e\n" +
-	"\fWatchRequest\":\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\"\xa4\x01\n" +
+	"\fWatchRequest\x12\x1d\n" +
+	"\n" +
+	"batch_size\x18\x01 \x01(\x05R\tbatchSize\x12@\n" +
+	"\x0ebatch_interval\x18\x02 \x01(\v2\x19.google.protobuf.DurationR\rbatchInterval\x123\n" +
+	"\ahistory\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\ahistory\"\x81\x01\n" +
 	"\rWatchResponse\x12)\n" +
-	"\x05event\x18\x01 \x01(\v2\x13.scope.v1.CallEventR\x05event2J\n" +
+	"\x05event\x18\x01 \x01(\v2\x13.scope.v1.CallEventR\x05event\x12+\n" +
+	"\x06events\x18\x02 \x03(\v2\x13.scope.v1.CallEventR\x06events\x12\x18\n" +
+	"\adropped\x18\x03 \x01(\x04R\adropped\"\x13\n" +
+	"\x11ServerInfoRequest\"\xab\x01\n" +
+	"\x12ServerInfoResponse\x12\x1d\n" +
+	"\n" +
+	"app_target\x18\x01 \x01(\tR\tappTarget\x12,\n" +
+	"\x12max_shown_elements\x18\x02 \x01(\x05R\x10maxShownElements\x12H\n" +
+	"\x12heartbeat_interval\x18\x03 \x01(\v2\x19.google.protobuf.DurationR\x11heartbeatInterval\"\xb5\x01\n" +
+	"\x11GetHistoryRequest\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x1f\n" +
+	"\vstatus_code\x18\x02 \x01(\x05R\n" +
+	"statusCode\x12\x1f\n" +
+	"\verrors_only\x18\x03 \x01(\bR\n" +
+	"errorsOnly\x120\n" +
+	"\x05since\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x12\x14\n" +
+	"\x05limit\x18\x05 \x01(\x05R\x05limit\"_\n" +
+	"\x12GetHistoryResponse\x12+\n" +
+	"\x06events\x18\x01 \x03(\v2\x13.scope.v1.CallEventR\x06events\x12\x1c\n" +
+	"\ttruncated\x18\x02 \x01(\bR\ttruncated*U\n" +
+	"\tDirection\x12\x19\n" +
+	"\x15DIRECTION_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11DIRECTION_INBOUND\x10\x01\x12\x16\n" +
+	"\x12DIRECTION_OUTBOUND\x10\x022\xdc\x01\n" +
 	"\fScopeService\x12:\n" +
-	"\x05Watch\x12\x16.scope.v1.WatchRequest\x1a\x17.scope.v1.WatchResponse0\x01B\x95\x01\n" +
+	"\x05Watch\x12\x16.scope.v1.WatchRequest\x1a\x17.scope.v1.WatchResponse0\x01\x12G\n" +
+	"\n" +
+	"ServerInfo\x12\x1b.scope.v1.ServerInfoRequest\x1a\x1c.scope.v1.ServerInfoResponse\x12G\n" +
+	"\n" +
+	"GetHistory\x12\x1b.scope.v1.GetHistoryRequest\x1a\x1c.scope.v1.GetHistoryResponseB\x95\x01\n" +
 	"\fcom.scope.v1B\n" +
 	"ScopeProtoP\x01Z8github.com/mickamy/grpc-scope/scope/gen/scope/v1;scopev1\xa2\x02\x03SXX\xaa\x02\bScope.V1\xca\x02\bScope\\V1\xe2\x02\x14Scope\\V1\\GPBMetadata\xea\x02\tScope::V1b\x06proto3"
 
@@ -322,35 +880,56 @@ func file_scope_v1_scope_proto_rawDescGZIP() []byte {
 	return file_scope_v1_scope_proto_rawDescData
 }
 
-var file_scope_v1_scope_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_scope_v1_scope_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_scope_v1_scope_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
 var file_scope_v1_scope_proto_goTypes = []any{
-	(*CallEvent)(nil),             // 0: scope.v1.CallEvent
-	(*MetadataValues)(nil),        // 1: scope.v1.MetadataValues
-	(*WatchRequest)(nil),          // 2: scope.v1.WatchRequest
-	(*WatchResponse)(nil),         // 3: scope.v1.WatchResponse
-	nil,                           // 4: scope.v1.CallEvent.RequestMetadataEntry
-	nil,                           // 5: scope.v1.CallEvent.ResponseHeadersEntry
-	nil,                           // 6: scope.v1.CallEvent.ResponseTrailersEntry
-	(*timestamppb.Timestamp)(nil), // 7: google.protobuf.Timestamp
-	(*durationpb.Duration)(nil),   // 8: google.protobuf.Duration
+	(Direction)(0),                // 0: scope.v1.Direction
+	(*CallEvent)(nil),             // 1: scope.v1.CallEvent
+	(*MetadataValues)(nil),        // 2: scope.v1.MetadataValues
+	(*WatchRequest)(nil),          // 3: scope.v1.WatchRequest
+	(*WatchResponse)(nil),         // 4: scope.v1.WatchResponse
+	(*ServerInfoRequest)(nil),     // 5: scope.v1.ServerInfoRequest
+	(*ServerInfoResponse)(nil),    // 6: scope.v1.ServerInfoResponse
+	(*GetHistoryRequest)(nil),     // 7: scope.v1.GetHistoryRequest
+	(*GetHistoryResponse)(nil),    // 8: scope.v1.GetHistoryResponse
+	nil,                           // 9: scope.v1.CallEvent.RequestMetadataEntry
+	nil,                           // 10: scope.v1.CallEvent.ResponseHeadersEntry
+	nil,                           // 11: scope.v1.CallEvent.ResponseTrailersEntry
+	nil,                           // 12: scope.v1.CallEvent.LabelsEntry
+	(*timestamppb.Timestamp)(nil), // 13: google.protobuf.Timestamp
+	(*durationpb.Duration)(nil),   // 14: google.protobuf.Duration
 }
 var file_scope_v1_scope_proto_depIdxs = []int32{
-	7,  // 0: scope.v1.CallEvent.start_time:type_name -> google.protobuf.Timestamp
-	8,  // 1: scope.v1.CallEvent.duration:type_name -> google.protobuf.Duration
-	4,  // 2: scope.v1.CallEvent.request_metadata:type_name -> scope.v1.CallEvent.RequestMetadataEntry
-	5,  // 3: scope.v1.CallEvent.response_headers:type_name -> scope.v1.CallEvent.ResponseHeadersEntry
-	6,  // 4: scope.v1.CallEvent.response_trailers:type_name -> scope.v1.CallEvent.ResponseTrailersEntry
-	0,  // 5: scope.v1.WatchResponse.event:type_name -> scope.v1.CallEvent
-	1,  // 6: scope.v1.CallEvent.RequestMetadataEntry.value:type_name -> scope.v1.MetadataValues
-	1,  // 7: scope.v1.CallEvent.ResponseHeadersEntry.value:type_name -> scope.v1.MetadataValues
-	1,  // 8: scope.v1.CallEvent.ResponseTrailersEntry.value:type_name -> scope.v1.MetadataValues
-	2,  // 9: scope.v1.ScopeService.Watch:input_type -> scope.v1.WatchRequest
-	3,  // 10: scope.v1.ScopeService.Watch:output_type -> scope.v1.WatchResponse
-	10, // [10:11] is the sub-list for method output_type
-	9,  // [9:10] is the sub-list for method input_type
-	9,  // [9:9] is the sub-list for extension type_name
-	9,  // [9:9] is the sub-list for extension extendee
-	0,  // [0:9] is the sub-list for field type_name
+	13, // 0: scope.v1.CallEvent.start_time:type_name -> google.protobuf.Timestamp
+	14, // 1: scope.v1.CallEvent.duration:type_name -> google.protobuf.Duration
+	9,  // 2: scope.v1.CallEvent.request_metadata:type_name -> scope.v1.CallEvent.RequestMetadataEntry
+	10, // 3: scope.v1.CallEvent.response_headers:type_name -> scope.v1.CallEvent.ResponseHeadersEntry
+	11, // 4: scope.v1.CallEvent.response_trailers:type_name -> scope.v1.CallEvent.ResponseTrailersEntry
+	13, // 5: scope.v1.CallEvent.deadline:type_name -> google.protobuf.Timestamp
+	0,  // 6: scope.v1.CallEvent.direction:type_name -> scope.v1.Direction
+	14, // 7: scope.v1.CallEvent.total_duration:type_name -> google.protobuf.Duration
+	12, // 8: scope.v1.CallEvent.labels:type_name -> scope.v1.CallEvent.LabelsEntry
+	14, // 9: scope.v1.WatchRequest.batch_interval:type_name -> google.protobuf.Duration
+	14, // 10: scope.v1.WatchRequest.history:type_name -> google.protobuf.Duration
+	1,  // 11: scope.v1.WatchResponse.event:type_name -> scope.v1.CallEvent
+	1,  // 12: scope.v1.WatchResponse.events:type_name -> scope.v1.CallEvent
+	14, // 13: scope.v1.ServerInfoResponse.heartbeat_interval:type_name -> google.protobuf.Duration
+	13, // 14: scope.v1.GetHistoryRequest.since:type_name -> google.protobuf.Timestamp
+	1,  // 15: scope.v1.GetHistoryResponse.events:type_name -> scope.v1.CallEvent
+	2,  // 16: scope.v1.CallEvent.RequestMetadataEntry.value:type_name -> scope.v1.MetadataValues
+	2,  // 17: scope.v1.CallEvent.ResponseHeadersEntry.value:type_name -> scope.v1.MetadataValues
+	2,  // 18: scope.v1.CallEvent.ResponseTrailersEntry.value:type_name -> scope.v1.MetadataValues
+	3,  // 19: scope.v1.ScopeService.Watch:input_type -> scope.v1.WatchRequest
+	5,  // 20: scope.v1.ScopeService.ServerInfo:input_type -> scope.v1.ServerInfoRequest
+	7,  // 21: scope.v1.ScopeService.GetHistory:input_type -> scope.v1.GetHistoryRequest
+	4,  // 22: scope.v1.ScopeService.Watch:output_type -> scope.v1.WatchResponse
+	6,  // 23: scope.v1.ScopeService.ServerInfo:output_type -> scope.v1.ServerInfoResponse
+	8,  // 24: scope.v1.ScopeService.GetHistory:output_type -> scope.v1.GetHistoryResponse
+	22, // [22:25] is the sub-list for method output_type
+	19, // [19:22] is the sub-list for method input_type
+	19, // [19:19] is the sub-list for extension type_name
+	19, // [19:19] is the sub-list for extension extendee
+	0,  // [0:19] is the sub-list for field type_name
 }
 
 func init() { file_scope_v1_scope_proto_init() }
@@ -363,13 +942,14 @@ func file_scope_v1_scope_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_scope_v1_scope_proto_rawDesc), len(file_scope_v1_scope_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   7,
+			NumEnums:      1,
+			NumMessages:   12,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_scope_v1_scope_proto_goTypes,
 		DependencyIndexes: file_scope_v1_scope_proto_depIdxs,
+		EnumInfos:         file_scope_v1_scope_proto_enumTypes,
 		MessageInfos:      file_scope_v1_scope_proto_msgTypes,
 	}.Build()
 	File_scope_v1_scope_proto = out.File
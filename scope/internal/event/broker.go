@@ -1,29 +1,113 @@
 package event
 
 import (
+	"log/slog"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/mickamy/grpc-scope/scope/domain"
 )
 
+// Policy decides what Publish does when a subscriber's buffer is full.
+type Policy struct {
+	blockTimeout time.Duration
+}
+
+// DropPolicy drops the event for a subscriber whose buffer is full, so
+// Publish never waits. It is the default.
+func DropPolicy() Policy {
+	return Policy{}
+}
+
+// BlockPolicy makes Publish wait up to timeout, in total per call, for full
+// buffers to drain before dropping. Publish runs on the caller's goroutine,
+// so a slow subscriber slows the caller by up to timeout per event.
+func BlockPolicy(timeout time.Duration) Policy {
+	return Policy{blockTimeout: timeout}
+}
+
 // Broker fans out CallEvents to all active subscribers.
 type Broker struct {
 	mu          sync.RWMutex
-	subscribers map[int]chan domain.CallEvent
+	subscribers map[int]*subscriber
 	nextID      int
 	bufSize     int
+	policy      Policy
+	dropped     atomic.Uint64 // across all subscribers, including past ones
+	logger      *slog.Logger
+
+	history       *Store // the last historySize published events
+	historySize   int
+	replayHistory bool // send history to new subscribers
 }
 
-// NewBroker creates a new Broker. bufSize controls the channel buffer size for each subscriber.
+// Option configures a Broker.
+type Option func(*Broker)
+
+// WithLogger makes the Broker log dropped events at debug level.
+func WithLogger(logger *slog.Logger) Option {
+	return func(b *Broker) {
+		b.logger = logger
+	}
+}
+
+// WithHistory makes the Broker keep the last n published events and send
+// them to every new subscriber ahead of live events, as many as its buffer
+// holds.
+func WithHistory(n int) Option {
+	return func(b *Broker) {
+		b.historySize = max(n, b.historySize)
+		b.replayHistory = true
+	}
+}
+
+// WithRetention makes the Broker keep the last n published events for
+// History and Query, without sending them to new subscribers. Combined with
+// WithHistory, the larger size applies.
+func WithRetention(n int) Option {
+	return func(b *Broker) {
+		b.historySize = max(n, b.historySize)
+	}
+}
+
+type subscriber struct {
+	ch      chan domain.CallEvent
+	dropped atomic.Uint64
+}
+
+// NewBroker creates a new Broker that drops events for slow subscribers.
+// bufSize controls the channel buffer size for each subscriber.
 func NewBroker(bufSize int) *Broker {
-	return &Broker{
-		subscribers: make(map[int]chan domain.CallEvent),
+	return NewBrokerWithPolicy(bufSize, DropPolicy())
+}
+
+// NewBrokerWithPolicy creates a new Broker that handles full subscriber
+// buffers according to policy.
+func NewBrokerWithPolicy(bufSize int, policy Policy, opts ...Option) *Broker {
+	b := &Broker{
+		subscribers: make(map[int]*subscriber),
 		bufSize:     bufSize,
+		policy:      policy,
+		logger:      slog.New(slog.DiscardHandler),
+	}
+	for _, opt := range opts {
+		opt(b)
 	}
+	b.history = NewStore(b.historySize)
+	return b
 }
 
 // Subscribe returns a channel that receives published CallEvents and an unsubscribe function.
 func (b *Broker) Subscribe() (<-chan domain.CallEvent, func()) {
+	return b.SubscribeHistory(nil)
+}
+
+// SubscribeHistory is like Subscribe, but of the history sent ahead of live
+// events only resends the events keep reports true for. A nil keep resends
+// all of it.
+func (b *Broker) SubscribeHistory(keep func(domain.CallEvent) bool) (<-chan domain.CallEvent, func()) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -31,7 +115,18 @@ func (b *Broker) Subscribe() (<-chan domain.CallEvent, func()) {
 	b.nextID++
 
 	ch := make(chan domain.CallEvent, b.bufSize)
-	b.subscribers[id] = ch
+	b.subscribers[id] = &subscriber{ch: ch}
+
+	// Publish is excluded by b.mu, so no event is both replayed and sent.
+	if b.replayHistory {
+		history := b.History()
+		if keep != nil {
+			history = slices.DeleteFunc(history, func(ev domain.CallEvent) bool { return !keep(ev) })
+		}
+		for _, ev := range history[max(len(history)-b.bufSize, 0):] {
+			ch <- ev
+		}
+	}
 
 	unsubscribe := func() {
 		b.mu.Lock()
@@ -46,6 +141,32 @@ func (b *Broker) Subscribe() (<-chan domain.CallEvent, func()) {
 	return ch, unsubscribe
 }
 
+// History returns the events kept by WithHistory or WithRetention, oldest
+// first.
+func (b *Broker) History() []domain.CallEvent {
+	return b.history.All()
+}
+
+// Query returns the newest kept events matching q, oldest first.
+func (b *Broker) Query(q Query) []domain.CallEvent {
+	return b.history.Query(q)
+}
+
+// HistorySize returns how many events the Broker keeps, or 0 if it keeps
+// none.
+func (b *Broker) HistorySize() int {
+	return b.historySize
+}
+
+// Seed adds events to the history without sending them to subscribers, e.g.
+// to restore history saved by a previous process. It does nothing unless
+// the Broker was created WithHistory or WithRetention.
+func (b *Broker) Seed(events []domain.CallEvent) {
+	for _, ev := range events {
+		b.history.Add(ev)
+	}
+}
+
 // SubscriberCount returns the number of active subscribers.
 func (b *Broker) SubscriberCount() int {
 	b.mu.RLock()
@@ -53,17 +174,63 @@ func (b *Broker) SubscriberCount() int {
 	return len(b.subscribers)
 }
 
+// Dropped returns the number of events dropped for the subscriber receiving
+// on ch because its buffer was full. It returns 0 for unknown channels.
+func (b *Broker) Dropped(ch <-chan domain.CallEvent) uint64 {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subscribers {
+		if sub.ch == ch {
+			return sub.dropped.Load()
+		}
+	}
+	return 0
+}
+
+// DroppedTotal returns the number of events dropped across all subscribers,
+// including ones that have since unsubscribed.
+func (b *Broker) DroppedTotal() uint64 {
+	return b.dropped.Load()
+}
+
 // Publish sends an event to all current subscribers.
-// Slow subscribers that have full buffers will have the event dropped.
+// Slow subscribers that have full buffers will have the event dropped, after
+// waiting as long as the broker's Policy allows. Drops are counted and
+// reported by Dropped.
 func (b *Broker) Publish(event domain.CallEvent) {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	for _, ch := range b.subscribers {
+	b.history.Add(event)
+
+	var (
+		timer   *time.Timer // started on the first full buffer
+		expired bool
+	)
+	for id, sub := range b.subscribers {
 		select {
-		case ch <- event:
+		case sub.ch <- event:
+			continue
 		default:
-			// drop event for slow subscriber
 		}
+
+		if b.policy.blockTimeout > 0 && !expired {
+			if timer == nil {
+				timer = time.NewTimer(b.policy.blockTimeout)
+				defer timer.Stop()
+			}
+			select {
+			case sub.ch <- event:
+				continue
+			case <-timer.C:
+				expired = true
+			}
+		}
+
+		sub.dropped.Add(1)
+		b.dropped.Add(1)
+		b.logger.Debug("grpc-scope: event dropped for subscriber",
+			"subscriber", id, "event", event.ID, "method", event.Method)
 	}
 }
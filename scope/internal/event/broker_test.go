@@ -1,7 +1,10 @@
 package event_test
 
 import (
+	"bytes"
 	"fmt"
+	"log/slog"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -125,6 +128,104 @@ func TestBroker_SlowSubscriberDoesNotBlockPublish(t *testing.T) {
 	}
 }
 
+func TestBroker_BlockPolicy_WaitsForSlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	b := event.NewBrokerWithPolicy(1, event.BlockPolicy(time.Second))
+	ch, unsub := b.Subscribe()
+	defer unsub()
+
+	b.Publish(domain.CallEvent{ID: "evt-1"}) // fill the buffer
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		<-ch
+	}()
+	b.Publish(domain.CallEvent{ID: "evt-2"}) // blocks until the reader drains evt-1
+
+	if got := (<-ch).ID; got != "evt-2" {
+		t.Errorf("got ID %q, want %q", got, "evt-2")
+	}
+	if got := b.Dropped(ch); got != 0 {
+		t.Errorf("got %d dropped, want 0", got)
+	}
+}
+
+func TestBroker_BlockPolicy_DropsAfterTimeout(t *testing.T) {
+	t.Parallel()
+
+	const timeout = 30 * time.Millisecond
+	b := event.NewBrokerWithPolicy(1, event.BlockPolicy(timeout))
+	ch1, unsub1 := b.Subscribe()
+	defer unsub1()
+	ch2, unsub2 := b.Subscribe()
+	defer unsub2()
+
+	b.Publish(domain.CallEvent{ID: "evt-1"}) // fill both buffers
+
+	start := time.Now()
+	b.Publish(domain.CallEvent{ID: "evt-2"})
+	elapsed := time.Since(start)
+
+	if elapsed < timeout {
+		t.Errorf("Publish returned after %s, want it to wait at least %s", elapsed, timeout)
+	}
+	if elapsed > 10*timeout {
+		t.Errorf("Publish returned after %s, want the timeout shared across subscribers", elapsed)
+	}
+	if got := b.Dropped(ch1) + b.Dropped(ch2); got != 2 {
+		t.Errorf("got %d dropped, want 2", got)
+	}
+}
+
+func TestBroker_Dropped(t *testing.T) {
+	t.Parallel()
+
+	b := event.NewBroker(1)
+	slow, unsubSlow := b.Subscribe()
+	fast, unsubFast := b.Subscribe()
+	defer unsubFast()
+
+	for i := range 3 {
+		b.Publish(domain.CallEvent{ID: fmt.Sprintf("evt-%d", i)})
+		<-fast
+	}
+
+	if got := b.Dropped(slow); got != 2 {
+		t.Errorf("got %d dropped for slow subscriber, want 2", got)
+	}
+	if got := b.Dropped(fast); got != 0 {
+		t.Errorf("got %d dropped for fast subscriber, want 0", got)
+	}
+
+	unsubSlow()
+	if got := b.DroppedTotal(); got != 2 {
+		t.Errorf("got %d dropped in total, want 2 after unsubscribe", got)
+	}
+}
+
+func TestBroker_WithLogger(t *testing.T) {
+	t.Parallel()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	b := event.NewBrokerWithPolicy(1, event.DropPolicy(), event.WithLogger(logger))
+	_, unsub := b.Subscribe()
+	defer unsub()
+
+	b.Publish(domain.CallEvent{ID: "evt-1", Method: "/test.v1.Test/Get"})
+	if logs.Len() != 0 {
+		t.Errorf("expected no logs before a drop, got:\n%s", logs.String())
+	}
+
+	b.Publish(domain.CallEvent{ID: "evt-2", Method: "/test.v1.Test/Get"})
+	for _, want := range []string{"event dropped for subscriber", "subscriber=0", "event=evt-2"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("expected log to contain %q, got:\n%s", want, logs.String())
+		}
+	}
+}
+
 func TestBroker_ConcurrentPublish(t *testing.T) {
 	t.Parallel()
 
@@ -159,3 +260,71 @@ func TestBroker_ConcurrentPublish(t *testing.T) {
 		t.Errorf("received %d events, want %d", received, n)
 	}
 }
+
+func TestBroker_WithHistory(t *testing.T) {
+	t.Parallel()
+
+	b := event.NewBrokerWithPolicy(2, event.DropPolicy(), event.WithHistory(3))
+	b.Seed([]domain.CallEvent{{ID: "evt-1"}})
+	for i := 2; i <= 4; i++ {
+		b.Publish(domain.CallEvent{ID: fmt.Sprintf("evt-%d", i)})
+	}
+
+	var ids []string
+	for _, ev := range b.History() {
+		ids = append(ids, ev.ID)
+	}
+	if got, want := strings.Join(ids, ","), "evt-2,evt-3,evt-4"; got != want {
+		t.Errorf("got history %s, want %s", got, want)
+	}
+
+	// A new subscriber gets the newest history its buffer holds, then live events.
+	ch, unsub := b.Subscribe()
+	defer unsub()
+	for _, want := range []string{"evt-3", "evt-4"} {
+		if got := (<-ch).ID; got != want {
+			t.Errorf("got replayed event %s, want %s", got, want)
+		}
+	}
+	b.Publish(domain.CallEvent{ID: "evt-5"})
+	if got := (<-ch).ID; got != "evt-5" {
+		t.Errorf("got live event %s, want evt-5", got)
+	}
+}
+
+func TestBroker_SubscribeHistory(t *testing.T) {
+	t.Parallel()
+
+	b := event.NewBrokerWithPolicy(4, event.DropPolicy(), event.WithHistory(4))
+	for i := 1; i <= 4; i++ {
+		b.Publish(domain.CallEvent{ID: fmt.Sprintf("evt-%d", i)})
+	}
+
+	ch, unsub := b.SubscribeHistory(func(ev domain.CallEvent) bool { return ev.ID != "evt-2" })
+	defer unsub()
+	b.Publish(domain.CallEvent{ID: "evt-5"})
+	for _, want := range []string{"evt-1", "evt-3", "evt-4", "evt-5"} {
+		if got := (<-ch).ID; got != want {
+			t.Errorf("got event %s, want %s", got, want)
+		}
+	}
+}
+
+func TestBroker_WithRetention(t *testing.T) {
+	t.Parallel()
+
+	b := event.NewBrokerWithPolicy(2, event.DropPolicy(), event.WithRetention(3))
+	b.Publish(domain.CallEvent{ID: "evt-1"})
+
+	if got := len(b.History()); got != 1 {
+		t.Errorf("got %d events in history, want 1", got)
+	}
+
+	// Retained events are not sent to new subscribers.
+	ch, unsub := b.Subscribe()
+	defer unsub()
+	b.Publish(domain.CallEvent{ID: "evt-2"})
+	if got := (<-ch).ID; got != "evt-2" {
+		t.Errorf("got event %s, want evt-2", got)
+	}
+}
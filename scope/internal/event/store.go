@@ -0,0 +1,100 @@
+package event
+
+import (
+	"path"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/mickamy/grpc-scope/scope/domain"
+)
+
+// Store keeps the most recent events in memory, up to a fixed number, and
+// answers filtered queries over them. It is safe for concurrent use.
+type Store struct {
+	mu     sync.Mutex
+	events []domain.CallEvent // ring of the last size events
+	next   int                // index of the oldest event once the ring is full
+	size   int
+}
+
+// NewStore creates a Store that keeps the last size events. A Store with a
+// non-positive size keeps nothing.
+func NewStore(size int) *Store {
+	return &Store{size: max(size, 0)}
+}
+
+// Add records ev, evicting the oldest event once the Store is full.
+func (s *Store) Add(ev domain.CallEvent) {
+	if s.size == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.events) < s.size {
+		s.events = append(s.events, ev)
+		return
+	}
+	s.events[s.next] = ev
+	s.next = (s.next + 1) % s.size
+}
+
+// All returns every event kept, oldest first.
+func (s *Store) All() []domain.CallEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Concat(s.events[s.next:], s.events[:s.next])
+}
+
+// Query selects events kept by a Store. The zero Query matches every event.
+type Query struct {
+	// Method matches full method names in the syntax of path.Match, e.g.
+	// "/pkg.Service/Method" or "/pkg.Service/*". Empty matches any method.
+	Method string
+	// Status matches events with this status code. StatusUnspecified matches
+	// any status.
+	Status domain.StatusCode
+	// ErrorsOnly matches only events whose status is not OK.
+	ErrorsOnly bool
+	// Since matches only events that started at or after it, unless zero.
+	Since time.Time
+	// Limit caps the result to the newest Limit matches. Zero or less
+	// returns every match.
+	Limit int
+}
+
+// Match reports whether ev satisfies q.
+func (q Query) Match(ev domain.CallEvent) bool {
+	if q.Method != "" {
+		if ok, _ := path.Match(q.Method, ev.Method); !ok {
+			return false
+		}
+	}
+	if q.Status != domain.StatusUnspecified && ev.StatusCode != q.Status {
+		return false
+	}
+	if q.ErrorsOnly && !ev.IsError() {
+		return false
+	}
+	return q.Since.IsZero() || !ev.StartTime.Before(q.Since)
+}
+
+// Query returns the newest events matching q, oldest first.
+func (s *Store) Query(q Query) []domain.CallEvent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var out []domain.CallEvent
+	// Walk from the newest event back, so a limit stops the scan early.
+	for i := len(s.events) - 1; i >= 0; i-- {
+		if q.Limit > 0 && len(out) == q.Limit {
+			break
+		}
+		ev := s.events[(s.next+i)%len(s.events)]
+		if q.Match(ev) {
+			out = append(out, ev)
+		}
+	}
+	slices.Reverse(out)
+	return out
+}
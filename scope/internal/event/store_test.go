@@ -0,0 +1,68 @@
+package event_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mickamy/grpc-scope/scope/domain"
+	"github.com/mickamy/grpc-scope/scope/internal/event"
+)
+
+func TestStore_Query(t *testing.T) {
+	t.Parallel()
+
+	base := time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)
+	s := event.NewStore(5)
+	for i, ev := range []domain.CallEvent{
+		{Method: "/users.v1.Users/Get", StatusCode: domain.StatusOK}, // evicted
+		{Method: "/users.v1.Users/Get", StatusCode: domain.StatusNotFound},
+		{Method: "/orders.v1.Orders/List", StatusCode: domain.StatusOK},
+		{Method: "/users.v1.Users/Get", StatusCode: domain.StatusOK},
+		{Method: "/users.v1.Users/List", StatusCode: domain.StatusInternal},
+		{Method: "/users.v1.Users/Get", StatusCode: domain.StatusNotFound},
+	} {
+		ev.ID = fmt.Sprintf("evt-%d", i+1)
+		ev.StartTime = base.Add(time.Duration(i) * time.Second)
+		s.Add(ev)
+	}
+
+	tests := []struct {
+		name  string
+		query event.Query
+		want  string
+	}{
+		{name: "all", want: "evt-2,evt-3,evt-4,evt-5,evt-6"},
+		{name: "method", query: event.Query{Method: "/users.v1.Users/Get"}, want: "evt-2,evt-4,evt-6"},
+		{name: "method pattern", query: event.Query{Method: "/users.v1.Users/*"}, want: "evt-2,evt-4,evt-5,evt-6"},
+		{name: "status", query: event.Query{Status: domain.StatusNotFound}, want: "evt-2,evt-6"},
+		{name: "errors only", query: event.Query{ErrorsOnly: true}, want: "evt-2,evt-5,evt-6"},
+		{name: "since", query: event.Query{Since: base.Add(4 * time.Second)}, want: "evt-5,evt-6"},
+		{name: "limit keeps newest", query: event.Query{ErrorsOnly: true, Limit: 2}, want: "evt-5,evt-6"},
+		{name: "no match", query: event.Query{Method: "/billing.v1.Billing/*"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var ids []string
+			for _, ev := range s.Query(tt.query) {
+				ids = append(ids, ev.ID)
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStore_ZeroSizeKeepsNothing(t *testing.T) {
+	t.Parallel()
+
+	s := event.NewStore(0)
+	s.Add(domain.CallEvent{ID: "evt-1"})
+	if got := s.All(); len(got) != 0 {
+		t.Errorf("got %d events, want none", len(got))
+	}
+}
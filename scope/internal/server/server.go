@@ -1,12 +1,23 @@
 package server
 
 import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
 	"net"
+	"net/http"
+	"path"
+	"slices"
+	"time"
 
 	"github.com/mickamy/grpc-scope/scope/domain"
 	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
 	"github.com/mickamy/grpc-scope/scope/internal/event"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -15,17 +26,66 @@ import (
 type Server struct {
 	grpcServer *grpc.Server
 	broker     *event.Broker
+	svc        *scopeService
+}
+
+// Option configures a Server.
+type Option func(*scopeService)
+
+// WithAuthToken makes Watch reject streams whose incoming metadata lacks a
+// matching x-scope-token. An empty token leaves the server open.
+func WithAuthToken(token string) Option {
+	return func(s *scopeService) {
+		s.token = token
+	}
+}
+
+// WithLogger makes the server log Watch streams opening and closing at debug
+// level, and rejected ones at warn level.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *scopeService) {
+		s.logger = logger
+	}
+}
+
+// WithHeartbeat makes Watch send a WatchResponse without events once a
+// stream has been idle for interval, so clients can tell a quiet stream from
+// a stalled one. A non-positive interval disables heartbeats.
+func WithHeartbeat(interval time.Duration) Option {
+	return func(s *scopeService) {
+		s.heartbeat = interval
+	}
+}
+
+// WithAppTarget makes ServerInfo report the application address target
+// returns when called, or none if it returns "".
+func WithAppTarget(target func() string) Option {
+	return func(s *scopeService) {
+		s.appTarget = target
+	}
+}
+
+// WithMaxShownElements makes ServerInfo report n as the number of array
+// elements monitors show before eliding the rest.
+func WithMaxShownElements(n int) Option {
+	return func(s *scopeService) {
+		s.maxShown = n
+	}
 }
 
 // New creates a new Server backed by the given Broker.
-func New(broker *event.Broker) *Server {
+func New(broker *event.Broker, opts ...Option) *Server {
 	gs := grpc.NewServer()
-	svc := &scopeService{broker: broker}
+	svc := &scopeService{broker: broker, logger: slog.New(slog.DiscardHandler)}
+	for _, opt := range opts {
+		opt(svc)
+	}
 	scopev1.RegisterScopeServiceServer(gs, svc)
 
 	return &Server{
 		grpcServer: gs,
 		broker:     broker,
+		svc:        svc,
 	}
 }
 
@@ -34,52 +94,329 @@ func (s *Server) Serve(lis net.Listener) error {
 	return s.grpcServer.Serve(lis)
 }
 
+// ServicePath is the URL path prefix of every ScopeService method.
+const ServicePath = "/scope.v1.ScopeService/"
+
+// Handler returns the gRPC server as an http.Handler. It serves gRPC over
+// HTTP/2 only; see grpc.Server.ServeHTTP.
+func (s *Server) Handler() http.Handler {
+	return s.grpcServer
+}
+
 // GracefulStop gracefully stops the server.
 func (s *Server) GracefulStop() {
 	s.grpcServer.GracefulStop()
 }
 
+// Stop stops the server, cancelling Watch streams still in progress.
+func (s *Server) Stop() {
+	s.grpcServer.Stop()
+}
+
 type scopeService struct {
 	scopev1.UnimplementedScopeServiceServer
-	broker *event.Broker
+	broker    *event.Broker
+	token     string        // required x-scope-token; empty disables the check
+	heartbeat time.Duration // idle time before a heartbeat; 0 disables them
+	appTarget func() string // reported by ServerInfo; nil reports none
+	maxShown  int           // array elements monitors show; 0 shows all
+	logger    *slog.Logger
+}
+
+// authorize checks the shared token presented in ctx's incoming metadata.
+func (s *scopeService) authorize(ctx context.Context) error {
+	if s.token == "" {
+		return nil
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, got := range md.Get(domain.HeaderScopeToken) {
+		if subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) == 1 {
+			return nil
+		}
+	}
+	return status.Error(codes.Unauthenticated, "missing or invalid "+domain.HeaderScopeToken)
 }
 
-func (s *scopeService) Watch(_ *scopev1.WatchRequest, stream grpc.ServerStreamingServer[scopev1.WatchResponse]) error {
-	ch, unsub := s.broker.Subscribe()
+// peerAddr returns the address of the client behind ctx, or "" if unknown.
+func peerAddr(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		return p.Addr.String()
+	}
+	return ""
+}
+
+func (s *scopeService) ServerInfo(ctx context.Context, _ *scopev1.ServerInfoRequest) (*scopev1.ServerInfoResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	resp := &scopev1.ServerInfoResponse{MaxShownElements: int32(max(s.maxShown, 0))}
+	if s.heartbeat > 0 {
+		resp.HeartbeatInterval = durationpb.New(s.heartbeat)
+	}
+	if s.appTarget != nil {
+		resp.AppTarget = s.appTarget()
+	}
+	return resp, nil
+}
+
+// GetHistory returns the kept events matching req, oldest first. When they
+// would not all fit in one response, the oldest are left out.
+func (s *scopeService) GetHistory(ctx context.Context, req *scopev1.GetHistoryRequest) (*scopev1.GetHistoryResponse, error) {
+	if err := s.authorize(ctx); err != nil {
+		return nil, err
+	}
+	if s.broker.HistorySize() == 0 {
+		return nil, status.Error(codes.FailedPrecondition, "grpc-scope: history is disabled; enable it with WithHistorySize")
+	}
+	if _, err := path.Match(req.GetMethod(), ""); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "grpc-scope: method pattern %q: %v", req.GetMethod(), err)
+	}
+
+	q := event.Query{
+		Method:     req.GetMethod(),
+		Status:     domain.StatusCode(req.GetStatusCode()),
+		ErrorsOnly: req.GetErrorsOnly(),
+		Limit:      int(req.GetLimit()),
+	}
+	if since := req.GetSince(); since != nil {
+		q.Since = since.AsTime()
+	}
+	events := s.broker.Query(q)
+
+	resp := &scopev1.GetHistoryResponse{}
+	size := 0
+	for i := len(events) - 1; i >= 0; i-- {
+		pe := fitEvent(domainToProto(events[i]), maxEventSize)
+		n := batchedSize(pe)
+		if size+n > maxEventSize {
+			resp.Truncated = true
+			break
+		}
+		size += n
+		resp.Events = append(resp.Events, pe)
+	}
+	slices.Reverse(resp.Events)
+	return resp, nil
+}
+
+// defaultBatchInterval bounds how long a batched event waits when the
+// WatchRequest sets a batch size but no interval.
+const defaultBatchInterval = 100 * time.Millisecond
+
+func (s *scopeService) Watch(req *scopev1.WatchRequest, stream grpc.ServerStreamingServer[scopev1.WatchResponse]) error {
+	if err := s.authorize(stream.Context()); err != nil {
+		s.logger.Warn("grpc-scope: watch rejected", "peer", peerAddr(stream.Context()), "error", err)
+		return err
+	}
+
+	ch, unsub := s.broker.SubscribeHistory(keptSince(req.GetHistory()))
 	defer unsub()
 
+	s.logger.Debug("grpc-scope: watch started", "peer", peerAddr(stream.Context()), "batch_size", req.GetBatchSize())
+	defer s.logger.Debug("grpc-scope: watch ended", "peer", peerAddr(stream.Context()))
+
+	if size := int(req.GetBatchSize()); size > 1 {
+		interval := req.GetBatchInterval().AsDuration()
+		if interval <= 0 {
+			interval = defaultBatchInterval
+		}
+		return s.watchBatched(stream, ch, size, interval)
+	}
+
 	ctx := stream.Context()
+	idle := newIdleTimer(s.heartbeat)
+	defer idle.stop()
 	for {
 		select {
 		case <-ctx.Done():
 			return ctx.Err()
+		case <-idle.C():
+			if err := s.sendHeartbeat(stream, ch); err != nil {
+				return err
+			}
 		case ev, ok := <-ch:
 			if !ok {
 				return nil
 			}
 			if err := stream.Send(&scopev1.WatchResponse{
-				Event: domainToProto(ev),
+				Event:   fitEvent(domainToProto(ev), maxEventSize),
+				Dropped: s.broker.Dropped(ch),
 			}); err != nil {
 				return err
 			}
 		}
+		idle.reset()
+	}
+}
+
+// keptSince returns which kept events a Watch stream asking for history
+// resends: those that ended within window before now, by this server's
+// clock. A nil window resends all of them; a zero one resends none.
+func keptSince(window *durationpb.Duration) func(domain.CallEvent) bool {
+	if window == nil {
+		return nil
+	}
+	d := window.AsDuration()
+	since := time.Now().Add(-d)
+	return func(ev domain.CallEvent) bool {
+		return d > 0 && !ev.StartTime.Add(ev.Duration).Before(since)
+	}
+}
+
+// watchBatched groups events into a single WatchResponse, flushing when the
+// batch reaches size, when its oldest event has waited for interval, or
+// before the next event would push it past maxMessageSize.
+func (s *scopeService) watchBatched(stream grpc.ServerStreamingServer[scopev1.WatchResponse], ch <-chan domain.CallEvent, size int, interval time.Duration) error {
+	ctx := stream.Context()
+	batch := make([]*scopev1.CallEvent, 0, size)
+	batchBytes := 0
+	timer := time.NewTimer(interval)
+	timer.Stop()
+	defer timer.Stop()
+	idle := newIdleTimer(s.heartbeat)
+	defer idle.stop()
+
+	flush := func() error {
+		timer.Stop()
+		if len(batch) == 0 {
+			return nil
+		}
+		err := stream.Send(&scopev1.WatchResponse{Events: batch, Dropped: s.broker.Dropped(ch)})
+		batch = make([]*scopev1.CallEvent, 0, size)
+		batchBytes = 0
+		idle.reset()
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-idle.C():
+			if err := s.sendHeartbeat(stream, ch); err != nil {
+				return err
+			}
+			idle.reset()
+		case <-timer.C:
+			if err := flush(); err != nil {
+				return err
+			}
+		case ev, ok := <-ch:
+			if !ok {
+				return flush()
+			}
+			pe := fitEvent(domainToProto(ev), maxEventSize)
+			n := batchedSize(pe)
+			if batchBytes+n > maxEventSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			batch = append(batch, pe)
+			batchBytes += n
+			if len(batch) == 1 {
+				timer.Reset(interval)
+			}
+			if len(batch) >= size {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+// sendHeartbeat sends a WatchResponse carrying only the dropped count.
+func (s *scopeService) sendHeartbeat(stream grpc.ServerStreamingServer[scopev1.WatchResponse], ch <-chan domain.CallEvent) error {
+	return stream.Send(&scopev1.WatchResponse{Dropped: s.broker.Dropped(ch)})
+}
+
+// idleTimer fires once a stream has sent nothing for its interval. A zero
+// idleTimer never fires.
+type idleTimer struct {
+	interval time.Duration
+	timer    *time.Timer
+}
+
+func newIdleTimer(interval time.Duration) *idleTimer {
+	if interval <= 0 {
+		return &idleTimer{}
+	}
+	return &idleTimer{interval: interval, timer: time.NewTimer(interval)}
+}
+
+// C returns the channel the timer fires on, or nil if it is disabled.
+func (t *idleTimer) C() <-chan time.Time {
+	if t.timer == nil {
+		return nil
+	}
+	return t.timer.C
+}
+
+// reset restarts the interval after a send.
+func (t *idleTimer) reset() {
+	if t.timer != nil {
+		t.timer.Reset(t.interval)
+	}
+}
+
+func (t *idleTimer) stop() {
+	if t.timer != nil {
+		t.timer.Stop()
 	}
 }
 
 func domainToProto(e domain.CallEvent) *scopev1.CallEvent {
 	return &scopev1.CallEvent{
-		Id:               e.ID,
-		Method:           e.Method,
-		StartTime:        timestamppb.New(e.StartTime),
-		Duration:         durationpb.New(e.Duration),
-		StatusCode:       int32(e.StatusCode),
-		StatusMessage:    e.StatusMessage,
-		RequestMetadata:  metadataToProto(e.RequestMetadata),
-		ResponseHeaders:  metadataToProto(e.ResponseHeaders),
-		ResponseTrailers: metadataToProto(e.ResponseTrailers),
-		RequestPayload:   e.RequestPayload,
-		ResponsePayload:  e.ResponsePayload,
+		Id:                   e.ID,
+		Method:               e.Method,
+		StartTime:            timestamppb.New(e.StartTime),
+		Duration:             durationpb.New(e.Duration),
+		StatusCode:           int32(e.StatusCode),
+		StatusMessage:        e.StatusMessage,
+		RequestMetadata:      metadataToProto(e.RequestMetadata),
+		ResponseHeaders:      metadataToProto(e.ResponseHeaders),
+		ResponseTrailers:     metadataToProto(e.ResponseTrailers),
+		RequestPayload:       e.RequestPayload,
+		ResponsePayload:      e.ResponsePayload,
+		Attempt:              int32(e.Attempt),
+		ContentEncoding:      e.ContentEncoding,
+		Deadline:             deadlineToProto(e.Deadline),
+		Direction:            scopev1.Direction(e.Direction),
+		HttpPath:             e.HTTPPath,
+		ResponseContentType:  e.ResponseContentType,
+		Seq:                  e.Seq,
+		Goroutines:           int32(e.Goroutines),
+		TotalDuration:        totalDurationToProto(e.TotalDuration),
+		DeadlineSource:       e.DeadlineSource,
+		RequestContentLength: e.RequestContentLength,
+		RequestBodySize:      e.RequestBodySize,
+		SizeMismatch:         e.SizeMismatch,
+		NilResponse:          e.NilResponse,
+		StatusDetails:        e.StatusDetails,
+		Protocol:             e.Protocol,
+		PeerIdentity:         e.PeerIdentity,
+		SchemaWarnings:       e.SchemaWarnings,
+		Streaming:            e.Streaming,
+		SentCount:            e.SentCount,
+		RecvCount:            e.RecvCount,
+		RequestBytesRaw:      e.RequestBytesRaw,
+		Labels:               e.Labels,
+	}
+}
+
+func totalDurationToProto(d time.Duration) *durationpb.Duration {
+	if d == 0 {
+		return nil
+	}
+	return durationpb.New(d)
+}
+
+func deadlineToProto(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
 	}
+	return timestamppb.New(t)
 }
 
 func metadataToProto(md domain.Metadata) map[string]*scopev1.MetadataValues {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,14 +13,25 @@ import (
 	"github.com/mickamy/grpc-scope/scope/internal/event"
 	"github.com/mickamy/grpc-scope/scope/internal/server"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
-func startServer(t *testing.T) (scopev1.ScopeServiceClient, *event.Broker) {
+func startServer(t *testing.T, opts ...server.Option) (scopev1.ScopeServiceClient, *event.Broker) {
 	t.Helper()
 
 	broker := event.NewBroker(100)
-	srv := server.New(broker)
+	return startServerWithBroker(t, broker, opts...), broker
+}
+
+func startServerWithBroker(t *testing.T, broker *event.Broker, opts ...server.Option) scopev1.ScopeServiceClient {
+	t.Helper()
+
+	srv := server.New(broker, opts...)
 
 	lis, err := net.Listen("tcp", "localhost:0")
 	if err != nil {
@@ -42,7 +54,7 @@ func startServer(t *testing.T) (scopev1.ScopeServiceClient, *event.Broker) {
 	}
 	t.Cleanup(func() { _ = conn.Close() })
 
-	return scopev1.NewScopeServiceClient(conn), broker
+	return scopev1.NewScopeServiceClient(conn)
 }
 
 // waitForSubscriber polls the broker until at least wantCount subscribers are registered.
@@ -120,6 +132,50 @@ func TestWatch_ReceivesPublishedEvent(t *testing.T) {
 	}
 }
 
+func TestWatch_History(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		history *durationpb.Duration
+		want    []string
+	}{
+		{name: "unset resends all history", want: []string{"old", "recent", "live"}},
+		{name: "zero resends none", history: durationpb.New(0), want: []string{"live"}},
+		{name: "window", history: durationpb.New(time.Minute), want: []string{"recent", "live"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			broker := event.NewBrokerWithPolicy(100, event.DropPolicy(), event.WithHistory(10))
+			broker.Publish(domain.CallEvent{ID: "old", StartTime: time.Now().Add(-time.Hour)})
+			broker.Publish(domain.CallEvent{ID: "recent", StartTime: time.Now().Add(-10 * time.Second)})
+			client := startServerWithBroker(t, broker)
+
+			stream, err := client.Watch(ctx, &scopev1.WatchRequest{History: tt.history})
+			if err != nil {
+				t.Fatal(err)
+			}
+			waitForSubscriber(t, ctx, broker, 1)
+			broker.Publish(domain.CallEvent{ID: "live", StartTime: time.Now()})
+
+			var got []string
+			for len(got) == 0 || got[len(got)-1] != "live" {
+				resp, err := stream.Recv()
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, resp.GetEvent().GetId())
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("got events %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 func TestWatch_MultipleEvents(t *testing.T) {
 	t.Parallel()
 
@@ -152,6 +208,158 @@ func TestWatch_MultipleEvents(t *testing.T) {
 	}
 }
 
+func TestWatch_Batched(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		req       *scopev1.WatchRequest
+		published int
+		want      []int // events per response
+	}{
+		{
+			name:      "flushes full batches",
+			req:       &scopev1.WatchRequest{BatchSize: 5, BatchInterval: durationpb.New(time.Minute)},
+			published: 10,
+			want:      []int{5, 5},
+		},
+		{
+			name:      "flushes partial batch on interval",
+			req:       &scopev1.WatchRequest{BatchSize: 10, BatchInterval: durationpb.New(20 * time.Millisecond)},
+			published: 3,
+			want:      []int{3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			client, broker := startServer(t)
+
+			stream, err := client.Watch(ctx, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			waitForSubscriber(t, ctx, broker, 1)
+
+			for i := range tt.published {
+				broker.Publish(domain.CallEvent{
+					ID:         fmt.Sprintf("evt-%d", i),
+					Method:     "/test.v1.TestService/List",
+					StatusCode: domain.StatusOK,
+				})
+			}
+
+			next := 0
+			for _, want := range tt.want {
+				resp, err := stream.Recv()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp.GetEvent() != nil {
+					t.Error("expected batched response to leave event unset")
+				}
+				events := resp.GetEvents()
+				if len(events) != want {
+					t.Fatalf("got batch of %d events, want %d", len(events), want)
+				}
+				for _, ev := range events {
+					if id := fmt.Sprintf("evt-%d", next); ev.GetId() != id {
+						t.Errorf("got ID %q, want %q", ev.GetId(), id)
+					}
+					next++
+				}
+			}
+		})
+	}
+}
+
+func TestWatch_Heartbeat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		req  *scopev1.WatchRequest
+	}{
+		{name: "unbatched", req: &scopev1.WatchRequest{}},
+		{name: "batched", req: &scopev1.WatchRequest{BatchSize: 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			client, broker := startServer(t, server.WithHeartbeat(20*time.Millisecond))
+
+			stream, err := client.Watch(ctx, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resp, err := stream.Recv()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.GetEvent() != nil || len(resp.GetEvents()) > 0 {
+				t.Fatalf("expected an empty heartbeat on an idle stream, got %v", resp)
+			}
+
+			broker.Publish(domain.CallEvent{ID: "evt-1", StatusCode: domain.StatusOK})
+			for {
+				resp, err := stream.Recv()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if resp.GetEvent().GetId() == "evt-1" || len(resp.GetEvents()) == 1 {
+					break
+				}
+			}
+		})
+	}
+}
+
+func TestWatch_AuthToken(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		md       metadata.MD
+		wantCode codes.Code
+	}{
+		{name: "missing token", md: nil, wantCode: codes.Unauthenticated},
+		{name: "wrong token", md: metadata.Pairs(domain.HeaderScopeToken, "nope"), wantCode: codes.Unauthenticated},
+		{name: "matching token", md: metadata.Pairs(domain.HeaderScopeToken, "secret"), wantCode: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			client, broker := startServer(t, server.WithAuthToken("secret"))
+
+			stream, err := client.Watch(metadata.NewOutgoingContext(ctx, tt.md), &scopev1.WatchRequest{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.wantCode == codes.OK {
+				waitForSubscriber(t, ctx, broker, 1)
+				broker.Publish(domain.CallEvent{ID: "evt-1", StatusCode: domain.StatusOK})
+			}
+
+			resp, err := stream.Recv()
+			if got := status.Code(err); got != tt.wantCode {
+				t.Fatalf("got code %s, want %s (err: %v)", got, tt.wantCode, err)
+			}
+			if tt.wantCode == codes.OK && resp.GetEvent().GetId() != "evt-1" {
+				t.Errorf("got event %q, want %q", resp.GetEvent().GetId(), "evt-1")
+			}
+		})
+	}
+}
+
 func TestWatch_ClientCancelStopsStream(t *testing.T) {
 	t.Parallel()
 
@@ -177,3 +385,188 @@ func TestWatch_ClientCancelStopsStream(t *testing.T) {
 		t.Fatal("expected error after cancel, got nil")
 	}
 }
+
+func TestWatch_OversizedEvent(t *testing.T) {
+	t.Parallel()
+
+	large := strings.Repeat("x", 5<<20)
+
+	tests := []struct {
+		name string
+		req  *scopev1.WatchRequest
+	}{
+		{name: "unbatched", req: &scopev1.WatchRequest{}},
+		{name: "batched", req: &scopev1.WatchRequest{BatchSize: 3, BatchInterval: durationpb.New(time.Minute)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := t.Context()
+			client, broker := startServer(t)
+
+			stream, err := client.Watch(ctx, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			waitForSubscriber(t, ctx, broker, 1)
+
+			broker.Publish(domain.CallEvent{ID: "large", RequestPayload: large, RequestBytesRaw: []byte(large), StatusCode: domain.StatusOK})
+			broker.Publish(domain.CallEvent{ID: "half", ResponsePayload: large[:3<<20], StatusCode: domain.StatusOK})
+			broker.Publish(domain.CallEvent{ID: "small", RequestPayload: `{"id":1}`, StatusCode: domain.StatusOK})
+
+			var got []*scopev1.CallEvent
+			for len(got) < 3 {
+				resp, err := stream.Recv()
+				if err != nil {
+					t.Fatalf("stream broke after %d events: %v", len(got), err)
+				}
+				if ev := resp.GetEvent(); ev != nil {
+					got = append(got, ev)
+				}
+				got = append(got, resp.GetEvents()...)
+			}
+
+			if p := got[0].GetRequestPayload(); !strings.HasPrefix(p, "<payload omitted: 5242880 bytes") {
+				t.Errorf("large request payload = %.60q, want placeholder", p)
+			}
+			if raw := got[0].GetRequestBytesRaw(); raw != nil {
+				t.Errorf("large raw request bytes = %d bytes, want none", len(raw))
+			}
+			if p := got[1].GetResponsePayload(); len(p) != 3<<20 {
+				t.Errorf("half response payload = %d bytes, want %d kept", len(p), 3<<20)
+			}
+			if p := got[2].GetRequestPayload(); p != `{"id":1}` {
+				t.Errorf("small request payload = %q", p)
+			}
+		})
+	}
+}
+
+func TestServerInfo(t *testing.T) {
+	t.Parallel()
+
+	client, _ := startServer(t,
+		server.WithAppTarget(func() string { return "localhost:50051" }),
+		server.WithMaxShownElements(3),
+		server.WithHeartbeat(30*time.Second),
+	)
+
+	info, err := client.ServerInfo(t.Context(), &scopev1.ServerInfoRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.GetAppTarget(); got != "localhost:50051" {
+		t.Errorf("got app target %q, want localhost:50051", got)
+	}
+	if got := info.GetMaxShownElements(); got != 3 {
+		t.Errorf("got max shown elements %d, want 3", got)
+	}
+	if got := info.GetHeartbeatInterval().AsDuration(); got != 30*time.Second {
+		t.Errorf("got heartbeat interval %s, want 30s", got)
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	t.Parallel()
+
+	broker := event.NewBrokerWithPolicy(100, event.DropPolicy(), event.WithRetention(10))
+	client := startServerWithBroker(t, broker)
+
+	base := time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)
+	for i, code := range []domain.StatusCode{domain.StatusOK, domain.StatusNotFound, domain.StatusOK, domain.StatusInternal, domain.StatusNotFound} {
+		broker.Publish(domain.CallEvent{
+			ID:         fmt.Sprintf("evt-%d", i+1),
+			Method:     "/test.v1.TestService/Get",
+			StartTime:  base.Add(time.Duration(i) * time.Second),
+			StatusCode: code,
+		})
+	}
+	broker.Publish(domain.CallEvent{ID: "evt-6", Method: "/test.v1.TestService/List", StartTime: base.Add(5 * time.Second), StatusCode: domain.StatusInternal})
+
+	tests := []struct {
+		name string
+		req  *scopev1.GetHistoryRequest
+		want string
+	}{
+		{name: "all", req: &scopev1.GetHistoryRequest{}, want: "evt-1,evt-2,evt-3,evt-4,evt-5,evt-6"},
+		{
+			name: "last errors for a method",
+			req:  &scopev1.GetHistoryRequest{Method: "/test.v1.TestService/Get", ErrorsOnly: true, Limit: 2},
+			want: "evt-4,evt-5",
+		},
+		{name: "status", req: &scopev1.GetHistoryRequest{StatusCode: int32(domain.StatusNotFound)}, want: "evt-2,evt-5"},
+		{name: "since", req: &scopev1.GetHistoryRequest{Since: timestamppb.New(base.Add(4 * time.Second))}, want: "evt-5,evt-6"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := client.GetHistory(t.Context(), tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, ev := range resp.GetEvents() {
+				ids = append(ids, ev.GetId())
+			}
+			if got := strings.Join(ids, ","); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+			if resp.GetTruncated() {
+				t.Error("expected a complete response")
+			}
+		})
+	}
+}
+
+func TestGetHistory_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		broker *event.Broker
+		req    *scopev1.GetHistoryRequest
+		want   codes.Code
+	}{
+		{name: "history disabled", broker: event.NewBroker(10), req: &scopev1.GetHistoryRequest{}, want: codes.FailedPrecondition},
+		{
+			name:   "bad method pattern",
+			broker: event.NewBrokerWithPolicy(10, event.DropPolicy(), event.WithRetention(10)),
+			req:    &scopev1.GetHistoryRequest{Method: "/test.v1.TestService/["},
+			want:   codes.InvalidArgument,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			client := startServerWithBroker(t, tt.broker)
+			_, err := client.GetHistory(t.Context(), tt.req)
+			if got := status.Code(err); got != tt.want {
+				t.Errorf("got code %s, want %s (err: %v)", got, tt.want, err)
+			}
+		})
+	}
+}
+
+func TestGetHistory_TruncatesToMessageSize(t *testing.T) {
+	t.Parallel()
+
+	broker := event.NewBrokerWithPolicy(10, event.DropPolicy(), event.WithRetention(10))
+	client := startServerWithBroker(t, broker)
+
+	payload := strings.Repeat("x", 3<<20)
+	for i := range 3 {
+		broker.Publish(domain.CallEvent{ID: fmt.Sprintf("evt-%d", i+1), RequestPayload: payload, StatusCode: domain.StatusOK})
+	}
+
+	resp, err := client.GetHistory(t.Context(), &scopev1.GetHistoryRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.GetEvents()) != 1 || resp.GetEvents()[0].GetId() != "evt-3" || !resp.GetTruncated() {
+		t.Errorf("got %d events (truncated %v), want only the newest and truncated", len(resp.GetEvents()), resp.GetTruncated())
+	}
+}
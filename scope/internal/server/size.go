@@ -0,0 +1,62 @@
+package server
+
+import (
+	"fmt"
+
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// maxMessageSize is the largest WatchResponse Watch sends. It matches
+// gRPC's default receive limit, so a monitor that keeps the default can read
+// every response.
+const maxMessageSize = 4 << 20
+
+// maxEventSize leaves room in a WatchResponse for the event's own framing
+// and the dropped count.
+const maxEventSize = maxMessageSize - 64
+
+// fitEvent returns ev trimmed to at most limit bytes when encoded. It first
+// drops the raw request bytes, then replaces payloads with a placeholder,
+// largest first, and as a last resort clears status details and metadata.
+// ev is returned unchanged when it already fits.
+func fitEvent(ev *scopev1.CallEvent, limit int) *scopev1.CallEvent {
+	size := proto.Size(ev)
+	if size <= limit {
+		return ev
+	}
+
+	ev = proto.CloneOf(ev)
+	ev.RequestBytesRaw = nil
+	if proto.Size(ev) <= limit {
+		return ev
+	}
+
+	payloads := []*string{&ev.RequestPayload, &ev.ResponsePayload}
+	if len(ev.ResponsePayload) > len(ev.RequestPayload) {
+		payloads[0], payloads[1] = payloads[1], payloads[0]
+	}
+	for _, p := range payloads {
+		*p = oversizedPayload(len(*p), limit)
+		if proto.Size(ev) <= limit {
+			return ev
+		}
+	}
+
+	ev.StatusDetails = nil
+	ev.RequestMetadata = nil
+	ev.ResponseHeaders = nil
+	ev.ResponseTrailers = nil
+	return ev
+}
+
+func oversizedPayload(size, limit int) string {
+	return fmt.Sprintf("<payload omitted: %d bytes exceeds the %d-byte watch message limit>", size, limit)
+}
+
+// batchedSize returns how many bytes ev adds to a WatchResponse's events.
+func batchedSize(ev *scopev1.CallEvent) int {
+	// One byte of field tag and up to four of length prefix for messages
+	// under maxMessageSize.
+	return proto.Size(ev) + 5
+}
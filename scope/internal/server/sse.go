@@ -0,0 +1,98 @@
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/mickamy/grpc-scope/scope/domain"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// EventsPath is where EventsHandler serves its stream.
+const EventsPath = "/events"
+
+// EventsHandler returns an http.Handler that streams captured events to GET
+// EventsPath as server-sent events, one protojson-encoded CallEvent per
+// message with its Seq as the event ID, so browsers and curl can follow the
+// feed without speaking gRPC. Idle streams get a comment line as a
+// heartbeat. With an auth token configured, requests must present it in the
+// x-scope-token header or, for EventSource, the token query parameter.
+func (s *Server) EventsHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+EventsPath, s.svc.serveEvents)
+	return mux
+}
+
+func (s *scopeService) serveEvents(w http.ResponseWriter, r *http.Request) {
+	if !s.authorizeHTTP(r) {
+		s.logger.Warn("grpc-scope: event stream rejected", "peer", r.RemoteAddr)
+		http.Error(w, "missing or invalid "+domain.HeaderScopeToken, http.StatusUnauthorized)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+
+	ch, unsub := s.broker.Subscribe()
+	defer unsub()
+
+	s.logger.Debug("grpc-scope: event stream started", "peer", r.RemoteAddr)
+	defer s.logger.Debug("grpc-scope: event stream ended", "peer", r.RemoteAddr)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ctx := r.Context()
+	idle := newIdleTimer(s.heartbeat)
+	defer idle.stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-idle.C():
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case ev, ok := <-ch:
+			if !ok {
+				return
+			}
+			data, err := protojson.Marshal(domainToProto(ev))
+			if err != nil {
+				s.logger.Warn("grpc-scope: event not streamed", "event", ev.ID, "error", err)
+				continue
+			}
+			// protojson output is deliberately unstable; compact it, which
+			// also keeps the event on the single data line SSE requires.
+			var line bytes.Buffer
+			if err := json.Compact(&line, data); err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\ndata: %s\n\n", ev.Seq, line.Bytes()); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+		idle.reset()
+	}
+}
+
+// authorizeHTTP checks the shared token presented by an HTTP request.
+func (s *scopeService) authorizeHTTP(r *http.Request) bool {
+	if s.token == "" {
+		return true
+	}
+	for _, got := range []string{r.Header.Get(domain.HeaderScopeToken), r.URL.Query().Get("token")} {
+		if got != "" && subtle.ConstantTimeCompare([]byte(got), []byte(s.token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package scope
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mickamy/grpc-scope/scope/domain"
+)
+
+// loadHistory reads the events persisted at path, one JSON object per line,
+// and returns the newest limit of them. If the file holds more, it is
+// rewritten with only those, so appending on every shutdown keeps it
+// bounded. A missing file yields no events.
+func loadHistory(path string, limit int) ([]domain.CallEvent, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("grpc-scope: open history: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	var events []domain.CallEvent
+	total := 0
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if line = bytes.TrimSpace(line); len(line) > 0 {
+			var ev domain.CallEvent
+			if err := json.Unmarshal(line, &ev); err != nil {
+				return nil, fmt.Errorf("grpc-scope: decode history line %d: %w", total+1, err)
+			}
+			events = append(events, ev)
+			total++
+			if len(events) > limit {
+				events = events[1:]
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("grpc-scope: read history: %w", err)
+		}
+	}
+
+	if total > len(events) {
+		if err := rewriteHistory(path, events); err != nil {
+			return nil, err
+		}
+	}
+	return events, nil
+}
+
+// rewriteHistory replaces the file at path with events, atomically.
+func rewriteHistory(path string, events []domain.CallEvent) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("grpc-scope: compact history: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := writeHistory(tmp, events); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("grpc-scope: compact history: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("grpc-scope: compact history: %w", err)
+	}
+	return nil
+}
+
+// appendHistory appends events to the file at path, creating it if needed.
+func appendHistory(path string, events []domain.CallEvent) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("grpc-scope: open history: %w", err)
+	}
+	if err := writeHistory(f, events); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("grpc-scope: write history: %w", err)
+	}
+	return nil
+}
+
+func writeHistory(w io.Writer, events []domain.CallEvent) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	for _, ev := range events {
+		if err := enc.Encode(ev); err != nil {
+			return fmt.Errorf("grpc-scope: write history: %w", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("grpc-scope: write history: %w", err)
+	}
+	return nil
+}
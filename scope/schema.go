@@ -0,0 +1,124 @@
+package scope
+
+import (
+	"fmt"
+	"strconv"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// SchemaWarnings checks a decoded message against its own descriptor and
+// describes what would not round-trip cleanly: fields unknown to the schema,
+// enum numbers it does not define, deprecated fields that are set, and
+// missing proto2 required fields. Each warning names the field by its path,
+// e.g. "user.roles[2]". It returns nil for valid messages and non-messages.
+func SchemaWarnings(v any) []string {
+	msg, ok := v.(proto.Message)
+	if !ok || IsNil(v) {
+		return nil
+	}
+	var w schemaWalker
+	w.message("", msg.ProtoReflect())
+	return w.warnings
+}
+
+type schemaWalker struct {
+	warnings []string
+}
+
+func (w *schemaWalker) warn(format string, args ...any) {
+	w.warnings = append(w.warnings, fmt.Sprintf(format, args...))
+}
+
+func (w *schemaWalker) message(path string, m protoreflect.Message) {
+	desc := m.Descriptor()
+	for _, num := range unknownFieldNumbers(m.GetUnknown()) {
+		if path == "" {
+			w.warn("unknown field %d in %s", num, desc.FullName())
+		} else {
+			w.warn("%s: unknown field %d in %s", path, num, desc.FullName())
+		}
+	}
+
+	fields := desc.Fields()
+	for i := range fields.Len() {
+		fd := fields.Get(i)
+		if fd.Cardinality() == protoreflect.Required && !m.Has(fd) {
+			w.warn("%s: required field is missing", joinPath(path, fd))
+		}
+	}
+
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		p := joinPath(path, fd)
+		if isDeprecated(fd) {
+			w.warn("%s: deprecated field is set", p)
+		}
+		switch {
+		case fd.IsList():
+			list := v.List()
+			for i := range list.Len() {
+				w.value(p+"["+strconv.Itoa(i)+"]", fd, list.Get(i))
+			}
+		case fd.IsMap():
+			v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+				w.value(p+"["+k.String()+"]", fd.MapValue(), v)
+				return true
+			})
+		default:
+			w.value(p, fd, v)
+		}
+		return true
+	})
+}
+
+// value checks a single (non-list, non-map) value of field fd.
+func (w *schemaWalker) value(path string, fd protoreflect.FieldDescriptor, v protoreflect.Value) {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		enum := fd.Enum()
+		if enum.Values().ByNumber(v.Enum()) == nil {
+			w.warn("%s: enum value %d is not defined in %s", path, v.Enum(), enum.FullName())
+		}
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		w.message(path, v.Message())
+	default:
+	}
+}
+
+// unknownFieldNumbers returns the field numbers in raw unknown field bytes,
+// once each, in the order they appear.
+func unknownFieldNumbers(b protoreflect.RawFields) []protowire.Number {
+	var nums []protowire.Number
+	seen := make(map[protowire.Number]bool)
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return nums
+		}
+		b = b[n:]
+		n = protowire.ConsumeFieldValue(num, typ, b)
+		if n < 0 {
+			return nums
+		}
+		b = b[n:]
+		if !seen[num] {
+			seen[num] = true
+			nums = append(nums, num)
+		}
+	}
+	return nums
+}
+
+func isDeprecated(fd protoreflect.FieldDescriptor) bool {
+	opts, ok := fd.Options().(interface{ GetDeprecated() bool })
+	return ok && opts.GetDeprecated()
+}
+
+func joinPath(path string, fd protoreflect.FieldDescriptor) string {
+	if path == "" {
+		return string(fd.Name())
+	}
+	return path + "." + string(fd.Name())
+}
@@ -1,18 +1,45 @@
 package scope
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"math"
 	"net"
+	"net/http"
+	"os"
+	"path"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
 
 	"github.com/mickamy/grpc-scope/scope/domain"
 	"github.com/mickamy/grpc-scope/scope/internal/event"
 	"github.com/mickamy/grpc-scope/scope/internal/server"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/protobuf/encoding/protojson"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
 )
 
-const defaultPort = 9090
+const (
+	defaultPort           = 9090
+	defaultBindAddr       = "127.0.0.1"
+	defaultBufferSize     = 1024
+	defaultMaxPayloadSize = 4 << 20 // gRPC's default max message size
+	defaultHeartbeat      = 5 * time.Second
+
+	// lingerPollInterval is how often Close checks whether the last
+	// subscriber has left while lingering.
+	lingerPollInterval = 10 * time.Millisecond
+)
 
 // Option configures a Scope.
 type Option func(*Scope)
@@ -24,78 +51,927 @@ func WithPort(port int) Option {
 	}
 }
 
+// WithBindAddr sets the host the internal gRPC server listens on. It defaults
+// to 127.0.0.1, so captured payloads are only reachable from the same
+// machine. Use "0.0.0.0" (or "") to accept monitors from other hosts, and
+// consider WithAuthToken when doing so.
+func WithBindAddr(host string) Option {
+	return func(s *Scope) {
+		s.bindAddr = host
+	}
+}
+
+// WithBufferSize sets how many events are buffered for each Watch subscriber.
+// Events published while a subscriber's buffer is full are dropped for that
+// subscriber and counted; see DroppedEvents. The default is 1024.
+func WithBufferSize(n int) Option {
+	return func(s *Scope) {
+		s.bufferSize = n
+	}
+}
+
+// WithBlockingPublish makes publishing wait up to timeout for a slow Watch
+// subscriber to make room instead of dropping the event right away.
+//
+// Events are published on the RPC's goroutine, so while a subscriber is
+// behind, every captured call is delayed by up to timeout. Use it when
+// missing an event is worse than slowing the application; the default drops
+// events and never delays calls.
+func WithBlockingPublish(timeout time.Duration) Option {
+	return func(s *Scope) {
+		s.blockTimeout = timeout
+	}
+}
+
+// WithMaxRepeatedElements limits every JSON array in captured payloads to its
+// first n elements. The remaining elements are replaced by a single
+// "... N more elided" marker so payloads stay valid JSON. Replay sends the
+// payload as captured; to keep full payloads and only shorten what monitors
+// show, use WithMaxShownElements instead. Zero disables eliding.
+func WithMaxRepeatedElements(n int) Option {
+	return func(s *Scope) {
+		s.maxRepeated = n
+	}
+}
+
+// WithMaxShownElements makes monitors show only the first n elements of
+// every JSON array in payloads, with the rest shown as a single
+// "... N more elided" marker. Payloads are still captured in full, so replay
+// and copied grpcurl commands send every element. Zero shows arrays in full.
+func WithMaxShownElements(n int) Option {
+	return func(s *Scope) {
+		s.maxShown = n
+	}
+}
+
+// WithMaxPayloadSize sets the size budget, in bytes, for capturing a single
+// payload. Larger payloads are replaced by a placeholder instead of being
+// marshaled, so capturing a huge message does not stall the RPC.
+// For proto messages the budget is checked against the wire size before
+// marshaling. Zero disables the budget. The default is 4 MiB.
+func WithMaxPayloadSize(n int) Option {
+	return func(s *Scope) {
+		s.maxPayloadSize = n
+	}
+}
+
+// WithMaxBytesFieldSize replaces every bytes field longer than n bytes in a
+// captured proto payload with a "<N bytes>" placeholder instead of its base64
+// encoding, keeping payloads with file uploads or blobs readable. Non-proto
+// payloads are unaffected. Zero or negative keeps bytes fields intact.
+func WithMaxBytesFieldSize(n int) Option {
+	return func(s *Scope) {
+		s.maxBytesField = n
+	}
+}
+
+// WithRawRequestBytes also captures unary requests in their protobuf wire
+// encoding, which the monitor replays instead of the JSON payload, so fields
+// protojson cannot carry, such as unknown fields, reach the server intact.
+// The bytes are re-encoded from the decoded message, so their field order
+// may differ from what the client sent. They are subject to the payload size
+// budget and sample rate, and add to the size of every event.
+func WithRawRequestBytes() Option {
+	return func(s *Scope) {
+		s.rawRequests = true
+	}
+}
+
+// WithPayloadSampleRate captures request/response payloads for only the given
+// fraction (0.0-1.0) of successful calls. Every call is still published with
+// its method, status, and latency, and failed calls always include payloads.
+// The default is 1.0 (capture all payloads).
+func WithPayloadSampleRate(rate float64) Option {
+	return func(s *Scope) {
+		s.sampleRate = math.Max(0, math.Min(1, rate))
+	}
+}
+
+// CaptureFilter decides per call whether it is captured. md holds the incoming
+// request metadata with lowercase keys.
+type CaptureFilter func(method string, md metadata.MD) bool
+
+// DefaultIgnoreMethods are the method patterns not captured unless
+// WithIgnoreMethods overrides them: health checks, server reflection, and
+// the scope service itself.
+var DefaultIgnoreMethods = []string{
+	"/grpc.health.v1.Health/*",
+	"/grpc.reflection.*/*",
+	"/scope.v1.ScopeService/*",
+}
+
+// WithIgnoreMethods replaces DefaultIgnoreMethods with patterns. Calls whose
+// full method name, e.g. "/pkg.Service/Method", matches any of them in the
+// syntax of path.Match are never captured. Pass no patterns to capture
+// everything.
+func WithIgnoreMethods(patterns ...string) Option {
+	return func(s *Scope) {
+		s.ignoreMethods = patterns
+	}
+}
+
+// WithCaptureOnly captures only calls whose full method name matches any of
+// patterns in the syntax of path.Match; others are skipped before anything is
+// marshaled. A matching call is captured even if WithIgnoreMethods would
+// ignore it. Pass no patterns to capture every method not ignored.
+func WithCaptureOnly(patterns ...string) Option {
+	return func(s *Scope) {
+		s.captureOnly = patterns
+	}
+}
+
+// WithCaptureFilter calls fn before capturing each call. When fn returns
+// false the call is not captured at all; the handler still runs as usual.
+func WithCaptureFilter(fn CaptureFilter) Option {
+	return func(s *Scope) {
+		s.captureFilter = fn
+	}
+}
+
+// WithAuthToken requires TUI clients to present token in the x-scope-token
+// metadata key before they can Watch captured traffic. Without it, anyone who
+// can reach the scope port can watch every call.
+func WithAuthToken(token string) Option {
+	return func(s *Scope) {
+		s.authToken = token
+	}
+}
+
+// Processor enriches or rewrites a captured event before it is published,
+// e.g. to redact, annotate, or classify it.
+type Processor func(ev *domain.CallEvent)
+
+// IDFormat builds the ID of a captured event from its sequence number and the
+// event itself, e.g. to embed the method or status.
+type IDFormat func(seq uint64, ev *domain.CallEvent) string
+
+// WithIDFormat makes Publish replace the "call-N" ID of every event with the
+// one fn returns, before the processors run. fn must return unique IDs; seq
+// is the event's Seq, which is.
+func WithIDFormat(fn IDFormat) Option {
+	return func(s *Scope) {
+		s.idFormat = fn
+	}
+}
+
+// WithProcessor appends fn to the processors run, in the order they were
+// added, on every captured event before it is published.
+func WithProcessor(fn Processor) Option {
+	return func(s *Scope) {
+		s.processors = append(s.processors, fn)
+	}
+}
+
+// PayloadMarshaler formats a captured request or response for display.
+type PayloadMarshaler func(v any) string
+
+// WithPayloadMarshaler replaces MarshalPayload as the formatter for captured
+// payloads, e.g. to redact fields, render non-proto Connect messages, or skip
+// binary blobs. fn may call MarshalPayload for values it does not handle. The
+// payload size limit and array elision still apply to its output.
+func WithPayloadMarshaler(fn PayloadMarshaler) Option {
+	return func(s *Scope) {
+		s.marshaler = fn
+	}
+}
+
+// WithDeadlineSourceKey tracks who set each call's deadline. An interceptor
+// that injects a default deadline when the client sent none should also store
+// a marker naming itself, e.g. "server-default", in the context under key.
+// Events then record that marker as their DeadlineSource, or
+// domain.DeadlineSourceClient for deadlines without one. The scope
+// interceptors must run after the injecting interceptor to see either.
+func WithDeadlineSourceKey(key any) Option {
+	return func(s *Scope) {
+		s.deadlineSourceKey = key
+	}
+}
+
+// LabelExtractor returns labels describing a call, e.g. a tenant or user ID
+// that an earlier interceptor stored in its context.
+type LabelExtractor func(ctx context.Context) map[string]string
+
+// WithLabelExtractor records the labels fn returns for each captured call on
+// its event, so the TUI can filter calls by them. The scope interceptors must
+// run after whatever stores the values fn reads.
+func WithLabelExtractor(fn LabelExtractor) Option {
+	return func(s *Scope) {
+		s.labelExtractor = fn
+	}
+}
+
+// WithLogger makes the scope, its broker, and its server log diagnostics to
+// logger, e.g. payloads that could not be marshaled or events dropped for a
+// slow TUI client. Without it nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Scope) {
+		if logger != nil {
+			s.logger = logger
+		}
+	}
+}
+
+// WithAppTarget sets the application address the scope server reports to
+// TUI clients, so the monitor can replay calls without being given the
+// address. Without it, the address the first inbound call arrived on is
+// reported; see ObserveLocalAddr.
+func WithAppTarget(addr string) Option {
+	return func(s *Scope) {
+		s.appTarget = addr
+	}
+}
+
+// WithServeErrorHandler calls fn with the error when the internal gRPC
+// server or the HTTP endpoint stops serving before Close, e.g. because its
+// listener failed. Capturing carries on, but TUI clients can no longer
+// connect. fn runs on the server's goroutine. The error is also logged; see
+// WithLogger.
+func WithServeErrorHandler(fn func(error)) Option {
+	return func(s *Scope) {
+		s.onServeError = fn
+	}
+}
+
+// WithRuntimeStats makes every captured event record the number of
+// goroutines running in the application when it is published, to correlate
+// goroutine leaks with the calls that cause them.
+func WithRuntimeStats(enabled bool) Option {
+	return func(s *Scope) {
+		s.runtimeStats = enabled
+	}
+}
+
+// WithPersistPath keeps the last buffer-size events and, on Close, appends
+// the ones captured since New to path as JSON lines. New loads the newest of
+// them back, so TUIs connecting to a restarted application still see its
+// earlier calls. Every new Watch stream starts with this history. The file
+// is compacted to the buffer size when it is loaded; a file that cannot be
+// read is logged and ignored.
+func WithPersistPath(path string) Option {
+	return func(s *Scope) {
+		s.persistPath = path
+	}
+}
+
+// WithRecoverPanics makes the interceptors record calls whose handler
+// panicked, with an INTERNAL status and the panic value as the message. The
+// panic then continues with the same value, so a recovery interceptor
+// further out, or the crash without one, behaves as before. It is off by
+// default.
+func WithRecoverPanics() Option {
+	return func(s *Scope) {
+		s.recoverPanics = true
+	}
+}
+
+// WithHistorySize keeps the last n captured events in memory, so clients can
+// query them with the GetHistory RPC, e.g. for the latest failed calls to one
+// method. Unlike WithPersistPath, Watch streams still start with live events
+// only. Each kept event holds its payloads, so size n with the payload limit
+// in mind. It is off by default; with WithPersistPath, the larger of n and
+// the buffer size applies.
+func WithHistorySize(n int) Option {
+	return func(s *Scope) {
+		s.historySize = n
+	}
+}
+
+// WithHeartbeatInterval sets how long a Watch stream may stay idle before the
+// server sends an empty heartbeat response, which lets TUIs tell a quiet
+// application from a stalled connection. The default is 5 seconds; a
+// non-positive interval disables heartbeats.
+func WithHeartbeatInterval(interval time.Duration) Option {
+	return func(s *Scope) {
+		s.heartbeat = interval
+	}
+}
+
+// WithHTTPPort also serves captured events as JSON server-sent events on
+// GET /events at port, for browser dashboards or curl. It binds the same
+// address as the gRPC server and checks the same auth token, read from the
+// x-scope-token header or the token query parameter. Port 0 picks a free
+// port; see HTTPAddr. The endpoint is off unless this option is given.
+func WithHTTPPort(port int) Option {
+	return func(s *Scope) {
+		s.httpPort = port
+		s.httpEnabled = true
+	}
+}
+
+// WithLingerOnClose makes Close keep the scope server up for up to d while
+// TUI clients are still watching, so a short-lived process does not cut them
+// off before its last calls reach them. Close returns as soon as the last
+// client disconnects; clients still attached after d are disconnected. The
+// default is 0, which does not linger.
+func WithLingerOnClose(d time.Duration) Option {
+	return func(s *Scope) {
+		s.linger = d
+	}
+}
+
+// WithUnixSocket makes the internal gRPC server listen on a Unix domain socket
+// at path instead of a TCP port, so nothing is exposed on the network. The
+// monitor connects with a "unix://<path>" target. A stale socket left at path
+// by a previous run is removed.
+func WithUnixSocket(path string) Option {
+	return func(s *Scope) {
+		s.socketPath = path
+	}
+}
+
+// WithDisableServer captures events without starting the internal gRPC server
+// or the HTTP endpoint, so no port is bound, e.g. for parallel tests that
+// only read events through Subscribe, or when Handler is mounted on the
+// application's own server. Addr and HTTPAddr return nil, and TUI clients
+// cannot connect except through Handler.
+func WithDisableServer() Option {
+	return func(s *Scope) {
+		s.serverDisabled = true
+	}
+}
+
 // Scope manages the lifecycle of the event broker and internal gRPC server
 // that exposes captured traffic to TUI clients.
 type Scope struct {
-	port   int
-	broker *event.Broker
-	server *server.Server
-	nextID uint64
+	port              int
+	bindAddr          string
+	socketPath        string
+	httpPort          int
+	httpEnabled       bool
+	serverDisabled    bool
+	persistPath       string
+	historySize       int
+	recoverPanics     bool
+	restoredSeq       uint64 // highest Seq loaded from persistPath
+	bufferSize        int
+	blockTimeout      time.Duration
+	maxRepeated       int
+	maxShown          int // set by WithMaxShownElements
+	maxPayloadSize    int
+	maxBytesField     int
+	rawRequests       bool // set by WithRawRequestBytes
+	sampleRate        float64
+	sampled           atomic.Uint64 // successful calls seen by CapturePayload
+	ignoreMethods     []string
+	captureOnly       []string
+	captureFilter     CaptureFilter
+	idFormat          IDFormat
+	processors        []Processor
+	authToken         string
+	heartbeat         time.Duration
+	linger            time.Duration
+	runtimeStats      bool
+	logger            *slog.Logger
+	onServeError      func(error)            // set by WithServeErrorHandler
+	appTarget         string                 // set by WithAppTarget
+	observedTarget    atomic.Pointer[string] // recorded by ObserveLocalAddr
+	eventLog          *slog.Logger           // set by WithStderrLog
+	marshaler         PayloadMarshaler
+	deadlineSourceKey any
+	labelExtractor    LabelExtractor
+	broker            *event.Broker
+	server            *server.Server
+	handlerUsed       atomic.Bool // set by Handler
+	addr              net.Addr
+	httpServer        *http.Server // nil unless WithHTTPPort is set
+	httpAddr          net.Addr
+	nextID            atomic.Uint64
+	nextSeq           atomic.Uint64
 }
 
 // New creates a new Scope and starts the internal gRPC server.
 func New(opts ...Option) (*Scope, error) {
 	s := &Scope{
-		port:   defaultPort,
-		broker: event.NewBroker(1024),
+		port:           defaultPort,
+		bindAddr:       defaultBindAddr,
+		bufferSize:     defaultBufferSize,
+		maxPayloadSize: defaultMaxPayloadSize,
+		sampleRate:     1,
+		heartbeat:      defaultHeartbeat,
+		ignoreMethods:  DefaultIgnoreMethods,
+		logger:         slog.New(slog.DiscardHandler),
 	}
 	for _, opt := range opts {
 		opt(s)
 	}
+	policy := event.DropPolicy()
+	if s.blockTimeout > 0 {
+		policy = event.BlockPolicy(s.blockTimeout)
+	}
+	brokerOpts := []event.Option{event.WithLogger(s.logger)}
+	if s.persistPath != "" {
+		brokerOpts = append(brokerOpts, event.WithHistory(s.bufferSize))
+	}
+	if s.historySize > 0 {
+		brokerOpts = append(brokerOpts, event.WithRetention(s.historySize))
+	}
+	s.broker = event.NewBrokerWithPolicy(max(s.bufferSize, 0), policy, brokerOpts...)
+	if s.persistPath != "" {
+		s.restoreHistory()
+	}
+	s.server = server.New(s.broker,
+		server.WithAuthToken(s.authToken),
+		server.WithHeartbeat(s.heartbeat),
+		server.WithLogger(s.logger),
+		server.WithAppTarget(s.AppTarget),
+		server.WithMaxShownElements(s.maxShown),
+	)
+	if s.serverDisabled {
+		return s, nil
+	}
 
-	s.server = server.New(s.broker)
-
-	lis, err := net.Listen("tcp", fmt.Sprintf(":%d", s.port))
+	lis, err := s.listen()
 	if err != nil {
-		return nil, fmt.Errorf("grpc-scope: failed to listen on port %d: %w", s.port, err)
+		return nil, err
 	}
+	s.addr = lis.Addr()
 
 	go func() {
-		if err := s.server.Serve(lis); err != nil {
-			// server stopped
+		// Serve reports ErrServerStopped when Close wins the race to it.
+		if err := s.server.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+			s.logger.Error("grpc-scope: server stopped", "error", err)
+			s.serveFailed(fmt.Errorf("grpc-scope: server stopped: %w", err))
 		}
 	}()
 
+	if s.httpEnabled {
+		if err := s.serveHTTP(); err != nil {
+			s.server.GracefulStop()
+			return nil, err
+		}
+	}
+
 	return s, nil
 }
 
+// serveHTTP starts the server-sent events endpoint enabled by WithHTTPPort.
+func (s *Scope) serveHTTP() error {
+	addr := net.JoinHostPort(s.bindAddr, strconv.Itoa(s.httpPort))
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("grpc-scope: failed to listen on %s: %w", addr, err)
+	}
+	s.httpAddr = lis.Addr()
+	s.httpServer = &http.Server{
+		Handler:           s.server.EventsHandler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		if err := s.httpServer.Serve(lis); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("grpc-scope: HTTP server stopped", "error", err)
+			s.serveFailed(fmt.Errorf("grpc-scope: HTTP server stopped: %w", err))
+		}
+	}()
+	return nil
+}
+
+func (s *Scope) serveFailed(err error) {
+	if s.onServeError != nil {
+		s.onServeError(err)
+	}
+}
+
+func (s *Scope) listen() (net.Listener, error) {
+	if s.socketPath == "" {
+		addr := net.JoinHostPort(s.bindAddr, strconv.Itoa(s.port))
+		lis, err := net.Listen("tcp", addr)
+		if err != nil {
+			return nil, fmt.Errorf("grpc-scope: failed to listen on %s: %w", addr, err)
+		}
+		return lis, nil
+	}
+
+	if fi, err := os.Stat(s.socketPath); err == nil && fi.Mode()&os.ModeSocket != 0 {
+		_ = os.Remove(s.socketPath)
+	}
+	lis, err := net.Listen("unix", s.socketPath)
+	if err != nil {
+		return nil, fmt.Errorf("grpc-scope: failed to listen on socket %s: %w", s.socketPath, err)
+	}
+	return lis, nil
+}
+
+// Addr returns the address the internal gRPC server listens on, e.g. to find
+// the port chosen for WithPort(0), or nil with WithDisableServer.
+func (s *Scope) Addr() net.Addr {
+	return s.addr
+}
+
+// HTTPAddr returns the address the server-sent events endpoint listens on,
+// or nil unless WithHTTPPort is set and the server is enabled.
+func (s *Scope) HTTPAddr() net.Addr {
+	return s.httpAddr
+}
+
+// HandlerPath is the URL path prefix to mount Handler on.
+const HandlerPath = server.ServicePath
+
+// Handler returns the scope server as an http.Handler, to mount at
+// HandlerPath on the application's own server instead of opening a second
+// port, usually together with WithDisableServer. The monitor then connects to
+// the application's address.
+//
+// Only gRPC over HTTP/2 is served: the server must accept HTTP/2, through TLS
+// or, when it is plaintext, h2c. The handler does not speak the Connect
+// protocol or gRPC-Web: requests of any content type but gRPC's get 415
+// Unsupported Media Type, and gRPC requests over HTTP/1.1 get 505 HTTP
+// Version Not Supported, so Connect and gRPC-Web clients, such as a browser,
+// cannot use it.
+func (s *Scope) Handler() http.Handler {
+	s.handlerUsed.Store(true)
+	return s.server.Handler()
+}
+
 // SubscriberCount returns the number of active Watch subscribers.
 func (s *Scope) SubscriberCount() int {
 	return s.broker.SubscriberCount()
 }
 
-// Close stops the internal gRPC server.
+// DroppedEvents returns the number of events dropped because a Watch
+// subscriber fell behind, summed over all subscribers.
+func (s *Scope) DroppedEvents() uint64 {
+	return s.broker.DroppedTotal()
+}
+
+// AppTarget returns the application address reported to TUI clients: the
+// one given to WithAppTarget, or else the one ObserveLocalAddr recorded, or
+// "" if neither is known yet.
+func (s *Scope) AppTarget() string {
+	if s.appTarget != "" {
+		return s.appTarget
+	}
+	if t := s.observedTarget.Load(); t != nil {
+		return *t
+	}
+	return ""
+}
+
+// ObserveLocalAddr records addr, the local address an inbound call arrived
+// on, as the application's address. Only the first address is kept, and
+// none is needed when WithAppTarget is set. Interceptors call it for every
+// inbound call.
+func (s *Scope) ObserveLocalAddr(addr net.Addr) {
+	if addr == nil || s.appTarget != "" || s.observedTarget.Load() != nil {
+		return
+	}
+	target := addr.String()
+	if addr.Network() == "unix" {
+		target = "unix://" + target
+	}
+	s.observedTarget.CompareAndSwap(nil, &target)
+}
+
+// Subscribe returns a channel that receives every published event and a
+// function that unsubscribes, for tooling that consumes events in-process,
+// e.g. a test asserting that no call failed. It does not go through the gRPC
+// server, so it only sees events of this process.
+//
+// The channel is buffered like a Watch subscriber's (see WithBufferSize and
+// WithBlockingPublish), and the subscriber counts toward SubscriberCount, so
+// unsubscribe before Close when using WithLingerOnClose.
+func (s *Scope) Subscribe() (<-chan domain.CallEvent, func()) {
+	return s.broker.Subscribe()
+}
+
+// Close stops the internal gRPC server and the HTTP endpoint, if any. With
+// WithLingerOnClose, it first waits for connected clients to leave.
 func (s *Scope) Close() {
-	s.server.GracefulStop()
+	lingered := s.lingerForSubscribers()
+	if s.httpServer != nil {
+		// Event streams never end on their own, so don't wait for them.
+		_ = s.httpServer.Close()
+	}
+	switch {
+	case lingered:
+		// Whoever is still watching has had its chance; don't wait for them.
+		s.server.Stop()
+	case s.handlerUsed.Load():
+		// Streams served through Handler can't be drained, only cancelled.
+		s.server.Stop()
+	default:
+		s.server.GracefulStop()
+	}
+	if s.persistPath != "" {
+		s.persistHistory()
+	}
+}
+
+// lingerForSubscribers waits up to the linger duration for Watch and event
+// stream subscribers to disconnect. It reports whether it waited at all.
+func (s *Scope) lingerForSubscribers() bool {
+	if s.linger <= 0 || s.broker.SubscriberCount() == 0 {
+		return false
+	}
+	deadline := time.After(s.linger)
+	tick := time.NewTicker(lingerPollInterval)
+	defer tick.Stop()
+	for s.broker.SubscriberCount() > 0 {
+		select {
+		case <-deadline:
+			return true
+		case <-tick.C:
+		}
+	}
+	return true
+}
+
+// restoreHistory seeds the broker with the events persisted at persistPath
+// and continues numbering after them, so restored and new events stay
+// distinct and ordered.
+func (s *Scope) restoreHistory() {
+	events, err := loadHistory(s.persistPath, s.bufferSize)
+	if err != nil {
+		s.logger.Warn("grpc-scope: history not restored", "path", s.persistPath, "error", err)
+		return
+	}
+	s.broker.Seed(events)
+	var lastID uint64
+	for _, ev := range events {
+		s.restoredSeq = max(s.restoredSeq, ev.Seq)
+		var n uint64
+		if _, err := fmt.Sscanf(ev.ID, "call-%d", &n); err == nil {
+			lastID = max(lastID, n)
+		}
+	}
+	s.nextSeq.Store(s.restoredSeq)
+	s.nextID.Store(lastID)
 }
 
-// Publish sends a CallEvent to all connected subscribers.
+// persistHistory appends the events published since New to persistPath.
+func (s *Scope) persistHistory() {
+	var captured []domain.CallEvent
+	for _, ev := range s.broker.History() {
+		if ev.Seq > s.restoredSeq {
+			captured = append(captured, ev)
+		}
+	}
+	if err := appendHistory(s.persistPath, captured); err != nil {
+		s.logger.Warn("grpc-scope: history not persisted", "path", s.persistPath, "error", err)
+	}
+}
+
+// Publish assigns ev the next sequence number and, if configured, a
+// formatted ID, records runtime stats if enabled, runs the processors on it,
+// logs it if WithStderrLog is set, and sends it to all connected subscribers.
 func (s *Scope) Publish(ev domain.CallEvent) {
+	ev.Seq = s.nextSeq.Add(1)
+	if s.idFormat != nil {
+		ev.ID = s.idFormat(ev.Seq, &ev)
+	}
+	if s.runtimeStats {
+		ev.Goroutines = runtime.NumGoroutine()
+	}
+	for _, p := range s.processors {
+		p(&ev)
+	}
+	if s.eventLog != nil {
+		logEvent(s.eventLog, ev)
+	}
 	s.broker.Publish(ev)
 }
 
-// GenerateID returns a unique sequential ID for a call event.
+// GenerateID returns a unique sequential ID for a call event. It is safe for
+// concurrent use, as interceptors call it from every in-flight RPC.
 func (s *Scope) GenerateID() string {
-	s.nextID++
-	return fmt.Sprintf("call-%d", s.nextID)
+	return fmt.Sprintf("call-%d", s.nextID.Add(1))
+}
+
+// ShouldCapture reports whether a call to method with the given request
+// metadata should be captured, according to the capture-only patterns, the
+// ignored methods, and the capture filter.
+func (s *Scope) ShouldCapture(method string, md domain.Metadata) bool {
+	if len(s.captureOnly) > 0 {
+		if !matchesAny(s.captureOnly, method) {
+			return false
+		}
+	} else if matchesAny(s.ignoreMethods, method) {
+		return false
+	}
+	if s.captureFilter == nil {
+		return true
+	}
+	out := make(metadata.MD, len(md))
+	for k, vs := range md {
+		key := strings.ToLower(k)
+		out[key] = append(out[key], vs...)
+	}
+	return s.captureFilter(method, out)
+}
+
+// matchesAny reports whether method matches any of patterns.
+func matchesAny(patterns []string, method string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, method); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// CapturePayload reports whether payloads should be captured for ev, based on
+// the payload sample rate. Failed calls are always captured. Successful calls
+// are sampled evenly, so exactly the configured fraction of them is captured.
+func (s *Scope) CapturePayload(ev domain.CallEvent) bool {
+	if ev.IsError() || s.sampleRate >= 1 {
+		return true
+	}
+	n := s.sampled.Add(1)
+	return math.Floor(float64(n)*s.sampleRate) > math.Floor(float64(n-1)*s.sampleRate)
+}
+
+// Marshal serializes a payload with the Scope's PayloadMarshaler, or
+// MarshalPayload if none is set, and applies the payload options configured
+// on the Scope.
+func (s *Scope) Marshal(v any) string {
+	if msg, ok := v.(proto.Message); ok && s.maxPayloadSize > 0 {
+		if size := proto.Size(msg); size > s.maxPayloadSize {
+			return omittedPayload(size, s.maxPayloadSize)
+		}
+	}
+	var out string
+	if s.marshaler != nil {
+		out = s.marshaler(v)
+	} else {
+		var err error
+		if out, err = s.marshalDefault(v); err != nil {
+			s.logger.Warn("grpc-scope: payload marshal failed", "type", fmt.Sprintf("%T", v), "error", err)
+		}
+	}
+	if s.maxPayloadSize > 0 && len(out) > s.maxPayloadSize {
+		return omittedPayload(len(out), s.maxPayloadSize)
+	}
+	return elideArrays(out, s.maxRepeated)
+}
+
+// SchemaWarnings returns the package-level SchemaWarnings for a request
+// whose payload is captured. It returns nil for a proto message over the
+// payload size budget, which Marshal omits, so checking the schema never
+// walks a message too large to capture.
+func (s *Scope) SchemaWarnings(v any) []string {
+	if msg, ok := v.(proto.Message); ok && s.maxPayloadSize > 0 && proto.Size(msg) > s.maxPayloadSize {
+		return nil
+	}
+	return SchemaWarnings(v)
+}
+
+// MarshalRaw returns the protobuf wire encoding of a request when
+// WithRawRequestBytes is set. It returns nil otherwise, and for values that
+// are not proto messages or exceed the payload size budget.
+func (s *Scope) MarshalRaw(v any) []byte {
+	msg, ok := v.(proto.Message)
+	if !s.rawRequests || !ok || IsNil(v) {
+		return nil
+	}
+	if s.maxPayloadSize > 0 && proto.Size(msg) > s.maxPayloadSize {
+		return nil
+	}
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		s.logger.Warn("grpc-scope: raw payload marshal failed", "type", fmt.Sprintf("%T", v), "error", err)
+		return nil
+	}
+	return b
+}
+
+// marshalDefault is marshalPayload, eliding large bytes fields of proto
+// messages when WithMaxBytesFieldSize is set.
+func (s *Scope) marshalDefault(v any) (string, error) {
+	if msg, ok := v.(proto.Message); ok && s.maxBytesField > 0 {
+		out, err := marshalElidingBytes(msg, s.maxBytesField)
+		if err == nil {
+			return out, nil
+		}
+		s.logger.Warn("grpc-scope: bytes field elision failed", "type", fmt.Sprintf("%T", v), "error", err)
+	}
+	return marshalPayload(v)
+}
+
+func omittedPayload(size, limit int) string {
+	return fmt.Sprintf("<payload omitted: %d bytes exceeds the %d-byte capture limit>", size, limit)
+}
+
+// PreviousAttempts returns the number of attempts that preceded a retried call,
+// as reported by the client in the grpc-previous-rpc-attempts header.
+// It returns 0 when the header is absent or malformed.
+func PreviousAttempts(md domain.Metadata) int {
+	n, err := strconv.Atoi(md.Get(domain.HeaderPreviousAttempts))
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// DeadlineSource returns who set ctx's deadline, as configured by
+// WithDeadlineSourceKey. It returns "" when ctx has no deadline or no key is
+// configured.
+func (s *Scope) DeadlineSource(ctx context.Context) string {
+	if s.deadlineSourceKey == nil {
+		return ""
+	}
+	if _, ok := ctx.Deadline(); !ok {
+		return ""
+	}
+	if marker := ctx.Value(s.deadlineSourceKey); marker != nil {
+		return fmt.Sprint(marker)
+	}
+	return domain.DeadlineSourceClient
+}
+
+// RecoverPanic calls record with the value of a panic in progress and then
+// panics again with it, from the same stack, so the crash report still
+// points at the handler. Interceptors defer it directly around the handler
+// call. It does nothing unless WithRecoverPanics is set.
+func (s *Scope) RecoverPanic(record func(v any)) {
+	if !s.recoverPanics {
+		return
+	}
+	if v := recover(); v != nil {
+		record(v)
+		panic(v)
+	}
+}
+
+// PanicMessage returns the status message recorded for a handler that
+// panicked with v.
+func PanicMessage(v any) string {
+	return fmt.Sprintf("panic: %v", v)
+}
+
+// Labels returns the labels for a call with context ctx, as configured by
+// WithLabelExtractor, or nil when no extractor is configured or it returns
+// none. The result is a copy the event can keep.
+func (s *Scope) Labels(ctx context.Context) map[string]string {
+	if s.labelExtractor == nil {
+		return nil
+	}
+	labels := s.labelExtractor(ctx)
+	if len(labels) == 0 {
+		return nil
+	}
+	return maps.Clone(labels)
+}
+
+// ForwardedPath returns the original HTTP path of a call transcoded by an
+// HTTP/JSON gateway, as forwarded in the x-forwarded-path header. It returns
+// "" for calls that did not come through a gateway.
+func ForwardedPath(md domain.Metadata) string {
+	return md.Get(domain.HeaderForwardedPath)
+}
+
+// MarshalStatusDetails returns the details of an error status as JSON, each
+// with an "@type" field. A detail whose type is not registered in the
+// application is reported by its type URL alone.
+func MarshalStatusDetails(details []*anypb.Any) []string {
+	if len(details) == 0 {
+		return nil
+	}
+	out := make([]string, 0, len(details))
+	for _, d := range details {
+		b, err := protojson.Marshal(d)
+		if err != nil {
+			b, _ = json.Marshal(map[string]string{"@type": d.GetTypeUrl()})
+		}
+		out = append(out, string(b))
+	}
+	return out
 }
 
 // MarshalPayload serializes a value to a JSON string for display.
 // It first attempts protojson for proto.Message values,
 // then falls back to encoding/json, then fmt.Sprintf.
 func MarshalPayload(v any) string {
+	out, _ := marshalPayload(v)
+	return out
+}
+
+// IsNil reports whether v is nil or a nil pointer, such as the typed nil
+// behind an interface when a handler returns (nil, nil).
+func IsNil(v any) bool {
 	if v == nil {
-		return ""
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	return rv.Kind() == reflect.Pointer && rv.IsNil()
+}
+
+// marshalPayload is MarshalPayload, also returning the error that forced a
+// fallback to a lesser encoding, if any.
+func marshalPayload(v any) (string, error) {
+	if v == nil {
+		return "", nil
 	}
+	var protoErr error
 	if msg, ok := v.(proto.Message); ok {
 		b, err := protojson.Marshal(msg)
 		if err == nil {
-			return string(b)
+			return string(b), nil
 		}
+		protoErr = err
 	}
 	b, err := json.Marshal(v)
 	if err != nil {
-		return fmt.Sprintf("%v", v)
+		return fmt.Sprintf("%v", v), errors.Join(protoErr, err)
 	}
-	return string(b)
+	return string(b), protoErr
 }
@@ -0,0 +1,1237 @@
+package scope_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mickamy/grpc-scope/scope"
+	"github.com/mickamy/grpc-scope/scope/domain"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/known/sourcecontextpb"
+	"google.golang.org/protobuf/types/known/typepb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+func TestScope_Marshal_MaxRepeatedElements(t *testing.T) {
+	t.Parallel()
+
+	s, err := scope.New(scope.WithPort(0), scope.WithMaxRepeatedElements(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	values := make([]string, 100)
+	for i := range values {
+		values[i] = fmt.Sprintf("v%d", i)
+	}
+
+	tests := []struct {
+		name string
+		in   any
+		want string
+	}{
+		{
+			name: "long repeated field is elided",
+			in:   &scopev1.MetadataValues{Values: values},
+			want: `{"values":["v0","v1","v2","... 97 more elided"]}`,
+		},
+		{
+			name: "short repeated field is kept",
+			in:   &scopev1.MetadataValues{Values: values[:3]},
+			want: `{"values":["v0","v1","v2"]}`,
+		},
+		{
+			name: "nested arrays keep key order",
+			in: map[string]any{
+				"a": []any{[]int{1, 2, 3, 4}, 2, 3, 4, 5},
+				"b": "<tag>",
+			},
+			want: `{"a":[[1,2,3,"... 1 more elided"],2,3,"... 2 more elided"],"b":"<tag>"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := s.Marshal(tt.in)
+			if !json.Valid([]byte(got)) {
+				t.Fatalf("Marshal() produced invalid JSON: %s", got)
+			}
+			if got != tt.want {
+				t.Errorf("Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScope_Marshal_MaxShownElements(t *testing.T) {
+	t.Parallel()
+
+	s, err := scope.New(scope.WithPort(0), scope.WithMaxShownElements(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	values := make([]string, 100)
+	for i := range values {
+		values[i] = fmt.Sprintf("v%d", i)
+	}
+	msg := &scopev1.MetadataValues{Values: values}
+
+	// Monitors elide long arrays when showing them; the captured payload
+	// keeps every element so replay sends the original request.
+	got := &scopev1.MetadataValues{}
+	if err := protojson.Unmarshal([]byte(s.Marshal(msg)), got); err != nil {
+		t.Fatal(err)
+	}
+	if !proto.Equal(got, msg) {
+		t.Errorf("Marshal() lost elements: got %d values, want %d", len(got.GetValues()), len(values))
+	}
+}
+func TestScope_Marshal_MaxPayloadSize(t *testing.T) {
+	t.Parallel()
+
+	s, err := scope.New(scope.WithPort(0), scope.WithMaxPayloadSize(1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	huge := &scopev1.MetadataValues{Values: make([]string, 1_000_000)}
+	for i := range huge.Values {
+		huge.Values[i] = "0123456789"
+	}
+
+	start := time.Now()
+	got := s.Marshal(huge)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Marshal() took %s, want it bounded by the size check", elapsed)
+	}
+	if !strings.HasPrefix(got, "<payload omitted:") || !strings.Contains(got, "1024-byte capture limit") {
+		t.Errorf("Marshal() = %q, want placeholder", got)
+	}
+
+	if got := s.Marshal(map[string]string{"big": strings.Repeat("x", 2048)}); !strings.HasPrefix(got, "<payload omitted:") {
+		t.Errorf("Marshal() of large non-proto value = %q, want placeholder", got)
+	}
+
+	small := &scopev1.MetadataValues{Values: []string{"a"}}
+	if got, want := s.Marshal(small), `{"values":["a"]}`; got != want {
+		t.Errorf("Marshal() = %q, want %q", got, want)
+	}
+}
+
+func TestScope_Marshal_MaxBytesFieldSize(t *testing.T) {
+	t.Parallel()
+
+	s, err := scope.New(scope.WithPort(0), scope.WithMaxBytesFieldSize(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	big := bytes.Repeat([]byte{0xff}, 1000)
+	opts := &descriptorpb.MessageOptions{
+		UninterpretedOption: []*descriptorpb.UninterpretedOption{
+			{StringValue: big},
+			{StringValue: []byte("small")},
+		},
+	}
+
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{name: "top-level bytes", v: wrapperspb.Bytes(big), want: `"<1000 bytes>"`},
+		{name: "bytes at or under the limit", v: wrapperspb.Bytes([]byte("12345678")), want: `"MTIzNDU2Nzg="`},
+		{
+			name: "nested repeated messages",
+			v:    opts,
+			want: `{"uninterpretedOption":[{"stringValue":"<1000 bytes>"},{"stringValue":"c21hbGw="}]}`,
+		},
+		{name: "non-proto payload", v: map[string][]byte{"blob": []byte("0123456789")}, want: `{"blob":"MDEyMzQ1Njc4OQ=="}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			out := s.Marshal(tt.v)
+			if compact(t, out) != tt.want {
+				t.Errorf("Marshal() = %s, want %s", out, tt.want)
+			}
+		})
+	}
+
+	if got := opts.GetUninterpretedOption()[0].GetStringValue(); !bytes.Equal(got, big) {
+		t.Error("expected Marshal to leave the captured message unmodified")
+	}
+}
+
+// compact strips insignificant whitespace, which protojson adds at random.
+func compact(t *testing.T, s string) string {
+	t.Helper()
+
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(s)); err != nil {
+		t.Fatalf("invalid JSON %q: %v", s, err)
+	}
+	return buf.String()
+}
+
+func TestScope_Marshal_PayloadMarshaler(t *testing.T) {
+	t.Parallel()
+
+	type credentials struct {
+		User     string
+		password string
+	}
+	s, err := scope.New(
+		scope.WithPort(0),
+		scope.WithMaxPayloadSize(64),
+		scope.WithPayloadMarshaler(func(v any) string {
+			if c, ok := v.(credentials); ok {
+				return fmt.Sprintf(`{"user":%q,"password":"<redacted>"}`, c.User)
+			}
+			return scope.MarshalPayload(v)
+		}),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{name: "custom", v: credentials{User: "alice", password: "hunter2"}, want: `{"user":"alice","password":"<redacted>"}`},
+		{name: "fallback", v: &scopev1.MetadataValues{Values: []string{"a"}}, want: `{"values":["a"]}`},
+		{name: "size limit", v: credentials{User: strings.Repeat("x", 64)}, want: "<payload omitted: 99 bytes exceeds the 64-byte capture limit>"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := s.Marshal(tt.v); got != tt.want {
+				t.Errorf("Marshal() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchemaWarnings(t *testing.T) {
+	t.Parallel()
+
+	unknownField := protowire.AppendVarint(protowire.AppendTag(nil, 7, protowire.VarintType), 1)
+	unknown := &wrapperspb.StringValue{Value: "ok"}
+	unknown.ProtoReflect().SetUnknown(unknownField)
+	nested := &sourcecontextpb.SourceContext{FileName: "a.proto"}
+	nested.ProtoReflect().SetUnknown(unknownField)
+
+	tests := []struct {
+		name string
+		msg  any
+		want []string
+	}{
+		{
+			name: "valid",
+			msg:  &wrapperspb.StringValue{Value: "ok"},
+		},
+		{
+			name: "not a message",
+			msg:  "text",
+		},
+		{
+			name: "unknown field",
+			msg:  unknown,
+			want: []string{"unknown field 7 in google.protobuf.StringValue"},
+		},
+		{
+			name: "nested unknown field",
+			msg:  &typepb.Type{SourceContext: nested},
+			want: []string{"source_context: unknown field 7 in google.protobuf.SourceContext"},
+		},
+		{
+			name: "undefined enum value",
+			msg:  &typepb.Type{Fields: []*typepb.Field{{Name: "a"}, {Name: "b", Kind: 99}}},
+			want: []string{"fields[1].kind: enum value 99 is not defined in google.protobuf.Field.Kind"},
+		},
+		{
+			name: "deprecated field",
+			msg:  &descriptorpb.FileOptions{JavaGenerateEqualsAndHash: proto.Bool(true)},
+			want: []string{"java_generate_equals_and_hash: deprecated field is set"},
+		},
+		{
+			name: "missing required field",
+			msg:  &descriptorpb.UninterpretedOption{Name: []*descriptorpb.UninterpretedOption_NamePart{{NamePart: proto.String("a")}}},
+			want: []string{"name[0].is_extension: required field is missing"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := scope.SchemaWarnings(tt.msg); !slices.Equal(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScope_SchemaWarnings_MaxPayloadSize(t *testing.T) {
+	t.Parallel()
+
+	s, err := scope.New(scope.WithDisableServer(), scope.WithMaxPayloadSize(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	unknownField := protowire.AppendVarint(protowire.AppendTag(nil, 7, protowire.VarintType), 1)
+	small := &wrapperspb.StringValue{}
+	small.ProtoReflect().SetUnknown(unknownField)
+	large := &wrapperspb.StringValue{Value: "too large to capture"}
+	large.ProtoReflect().SetUnknown(unknownField)
+
+	if got := s.SchemaWarnings(small); len(got) != 1 {
+		t.Errorf("got warnings %q for a message within the budget, want one", got)
+	}
+	if got := s.SchemaWarnings(large); got != nil {
+		t.Errorf("got warnings %q for a message over the budget, want none", got)
+	}
+}
+
+func TestScope_WithLogger(t *testing.T) {
+	t.Parallel()
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	s, err := scope.New(scope.WithPort(0), scope.WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	if got := s.Marshal(map[string]string{"ok": "yes"}); got != `{"ok":"yes"}` {
+		t.Errorf("Marshal() = %q, want JSON", got)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("expected no logs for a successful marshal, got:\n%s", logs.String())
+	}
+
+	s.Marshal(make(chan int))
+	if !strings.Contains(logs.String(), "payload marshal failed") || !strings.Contains(logs.String(), "type=\"chan int\"") {
+		t.Errorf("expected a marshal failure log, got:\n%s", logs.String())
+	}
+}
+
+func TestScope_CapturePayload_SampleRate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		rate float64
+		want int // successful calls out of 100 with payloads
+	}{
+		{name: "default captures all", rate: -1, want: 100},
+		{name: "quarter", rate: 0.25, want: 25},
+		{name: "none", rate: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			opts := []scope.Option{scope.WithPort(0)}
+			if tt.rate >= 0 {
+				opts = append(opts, scope.WithPayloadSampleRate(tt.rate))
+			}
+			s, err := scope.New(opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(s.Close)
+
+			okCaptured, errCaptured := 0, 0
+			for range 100 {
+				if s.CapturePayload(domain.CallEvent{StatusCode: domain.StatusOK}) {
+					okCaptured++
+				}
+				if s.CapturePayload(domain.CallEvent{StatusCode: domain.StatusInternal}) {
+					errCaptured++
+				}
+			}
+
+			if okCaptured != tt.want {
+				t.Errorf("captured payloads for %d/100 successful calls, want %d", okCaptured, tt.want)
+			}
+			if errCaptured != 100 {
+				t.Errorf("captured payloads for %d/100 failed calls, want 100", errCaptured)
+			}
+		})
+	}
+}
+
+func TestScope_WithBindAddr(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []scope.Option
+		want func(net.IP) bool
+	}{
+		{name: "loopback by default", want: net.IP.IsLoopback},
+		{name: "all interfaces", opts: []scope.Option{scope.WithBindAddr("0.0.0.0")}, want: net.IP.IsUnspecified},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := scope.New(append([]scope.Option{scope.WithPort(0)}, tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(s.Close)
+
+			addr, ok := s.Addr().(*net.TCPAddr)
+			if !ok {
+				t.Fatalf("got address %v, want a TCP address", s.Addr())
+			}
+			if !tt.want(addr.IP) {
+				t.Errorf("got listener on %s", addr)
+			}
+		})
+	}
+}
+
+func TestScope_ShouldCapture_IgnoreMethods(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		opts   []scope.Option
+		method string
+		want   bool
+	}{
+		{name: "application method", method: "/greeter.v1.GreeterService/SayHello", want: true},
+		{name: "health check", method: "/grpc.health.v1.Health/Check", want: false},
+		{name: "reflection", method: "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo", want: false},
+		{name: "scope service", method: "/scope.v1.ScopeService/Watch", want: false},
+		{
+			name:   "custom pattern",
+			opts:   []scope.Option{scope.WithIgnoreMethods("/greeter.v1.GreeterService/*")},
+			method: "/greeter.v1.GreeterService/SayHello",
+			want:   false,
+		},
+		{
+			name:   "custom pattern replaces defaults",
+			opts:   []scope.Option{scope.WithIgnoreMethods("/greeter.v1.GreeterService/*")},
+			method: "/grpc.health.v1.Health/Check",
+			want:   true,
+		},
+		{
+			name:   "capture everything",
+			opts:   []scope.Option{scope.WithIgnoreMethods()},
+			method: "/grpc.reflection.v1.ServerReflection/ServerReflectionInfo",
+			want:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := scope.New(append([]scope.Option{scope.WithPort(0)}, tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(s.Close)
+
+			if got := s.ShouldCapture(tt.method, nil); got != tt.want {
+				t.Errorf("ShouldCapture(%q) = %v, want %v", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScope_ShouldCapture_CaptureOnly(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		opts   []scope.Option
+		method string
+		want   bool
+	}{
+		{
+			name:   "matching method",
+			opts:   []scope.Option{scope.WithCaptureOnly("/greeter.v1.GreeterService/SayHello", "/order.v1.*/Create")},
+			method: "/order.v1.OrderService/Create",
+			want:   true,
+		},
+		{
+			name:   "other method",
+			opts:   []scope.Option{scope.WithCaptureOnly("/greeter.v1.GreeterService/SayHello")},
+			method: "/greeter.v1.GreeterService/SayGoodbye",
+			want:   false,
+		},
+		{
+			name: "wins over ignore list",
+			opts: []scope.Option{
+				scope.WithIgnoreMethods("/greeter.v1.GreeterService/*"),
+				scope.WithCaptureOnly("/greeter.v1.GreeterService/SayHello"),
+			},
+			method: "/greeter.v1.GreeterService/SayHello",
+			want:   true,
+		},
+		{
+			name:   "empty captures everything not ignored",
+			opts:   []scope.Option{scope.WithCaptureOnly()},
+			method: "/greeter.v1.GreeterService/SayHello",
+			want:   true,
+		},
+		{
+			name:   "empty keeps ignore list",
+			opts:   []scope.Option{scope.WithCaptureOnly()},
+			method: "/grpc.health.v1.Health/Check",
+			want:   false,
+		},
+		{
+			name: "capture filter still applies",
+			opts: []scope.Option{
+				scope.WithCaptureOnly("/greeter.v1.GreeterService/*"),
+				scope.WithCaptureFilter(func(string, metadata.MD) bool { return false }),
+			},
+			method: "/greeter.v1.GreeterService/SayHello",
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := scope.New(append([]scope.Option{scope.WithPort(0)}, tt.opts...)...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(s.Close)
+
+			if got := s.ShouldCapture(tt.method, nil); got != tt.want {
+				t.Errorf("ShouldCapture(%q) = %v, want %v", tt.method, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScope_WithUnixSocket(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "scope.sock")
+
+	// Leave a stale socket behind, as a crashed previous run would.
+	stale, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	_ = stale.Close()
+
+	s, err := scope.New(scope.WithUnixSocket(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	conn, err := grpc.NewClient("unix://"+path, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	stream, err := scopev1.NewScopeServiceClient(conn).Watch(t.Context(), &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for s.SubscriberCount() == 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	s.Publish(domain.CallEvent{ID: "evt-1", StatusCode: domain.StatusOK})
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.GetEvent().GetId(); got != "evt-1" {
+		t.Errorf("got event %q, want %q", got, "evt-1")
+	}
+}
+
+func TestScope_WithHTTPPort(t *testing.T) {
+	t.Parallel()
+
+	s, err := scope.New(scope.WithPort(0), scope.WithHTTPPort(0), scope.WithAuthToken("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	url := fmt.Sprintf("http://%s/events", s.HTTPAddr())
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("got status %d without a token, want 401", resp.StatusCode)
+	}
+
+	resp, err = http.Get(url + "?token=secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("got content type %q, want text/event-stream", ct)
+	}
+
+	for s.SubscriberCount() < 1 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	s.Publish(domain.CallEvent{ID: s.GenerateID(), Method: "/test.v1.Test/Get", StatusCode: domain.StatusOK})
+
+	sc := bufio.NewScanner(resp.Body)
+	var lines []string
+	for sc.Scan() && sc.Text() != "" {
+		lines = append(lines, sc.Text())
+	}
+	if len(lines) != 2 || lines[0] != "id: 1" || !strings.HasPrefix(lines[1], "data: ") {
+		t.Fatalf("got message %q, want an id and a data line", lines)
+	}
+	var ev scopev1.CallEvent
+	if err := protojson.Unmarshal([]byte(strings.TrimPrefix(lines[1], "data: ")), &ev); err != nil {
+		t.Fatal(err)
+	}
+	if ev.GetId() != "call-1" || ev.GetMethod() != "/test.v1.Test/Get" {
+		t.Errorf("got event %v, want call-1 to /test.v1.Test/Get", &ev)
+	}
+}
+
+func TestScope_WithServeErrorHandler_NotCalledOnClose(t *testing.T) {
+	t.Parallel()
+
+	var errs []error
+	var mu sync.Mutex
+	s, err := scope.New(scope.WithPort(0), scope.WithHTTPPort(0), scope.WithServeErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+	// Give the serving goroutines time to return after Close.
+	time.Sleep(50 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) > 0 {
+		t.Errorf("expected no serve errors after Close, got %v", errs)
+	}
+}
+
+func TestScope_WithHTTPPort_Disabled(t *testing.T) {
+	t.Parallel()
+
+	s, err := scope.New(scope.WithPort(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	if addr := s.HTTPAddr(); addr != nil {
+		t.Errorf("got HTTP address %s, want none by default", addr)
+	}
+}
+
+func TestScope_WithLingerOnClose(t *testing.T) {
+	t.Parallel()
+
+	const linger = 200 * time.Millisecond
+	tests := []struct {
+		name       string
+		subscribe  bool
+		disconnect bool
+		wantMin    time.Duration
+		wantMax    time.Duration
+	}{
+		{name: "subscriber attached", subscribe: true, wantMin: linger, wantMax: 5 * linger},
+		{name: "subscriber leaves", subscribe: true, disconnect: true, wantMax: linger},
+		{name: "no subscribers", wantMax: linger / 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := scope.New(scope.WithPort(0), scope.WithLingerOnClose(linger))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.subscribe {
+				conn, err := grpc.NewClient(s.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				t.Cleanup(func() { _ = conn.Close() })
+				ctx, cancel := context.WithCancel(t.Context())
+				t.Cleanup(cancel)
+				if _, err := scopev1.NewScopeServiceClient(conn).Watch(ctx, &scopev1.WatchRequest{}); err != nil {
+					t.Fatal(err)
+				}
+				for s.SubscriberCount() == 0 {
+					time.Sleep(5 * time.Millisecond)
+				}
+				if tt.disconnect {
+					time.AfterFunc(linger/4, cancel)
+				}
+			}
+
+			start := time.Now()
+			s.Close()
+			elapsed := time.Since(start)
+			if elapsed < tt.wantMin || elapsed > tt.wantMax {
+				t.Errorf("Close took %s, want between %s and %s", elapsed, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestScope_WithHistorySize(t *testing.T) {
+	t.Parallel()
+
+	s, err := scope.New(scope.WithPort(0), scope.WithHistorySize(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+	for i := range 3 {
+		s.Publish(domain.CallEvent{ID: fmt.Sprintf("evt-%d", i+1), Method: "/test.v1.Test/Get", StatusCode: domain.StatusOK})
+	}
+
+	conn, err := grpc.NewClient(s.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	resp, err := scopev1.NewScopeServiceClient(conn).GetHistory(t.Context(), &scopev1.GetHistoryRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, ev := range resp.GetEvents() {
+		ids = append(ids, ev.GetId())
+	}
+	if got := strings.Join(ids, ","); got != "evt-2,evt-3" {
+		t.Errorf("got history %s, want evt-2,evt-3", got)
+	}
+}
+
+// TestScope_WithStderrLog replaces os.Stderr, so it must not run in parallel.
+func TestScope_WithStderrLog(t *testing.T) {
+	tests := []struct {
+		format scope.LogFormat
+		want   []string
+	}{
+		{
+			format: scope.LogFormatJSON,
+			want:   []string{`"level":"WARN"`, `"method":"/test.v1.Test/Get"`, `"status":"NOT_FOUND"`, `"message":"no such user"`, `"duration":1500000`},
+		},
+		{
+			format: scope.LogFormatLogfmt,
+			want:   []string{"level=WARN", "method=/test.v1.Test/Get", "status=NOT_FOUND", `message="no such user"`, "duration=1.5ms"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			r, w, err := os.Pipe()
+			if err != nil {
+				t.Fatal(err)
+			}
+			stderr := os.Stderr
+			os.Stderr = w
+			s, err := scope.New(scope.WithPort(0), scope.WithStderrLog(tt.format))
+			os.Stderr = stderr
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(s.Close)
+
+			// Nothing is subscribed: the log does not depend on a TUI.
+			s.Publish(domain.CallEvent{
+				ID:            "call-1",
+				Method:        "/test.v1.Test/Get",
+				StatusCode:    domain.StatusNotFound,
+				StatusMessage: "no such user",
+				Duration:      1500 * time.Microsecond,
+			})
+			_ = w.Close()
+			out, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if lines := strings.Split(strings.TrimSpace(string(out)), "\n"); len(lines) != 1 {
+				t.Fatalf("got %d lines, want 1:\n%s", len(lines), out)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(string(out), want) {
+					t.Errorf("expected %s in the log line, got:\n%s", want, out)
+				}
+			}
+			if tt.format == scope.LogFormatJSON && !json.Valid(out) {
+				t.Errorf("log line is not valid JSON:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestScope_WithPersistPath(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	start := func(opts ...scope.Option) *scope.Scope {
+		t.Helper()
+		s, err := scope.New(append([]scope.Option{
+			scope.WithPort(0),
+			scope.WithBufferSize(2),
+			scope.WithPersistPath(path),
+		}, opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	}
+	lines := func() int {
+		t.Helper()
+		b, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Count(string(b), "\n")
+	}
+
+	first := start()
+	for range 3 {
+		first.Publish(domain.CallEvent{ID: first.GenerateID(), Method: "/test.Service/Before"})
+	}
+	first.Close()
+	if got := lines(); got != 2 {
+		t.Fatalf("got %d persisted events, want the 2 the buffer holds", got)
+	}
+
+	var published domain.CallEvent
+	second := start(scope.WithProcessor(func(ev *domain.CallEvent) { published = *ev }))
+	conn, err := grpc.NewClient(second.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	ctx, cancel := context.WithCancel(t.Context())
+	stream, err := scopev1.NewScopeServiceClient(conn).Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"call-2", "call-3"} {
+		resp, err := stream.Recv()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := resp.GetEvent().GetId(); got != want {
+			t.Errorf("got restored event %s, want %s", got, want)
+		}
+	}
+
+	second.Publish(domain.CallEvent{ID: second.GenerateID()})
+	if published.ID != "call-4" || published.Seq != 4 {
+		t.Errorf("got ID %s and Seq %d after restart, want call-4 and 4", published.ID, published.Seq)
+	}
+	cancel() // Close waits for open Watch streams
+	second.Close()
+	if got := lines(); got != 3 {
+		t.Fatalf("got %d persisted events, want only the new one appended", got)
+	}
+
+	start().Close()
+	if got := lines(); got != 2 {
+		t.Errorf("got %d persisted events after reload, want the file compacted to 2", got)
+	}
+}
+
+func TestScope_GenerateID_Concurrent(t *testing.T) {
+	t.Parallel()
+
+	s, err := scope.New(scope.WithPort(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	const workers, perWorker = 16, 500
+	ids := make(chan string, workers*perWorker)
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range perWorker {
+				ids <- s.GenerateID()
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]struct{}, workers*perWorker)
+	for id := range ids {
+		if _, ok := seen[id]; ok {
+			t.Fatalf("duplicate ID %q", id)
+		}
+		seen[id] = struct{}{}
+	}
+	if len(seen) != workers*perWorker {
+		t.Errorf("got %d IDs, want %d", len(seen), workers*perWorker)
+	}
+}
+
+func TestScope_Publish_Seq(t *testing.T) {
+	t.Parallel()
+
+	var seqs []uint64
+	s, err := scope.New(scope.WithPort(0), scope.WithProcessor(func(ev *domain.CallEvent) {
+		seqs = append(seqs, ev.Seq)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	for range 3 {
+		s.Publish(domain.CallEvent{ID: s.GenerateID()})
+	}
+	if !slices.Equal(seqs, []uint64{1, 2, 3}) {
+		t.Errorf("got sequence numbers %v, want [1 2 3]", seqs)
+	}
+}
+
+func TestScope_Subscribe(t *testing.T) {
+	t.Parallel()
+
+	s, err := scope.New(scope.WithPort(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	ch, unsubscribe := s.Subscribe()
+	if got := s.SubscriberCount(); got != 1 {
+		t.Errorf("got %d subscribers, want 1", got)
+	}
+	s.Publish(domain.CallEvent{ID: "call-1", Method: "/test.v1.Test/Get", StatusCode: domain.StatusOK})
+
+	select {
+	case ev := <-ch:
+		if ev.ID != "call-1" || ev.Method != "/test.v1.Test/Get" || ev.Seq != 1 {
+			t.Errorf("got event %+v, want call-1 of /test.v1.Test/Get with seq 1", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for the event")
+	}
+
+	unsubscribe()
+	if got := s.SubscriberCount(); got != 0 {
+		t.Errorf("got %d subscribers after unsubscribing, want 0", got)
+	}
+	if _, ok := <-ch; ok {
+		t.Error("expected the channel to be closed after unsubscribing")
+	}
+}
+
+func TestScope_WithDisableServer(t *testing.T) {
+	t.Parallel()
+
+	// Both scopes would listen on the default port if the server were started.
+	for range 2 {
+		s, err := scope.New(scope.WithDisableServer(), scope.WithHTTPPort(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(s.Close)
+		if s.Addr() != nil || s.HTTPAddr() != nil {
+			t.Errorf("got addresses %v and %v, want nil", s.Addr(), s.HTTPAddr())
+		}
+
+		ch, unsubscribe := s.Subscribe()
+		s.Publish(domain.CallEvent{ID: "call-1"})
+		select {
+		case ev := <-ch:
+			if ev.ID != "call-1" {
+				t.Errorf("got event %q, want call-1", ev.ID)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for the event")
+		}
+		unsubscribe()
+	}
+}
+
+func TestScope_Handler(t *testing.T) {
+	t.Parallel()
+
+	s, err := scope.New(scope.WithDisableServer(), scope.WithAppTarget("localhost:8080"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The application's own plaintext HTTP/2 server, serving both itself and
+	// the scope server.
+	mux := http.NewServeMux()
+	mux.HandleFunc("/hello", func(w http.ResponseWriter, _ *http.Request) { _, _ = io.WriteString(w, "hello") })
+	mux.Handle(scope.HandlerPath, s.Handler())
+	srv := &http.Server{Handler: mux, Protocols: new(http.Protocols), ReadHeaderTimeout: time.Second}
+	srv.Protocols.SetHTTP1(true)
+	srv.Protocols.SetUnencryptedHTTP2(true)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	conn, err := grpc.NewClient(lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	client := scopev1.NewScopeServiceClient(conn)
+
+	info, err := client.ServerInfo(t.Context(), &scopev1.ServerInfoRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.GetAppTarget() != "localhost:8080" {
+		t.Errorf("got app target %q, want localhost:8080", info.GetAppTarget())
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	stream, err := client.Watch(ctx, &scopev1.WatchRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for s.SubscriberCount() == 0 {
+		time.Sleep(5 * time.Millisecond)
+	}
+	s.Publish(domain.CallEvent{ID: "call-1"})
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.GetEvent().GetId(); got != "call-1" {
+		t.Errorf("got event %q, want call-1", got)
+	}
+	cancel()
+	s.Close()
+
+	hello, err := http.Get("http://" + lis.Addr().String() + "/hello")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hello.Body.Close()
+	if body, _ := io.ReadAll(hello.Body); string(body) != "hello" {
+		t.Errorf("got %q from the application's route, want hello", body)
+	}
+}
+
+func TestScope_Handler_RejectsNonGRPC(t *testing.T) {
+	t.Parallel()
+
+	s, err := scope.New(scope.WithDisableServer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	mux := http.NewServeMux()
+	mux.Handle(scope.HandlerPath, s.Handler())
+	srv := &http.Server{Handler: mux, Protocols: new(http.Protocols), ReadHeaderTimeout: time.Second}
+	srv.Protocols.SetHTTP1(true)
+	srv.Protocols.SetUnencryptedHTTP2(true)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	h1 := &http.Transport{Protocols: new(http.Protocols)}
+	h1.Protocols.SetHTTP1(true)
+	h2c := &http.Transport{Protocols: new(http.Protocols)}
+	h2c.Protocols.SetUnencryptedHTTP2(true)
+	t.Cleanup(h1.CloseIdleConnections)
+	t.Cleanup(h2c.CloseIdleConnections)
+
+	tests := []struct {
+		name        string
+		transport   *http.Transport
+		contentType string
+		want        int
+	}{
+		{name: "gRPC over HTTP/1.1", transport: h1, contentType: "application/grpc", want: http.StatusHTTPVersionNotSupported},
+		{name: "Connect over HTTP/1.1", transport: h1, contentType: "application/json", want: http.StatusUnsupportedMediaType},
+		{name: "Connect over h2c", transport: h2c, contentType: "application/json", want: http.StatusUnsupportedMediaType},
+		{name: "gRPC-Web over h2c", transport: h2c, contentType: "application/grpc-web+proto", want: http.StatusUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			url := "http://" + lis.Addr().String() + scope.HandlerPath + "ServerInfo"
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, url, strings.NewReader("{}"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", tt.contentType)
+			resp, err := (&http.Client{Transport: tt.transport}).Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestScope_WithIDFormat(t *testing.T) {
+	t.Parallel()
+
+	var ids []string
+	s, err := scope.New(
+		scope.WithPort(0),
+		scope.WithIDFormat(func(seq uint64, ev *domain.CallEvent) string {
+			return fmt.Sprintf("%s#%d", path.Base(ev.Method), seq)
+		}),
+		scope.WithProcessor(func(ev *domain.CallEvent) { ids = append(ids, ev.ID) }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	s.Publish(domain.CallEvent{ID: s.GenerateID(), Method: "/test.v1.Test/Get"})
+	s.Publish(domain.CallEvent{ID: s.GenerateID(), Method: "/test.v1.Test/List"})
+	if want := []string{"Get#1", "List#2"}; !slices.Equal(ids, want) {
+		t.Errorf("got IDs %v, want %v", ids, want)
+	}
+}
+
+func TestScope_WithRuntimeStats(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		enabled bool
+	}{
+		{name: "enabled", enabled: true},
+		{name: "disabled", enabled: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got domain.CallEvent
+			s, err := scope.New(
+				scope.WithPort(0),
+				scope.WithRuntimeStats(tt.enabled),
+				scope.WithProcessor(func(ev *domain.CallEvent) { got = *ev }),
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(s.Close)
+
+			s.Publish(domain.CallEvent{ID: s.GenerateID()})
+			if tt.enabled && got.Goroutines <= 0 {
+				t.Errorf("got %d goroutines, want a positive count", got.Goroutines)
+			}
+			if !tt.enabled && got.Goroutines != 0 {
+				t.Errorf("got %d goroutines, want 0 when disabled", got.Goroutines)
+			}
+		})
+	}
+}
+
+func TestStreamRecorder_Payload(t *testing.T) {
+	t.Parallel()
+
+	s, err := scope.New(scope.WithPort(0), scope.WithMaxPayloadSize(64))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Close)
+
+	if got := s.NewStreamRecorder().Payload(); got != "" {
+		t.Errorf("got %q for an empty stream, want empty", got)
+	}
+
+	rec := s.NewStreamRecorder()
+	rec.Record(&scopev1.CallEvent{Id: "small"})
+	rec.Record(&scopev1.CallEvent{Id: strings.Repeat("x", 100)})
+	for range 200 {
+		rec.Record(&scopev1.CallEvent{Id: "more"})
+	}
+
+	var got []json.RawMessage
+	if err := json.Unmarshal([]byte(rec.Payload()), &got); err != nil {
+		t.Fatalf("payload is not a JSON array: %v", err)
+	}
+	// 100 recorded messages plus the elision marker.
+	if len(got) != 101 {
+		t.Fatalf("got %d elements, want 101", len(got))
+	}
+	if string(got[0]) != `{"id":"small"}` {
+		t.Errorf("got first element %s, want the message", got[0])
+	}
+	if !strings.HasPrefix(string(got[1]), `"<payload omitted`) {
+		t.Errorf("got second element %s, want an omitted-payload string", got[1])
+	}
+	if string(got[100]) != `"... 102 more elided"` {
+		t.Errorf("got marker %s, want \"... 102 more elided\"", got[100])
+	}
+}
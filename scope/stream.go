@@ -0,0 +1,67 @@
+package scope
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// maxStreamMessages caps how many messages of a stream are kept for its payload.
+const maxStreamMessages = 100
+
+// StreamRecorder collects the messages sent on a stream so they can be
+// published as a single JSON array payload. It is safe for concurrent use.
+type StreamRecorder struct {
+	s       *Scope
+	mu      sync.Mutex
+	msgs    []string
+	dropped int
+}
+
+// NewStreamRecorder returns a StreamRecorder that marshals messages with s.
+func (s *Scope) NewStreamRecorder() *StreamRecorder {
+	return &StreamRecorder{s: s}
+}
+
+// Record marshals v and appends it to the recorded messages. Messages past
+// the cap are only counted.
+func (r *StreamRecorder) Record(v any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.msgs) >= maxStreamMessages {
+		r.dropped++
+		return
+	}
+	r.msgs = append(r.msgs, r.s.Marshal(v))
+}
+
+// Payload returns the recorded messages as a JSON array, or "" if none were
+// recorded. Messages past the cap are replaced by a "... N more elided" marker.
+func (r *StreamRecorder) Payload() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.msgs) == 0 {
+		return ""
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i, m := range r.msgs {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		if json.Valid([]byte(m)) {
+			buf.WriteString(m)
+		} else {
+			// e.g. the placeholder for an omitted payload
+			writeJSONString(&buf, m)
+		}
+	}
+	if r.dropped > 0 {
+		buf.WriteByte(',')
+		writeJSONString(&buf, fmt.Sprintf("... %d more elided", r.dropped))
+	}
+	buf.WriteByte(']')
+	return buf.String()
+}
@@ -0,0 +1,37 @@
+package scope
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type suppressKey struct{}
+
+// Suppress marks the call ctx belongs to as not to be captured, e.g. while a
+// handler processes secrets. A handler can call it at any point: the server
+// interceptor checks the mark only once the handler returns. Outbound calls
+// made with ctx after Suppress are not captured either. Outside a call seen
+// by a server interceptor, Suppress does nothing.
+func Suppress(ctx context.Context) {
+	if mark, ok := ctx.Value(suppressKey{}).(*atomic.Bool); ok {
+		mark.Store(true)
+	}
+}
+
+// Suppressed reports whether Suppress has been called for the call ctx
+// belongs to.
+func Suppressed(ctx context.Context) bool {
+	mark, ok := ctx.Value(suppressKey{}).(*atomic.Bool)
+	return ok && mark.Load()
+}
+
+// Suppressible returns a context in which Suppress takes effect. Server
+// interceptors pass it to the handler and skip the call if Suppressed
+// reports true afterwards. A ctx that is already suppressible is returned
+// as is, so nested interceptors share one mark.
+func Suppressible(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(suppressKey{}).(*atomic.Bool); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, suppressKey{}, new(atomic.Bool))
+}
@@ -0,0 +1,38 @@
+package session
+
+import (
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"google.golang.org/grpc"
+)
+
+// Service is a read-only ScopeService that plays a loaded session to every
+// Watch stream and then idles until the client disconnects, so any monitor
+// can watch a saved session as if it were a live application.
+type Service struct {
+	scopev1.UnimplementedScopeServiceServer
+	events []*scopev1.CallEvent
+}
+
+// NewService returns a Service playing events, in the given order.
+func NewService(events []*scopev1.CallEvent) *Service {
+	return &Service{events: events}
+}
+
+// Watch sends the whole session, batched as the request asks, then waits for
+// the stream to end.
+func (s *Service) Watch(req *scopev1.WatchRequest, stream grpc.ServerStreamingServer[scopev1.WatchResponse]) error {
+	size := max(int(req.GetBatchSize()), 1)
+	for start := 0; start < len(s.events); start += size {
+		batch := s.events[start:min(start+size, len(s.events))]
+		resp := &scopev1.WatchResponse{Events: batch}
+		if size == 1 {
+			resp = &scopev1.WatchResponse{Event: batch[0]}
+		}
+		if err := stream.Send(resp); err != nil {
+			return err
+		}
+	}
+
+	<-stream.Context().Done()
+	return stream.Context().Err()
+}
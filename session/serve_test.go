@@ -0,0 +1,105 @@
+package session_test
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"github.com/mickamy/grpc-scope/session"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/proto"
+)
+
+func startService(t *testing.T, events []*scopev1.CallEvent) scopev1.ScopeServiceClient {
+	t.Helper()
+
+	srv := grpc.NewServer()
+	scopev1.RegisterScopeServiceServer(srv, session.NewService(events))
+
+	lis, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		if err := srv.Serve(lis); err != nil {
+			// server stopped
+		}
+	}()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient(
+		lis.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return scopev1.NewScopeServiceClient(conn)
+}
+
+func TestService_Watch(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	var written []*scopev1.CallEvent
+	for i := range 5 {
+		written = append(written, newEvent(fmt.Sprintf("call-%d", i+1), start.Add(time.Duration(i)*time.Second)))
+	}
+	var buf bytes.Buffer
+	if err := session.Write(&buf, written); err != nil {
+		t.Fatal(err)
+	}
+	events, err := session.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := startService(t, events)
+
+	tests := []struct {
+		name      string
+		req       *scopev1.WatchRequest
+		wantSends int
+	}{
+		{name: "unbatched", req: &scopev1.WatchRequest{}, wantSends: 5},
+		{name: "batched", req: &scopev1.WatchRequest{BatchSize: 2}, wantSends: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			stream, err := client.Watch(t.Context(), tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []*scopev1.CallEvent
+			for sends := 0; sends < tt.wantSends; sends++ {
+				resp, err := stream.Recv()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if ev := resp.GetEvent(); ev != nil {
+					got = append(got, ev)
+				}
+				got = append(got, resp.GetEvents()...)
+			}
+
+			if len(got) != len(written) {
+				t.Fatalf("got %d events, want %d", len(got), len(written))
+			}
+			for i := range written {
+				if !proto.Equal(got[i], written[i]) {
+					t.Errorf("event %d: got %v, want %v", i, got[i], written[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,150 @@
+// Package session reads and writes captured call events as shareable files,
+// and serves loaded sessions to monitors.
+//
+// A session file is JSON Lines: one protojson-encoded CallEvent per line.
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// maxLineSize bounds a single event line when reading, large enough for
+// payloads at gRPC's default 4 MiB message size.
+const maxLineSize = 16 << 20
+
+// Option configures Write.
+type Option func(*options)
+
+type options struct {
+	anonymize bool
+}
+
+// WithAnonymize makes Write replace timestamps with offsets from the earliest
+// event and event IDs with stable sequential ones, so a session can be shared
+// without leaking when or how much traffic was captured. Event order is kept.
+func WithAnonymize() Option {
+	return func(o *options) {
+		o.anonymize = true
+	}
+}
+
+// Write writes events to w in the given order.
+func Write(w io.Writer, events []*scopev1.CallEvent, opts ...Option) error {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.anonymize {
+		events = anonymize(events)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, ev := range events {
+		raw, err := protojson.Marshal(ev)
+		if err != nil {
+			return fmt.Errorf("session: marshal event %q: %w", ev.GetId(), err)
+		}
+		// protojson output is deliberately unstable; compact it so the same
+		// events always produce the same file.
+		var line bytes.Buffer
+		if err := json.Compact(&line, raw); err != nil {
+			return fmt.Errorf("session: compact event %q: %w", ev.GetId(), err)
+		}
+		line.WriteByte('\n')
+		if _, err := bw.Write(line.Bytes()); err != nil {
+			return fmt.Errorf("session: write: %w", err)
+		}
+	}
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("session: write: %w", err)
+	}
+	return nil
+}
+
+// Read parses events written by Write, in file order. Blank lines are skipped.
+func Read(r io.Reader) ([]*scopev1.CallEvent, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, maxLineSize)
+
+	var events []*scopev1.CallEvent
+	for n := 1; sc.Scan(); n++ {
+		line := bytes.TrimSpace(sc.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		ev := new(scopev1.CallEvent)
+		if err := protojson.Unmarshal(line, ev); err != nil {
+			return nil, fmt.Errorf("session: line %d: %w", n, err)
+		}
+		events = append(events, ev)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("session: read: %w", err)
+	}
+	return events, nil
+}
+
+// anonymize returns copies of events with start times and deadlines
+// rewritten as offsets from the earliest start time (encoded from the Unix
+// epoch) and IDs replaced by "call-N", numbered by start time. Events sharing
+// an ID keep sharing it. Sequence numbers are renumbered from 1 keeping their
+// order; unset ones stay unset.
+func anonymize(events []*scopev1.CallEvent) []*scopev1.CallEvent {
+	var base time.Time
+	for _, ev := range events {
+		if t := ev.GetStartTime(); t != nil && (base.IsZero() || t.AsTime().Before(base)) {
+			base = t.AsTime()
+		}
+	}
+	relative := func(ts *timestamppb.Timestamp) *timestamppb.Timestamp {
+		if ts == nil {
+			return nil
+		}
+		return timestamppb.New(time.Unix(0, 0).Add(ts.AsTime().Sub(base)))
+	}
+
+	ordered := make([]*scopev1.CallEvent, len(events))
+	copy(ordered, events)
+	slices.SortStableFunc(ordered, func(a, b *scopev1.CallEvent) int {
+		return a.GetStartTime().AsTime().Compare(b.GetStartTime().AsTime())
+	})
+	ids := make(map[string]string, len(events))
+	for _, ev := range ordered {
+		if _, ok := ids[ev.GetId()]; !ok {
+			ids[ev.GetId()] = fmt.Sprintf("call-%d", len(ids)+1)
+		}
+	}
+
+	var seqs []uint64
+	for _, ev := range events {
+		if ev.GetSeq() != 0 {
+			seqs = append(seqs, ev.GetSeq())
+		}
+	}
+	slices.Sort(seqs)
+	seqs = slices.Compact(seqs)
+
+	out := make([]*scopev1.CallEvent, len(events))
+	for i, ev := range events {
+		c := proto.Clone(ev).(*scopev1.CallEvent)
+		c.Id = ids[ev.GetId()]
+		if n, ok := slices.BinarySearch(seqs, ev.GetSeq()); ok {
+			c.Seq = uint64(n + 1)
+		}
+		c.StartTime = relative(ev.GetStartTime())
+		c.Deadline = relative(ev.GetDeadline())
+		out[i] = c
+	}
+	return out
+}
@@ -0,0 +1,131 @@
+package session_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"github.com/mickamy/grpc-scope/session"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func newEvent(id string, start time.Time) *scopev1.CallEvent {
+	return &scopev1.CallEvent{
+		Id:             id,
+		Method:         "/test.v1.Test/Get",
+		StartTime:      timestamppb.New(start),
+		StatusCode:     1, // domain.StatusOK
+		RequestPayload: `{"id":"123"}`,
+	}
+}
+
+func TestWrite_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	events := []*scopev1.CallEvent{
+		newEvent("call-41", start),
+		newEvent("call-42", start.Add(time.Second)),
+	}
+
+	var buf bytes.Buffer
+	if err := session.Write(&buf, events); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Count(buf.String(), "\n"); got != len(events) {
+		t.Errorf("got %d lines, want %d", got, len(events))
+	}
+
+	got, err := session.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(events) {
+		t.Fatalf("got %d events, want %d", len(got), len(events))
+	}
+	for i := range events {
+		if !proto.Equal(got[i], events[i]) {
+			t.Errorf("event %d: got %v, want %v", i, got[i], events[i])
+		}
+	}
+}
+
+func TestWrite_Anonymize(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	// Newest first, as the monitor lists them.
+	events := []*scopev1.CallEvent{
+		newEvent("call-907", start.Add(1500*time.Millisecond)),
+		newEvent("call-905", start.Add(250*time.Millisecond)),
+		newEvent("call-903", start),
+	}
+	events[1].Deadline = timestamppb.New(start.Add(time.Second))
+	for i, ev := range events {
+		ev.Seq = uint64(9000 - i*2)
+	}
+
+	var buf bytes.Buffer
+	if err := session.Write(&buf, events, session.WithAnonymize()); err != nil {
+		t.Fatal(err)
+	}
+	exported := buf.String()
+	if strings.Contains(exported, "2026") || strings.Contains(exported, "call-90") {
+		t.Errorf("expected no real timestamps or IDs, got:\n%s", exported)
+	}
+
+	got, err := session.Read(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id     string
+		seq    uint64
+		offset time.Duration
+	}{
+		{id: "call-3", seq: 3, offset: 1500 * time.Millisecond},
+		{id: "call-2", seq: 2, offset: 250 * time.Millisecond},
+		{id: "call-1", seq: 1, offset: 0},
+	}
+	for i, tt := range tests {
+		if got[i].GetId() != tt.id {
+			t.Errorf("event %d: got ID %q, want %q", i, got[i].GetId(), tt.id)
+		}
+		if got[i].GetSeq() != tt.seq {
+			t.Errorf("event %d: got seq %d, want %d", i, got[i].GetSeq(), tt.seq)
+		}
+		if off := got[i].GetStartTime().AsTime().Sub(time.Unix(0, 0)); off != tt.offset {
+			t.Errorf("event %d: got start offset %s, want %s", i, off, tt.offset)
+		}
+		if got[i].GetRequestPayload() != `{"id":"123"}` {
+			t.Errorf("event %d: got payload %q, want it unchanged", i, got[i].GetRequestPayload())
+		}
+	}
+	if off := got[1].GetDeadline().AsTime().Sub(time.Unix(0, 0)); off != time.Second {
+		t.Errorf("got deadline offset %s, want %s", off, time.Second)
+	}
+	if events[0].GetId() != "call-907" {
+		t.Error("expected Write to leave the input events unmodified")
+	}
+
+	var again bytes.Buffer
+	if err := session.Write(&again, events, session.WithAnonymize()); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != exported {
+		t.Error("expected anonymized exports to be deterministic")
+	}
+}
+
+func TestRead_InvalidLine(t *testing.T) {
+	t.Parallel()
+
+	_, err := session.Read(strings.NewReader("{\"id\":\"a\"}\nnot json\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Fatalf("got error %v, want error mentioning line 2", err)
+	}
+}
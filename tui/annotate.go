@@ -0,0 +1,180 @@
+package tui
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// jsonNotePrefix starts a line prettyJSON adds to explain the line above it.
+// Notes are for display only; copy, export and replay use the raw payload.
+const jsonNotePrefix = "// "
+
+const (
+	bytesPreviewLen  = 8  // bytes shown in hex
+	bytesPreviewText = 24 // runes shown when the bytes are text
+)
+
+// jsonScope is the object or array that the lines of indented JSON being
+// annotated are members of. Exactly one of its fields is set for a scope
+// whose members the schema describes; none is set for one it does not.
+type jsonScope struct {
+	msg  protoreflect.MessageDescriptor // an object holding msg's fields
+	list protoreflect.FieldDescriptor   // an array holding list's values
+	dict protoreflect.FieldDescriptor   // an object holding dict's entries
+}
+
+// annotateJSON inserts a note after each line of indented JSON holding a
+// bytes field of md, giving its decoded length and a preview, and after each
+// object holding a map field, giving its size. Fields are looked up in md, so
+// a nil md, as when the method's schema could not be resolved, adds no
+// notes.
+func annotateJSON(lines []string, md protoreflect.MessageDescriptor) []string {
+	if md == nil {
+		return lines
+	}
+	var (
+		out   []string
+		stack []jsonScope
+	)
+	for i, line := range lines {
+		out = append(out, line)
+		t := strings.TrimSuffix(strings.TrimSpace(line), ",")
+		if t == "}" || t == "]" {
+			stack = stack[:max(len(stack)-1, 0)]
+			continue
+		}
+		if len(stack) == 0 {
+			if t == "{" {
+				stack = append(stack, jsonScope{msg: md})
+			}
+			continue
+		}
+
+		fd, value, ok := stack[len(stack)-1].member(t)
+		element := stack[len(stack)-1].msg == nil
+		indent := strings.Repeat(" ", len(line)-len(strings.TrimLeft(line, " ")))
+		switch {
+		case value == "{" && ok && fd.IsMap() && !element:
+			stack = append(stack, jsonScope{dict: fd})
+			out = append(out, indent+"  "+jsonNotePrefix+fmt.Sprintf("map, %d entries", countMembers(lines[i+1:], len(indent))))
+		case value == "{" && ok && fd.Message() != nil && (element || !fd.IsList() && !fd.IsMap()):
+			stack = append(stack, jsonScope{msg: fd.Message()})
+		case value == "[" && ok && fd.IsList() && !element:
+			stack = append(stack, jsonScope{list: fd})
+		case value == "{" || value == "[":
+			stack = append(stack, jsonScope{})
+		case ok && fd.Kind() == protoreflect.BytesKind && (element || !fd.IsList()):
+			if b, ok := bytesValue(value); ok {
+				out = append(out, indent+jsonNotePrefix+describeBytes(b))
+			}
+		}
+	}
+	return out
+}
+
+// member returns the field describing t, a member line of s with any
+// trailing comma removed, and the member's value. For the elements of an
+// array and the values of a map the field is the repeated or map field
+// itself; ok is false when the schema does not describe the member.
+func (s jsonScope) member(t string) (fd protoreflect.FieldDescriptor, value string, ok bool) {
+	if s.list != nil {
+		return s.list, t, true
+	}
+	key, value, found := splitMember(t)
+	if !found {
+		return nil, "", false
+	}
+	switch {
+	case s.dict != nil:
+		return s.dict.MapValue(), value, true
+	case s.msg != nil:
+		fields := s.msg.Fields()
+		fd = fields.ByJSONName(key)
+		if fd == nil {
+			fd = fields.ByTextName(key)
+		}
+		return fd, value, fd != nil
+	}
+	return nil, value, false
+}
+
+// splitMember splits t, an object member line of indented JSON, into its
+// unquoted key and its value.
+func splitMember(t string) (key, value string, ok bool) {
+	if !strings.HasPrefix(t, `"`) {
+		return "", "", false
+	}
+	end := scanJSONString(t, 0)
+	if !isJSONKey(t, end) {
+		return "", "", false
+	}
+	key, err := strconv.Unquote(t[:end])
+	if err != nil {
+		return "", "", false
+	}
+	return key, strings.TrimSpace(strings.TrimPrefix(t[end:], ":")), true
+}
+
+// bytesValue decodes v, the JSON string protojson encodes a bytes value as.
+func bytesValue(v string) ([]byte, bool) {
+	if !strings.HasPrefix(v, `"`) {
+		return nil, false
+	}
+	s, err := strconv.Unquote(v)
+	if err != nil {
+		return nil, false
+	}
+	for _, enc := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if b, err := enc.DecodeString(s); err == nil {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// describeBytes gives the length of b and a preview: quoted when b is
+// printable text, in hex otherwise.
+func describeBytes(b []byte) string {
+	desc := fmt.Sprintf("%d bytes", len(b))
+	if len(b) == 0 {
+		return desc
+	}
+	if utf8.Valid(b) && strings.IndexFunc(string(b), func(r rune) bool { return !unicode.IsPrint(r) }) < 0 {
+		runes := []rune(string(b))
+		if len(runes) > bytesPreviewText {
+			return desc + ": " + strconv.Quote(string(runes[:bytesPreviewText])) + "..."
+		}
+		return desc + ": " + strconv.Quote(string(b))
+	}
+	hex := make([]string, 0, bytesPreviewLen)
+	for _, c := range b[:min(len(b), bytesPreviewLen)] {
+		hex = append(hex, fmt.Sprintf("%02x", c))
+	}
+	desc += ": " + strings.Join(hex, " ")
+	if len(b) > bytesPreviewLen {
+		desc += " ..."
+	}
+	return desc
+}
+
+// countMembers counts the members of the object whose lines follow,
+// indented by indent+2.
+func countMembers(lines []string, indent int) int {
+	n := 0
+	for _, line := range lines {
+		depth := len(line) - len(strings.TrimLeft(line, " "))
+		if depth <= indent {
+			break
+		}
+		if depth == indent+2 {
+			n++
+		}
+	}
+	return n
+}
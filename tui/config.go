@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// DisplayConfig holds the [display] settings of a config file.
+type DisplayConfig struct {
+	TimeFormat string // time.Format layout of start times; empty keeps the default
+	UTC        bool   // show start times in UTC
+}
+
+// ParseDisplayConfig reads display settings from the [display] table of a
+// TOML config file:
+//
+//	[display]
+//	time_format = "2006-01-02 15:04:05.000"
+//	utc = true
+//
+// Other tables are ignored.
+func ParseDisplayConfig(r io.Reader) (DisplayConfig, error) {
+	var cfg DisplayConfig
+	err := scanTable(r, "display", func(name, value string) error {
+		var err error
+		switch name {
+		case "time_format":
+			cfg.TimeFormat, err = parseString(value)
+		case "utc":
+			cfg.UTC, err = parseBool(value)
+		default:
+			return fmt.Errorf("unknown setting %q", name)
+		}
+		return err
+	})
+	if err != nil {
+		return DisplayConfig{}, err
+	}
+	return cfg, nil
+}
+
+// scanTable calls fn with the name and raw value of each entry of table in
+// a TOML config file. Errors are prefixed with the line they occur on.
+func scanTable(r io.Reader, table string, fn func(name, value string) error) error {
+	current := ""
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+		if current != table {
+			continue
+		}
+
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return fmt.Errorf("line %d: expected name = value", n)
+		}
+		if err := fn(strings.TrimSpace(name), strings.TrimSpace(value)); err != nil {
+			return fmt.Errorf("line %d: %w", n, err)
+		}
+	}
+	return sc.Err()
+}
+
+// parseString parses a TOML string, ignoring a trailing comment.
+func parseString(value string) (string, error) {
+	s, err := strconv.QuotedPrefix(value)
+	if err != nil {
+		return "", fmt.Errorf("expected a string, got %s", value)
+	}
+	if rest := strings.TrimSpace(value[len(s):]); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected %s after string", rest)
+	}
+	out, _ := strconv.Unquote(s)
+	return out, nil
+}
+
+// parseBool parses a TOML boolean, ignoring a trailing comment.
+func parseBool(value string) (bool, error) {
+	value, _, _ = strings.Cut(value, "#")
+	switch strings.TrimSpace(value) {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+	return false, fmt.Errorf("expected true or false, got %s", value)
+}
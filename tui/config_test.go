@@ -0,0 +1,72 @@
+package tui_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mickamy/grpc-scope/tui"
+)
+
+func TestParseDisplayConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		in      string
+		want    tui.DisplayConfig
+		wantErr bool
+	}{
+		{
+			name: "all settings",
+			in: `[keys]
+replay = "R"
+
+[display]
+time_format = "2006-01-02 15:04:05.000" # match the server logs
+utc = true
+`,
+			want: tui.DisplayConfig{TimeFormat: "2006-01-02 15:04:05.000", UTC: true},
+		},
+		{name: "empty", in: "", want: tui.DisplayConfig{}},
+		{name: "unknown setting", in: "[display]\ntheme = \"dark\"\n", wantErr: true},
+		{name: "unquoted format", in: "[display]\ntime_format = 15:04\n", wantErr: true},
+		{name: "non-bool utc", in: "[display]\nutc = \"yes\"\n", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tui.ParseDisplayConfig(strings.NewReader(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseDisplayConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ParseDisplayConfig() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidTimeLayout(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		layout string
+		want   bool
+	}{
+		{layout: tui.DefaultTimeLayout, want: true},
+		{layout: "2006-01-02T15:04:05.000Z07:00", want: true},
+		{layout: "15:04:05.000000", want: true},
+		{layout: "hh:mm:ss", want: false},
+		{layout: "", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tui.ValidTimeLayout(tt.layout); got != tt.want {
+				t.Errorf("ValidTimeLayout(%q) = %v, want %v", tt.layout, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// diffOp marks a line of a diff as kept, removed from the original, or added
+// by the replay.
+type diffOp byte
+
+const (
+	diffKeep   diffOp = ' '
+	diffRemove diffOp = '-'
+	diffAdd    diffOp = '+'
+)
+
+type diffLine struct {
+	op   diffOp
+	text string
+}
+
+// normalizeJSON indents s with object keys sorted, so responses that differ
+// only in key order or spacing compare equal. Input that is not JSON is
+// split into lines as is.
+func normalizeJSON(s string) []string {
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return strings.Split(s, "\n")
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return strings.Split(s, "\n")
+	}
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+// diffLines returns a line diff turning a into b, built from their longest
+// common subsequence. Lines shared at both ends are matched up front to keep
+// the table small for the usual case of a few changed fields.
+func diffLines(a, b []string) []diffLine {
+	var prefix, suffix []diffLine
+	for len(a) > 0 && len(b) > 0 && a[0] == b[0] {
+		prefix = append(prefix, diffLine{diffKeep, a[0]})
+		a, b = a[1:], b[1:]
+	}
+	for len(a) > 0 && len(b) > 0 && a[len(a)-1] == b[len(b)-1] {
+		suffix = append([]diffLine{{diffKeep, a[len(a)-1]}}, suffix...)
+		a, b = a[:len(a)-1], b[:len(b)-1]
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := prefix
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			lines = append(lines, diffLine{diffKeep, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			lines = append(lines, diffLine{diffRemove, a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{diffAdd, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		lines = append(lines, diffLine{diffRemove, a[i]})
+	}
+	for ; j < len(b); j++ {
+		lines = append(lines, diffLine{diffAdd, b[j]})
+	}
+	return append(lines, suffix...)
+}
+
+// renderResponseDiff shows how the replayed response differs from the one
+// originally captured, with removed lines in red and added lines in green.
+func renderResponseDiff(original, replayed string, width int) string {
+	var b strings.Builder
+	b.WriteString(labelStyle.Render("Response diff: "))
+	switch {
+	case original == "" && replayed == "":
+		b.WriteString(helpStyle.Render("neither the original call nor the replay has a response"))
+		return b.String()
+	case original == "":
+		b.WriteString(helpStyle.Render("the original response was not captured"))
+		return b.String()
+	case replayed == "":
+		b.WriteString(helpStyle.Render("the replay returned no response"))
+		return b.String()
+	}
+
+	lines := diffLines(normalizeJSON(original), normalizeJSON(replayed))
+	changed := false
+	for _, l := range lines {
+		if l.op != diffKeep {
+			changed = true
+			break
+		}
+	}
+	if !changed {
+		b.WriteString(successStyle.Render("unchanged"))
+		return b.String()
+	}
+	b.WriteString(helpStyle.Render("- original  + replay"))
+	for _, l := range lines {
+		line := string(l.op) + " " + l.text
+		if width > 3 {
+			line = truncate(line, width)
+		}
+		b.WriteString("\n")
+		switch l.op {
+		case diffRemove:
+			b.WriteString(errorStyle.Render(line))
+		case diffAdd:
+			b.WriteString(successStyle.Render(line))
+		case diffKeep:
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
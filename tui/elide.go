@@ -0,0 +1,109 @@
+package tui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// elideArrays rewrites the JSON document s so that every array keeps at most
+// n elements, for display only; replay always sends the captured payload. Dropped elements are replaced by a single marker string such as
+// "... 42 more elided", so the output remains valid JSON and object keys keep
+// their original order. If s is not valid JSON it is returned unchanged.
+func elideArrays(s string, n int) string {
+	if n <= 0 || !strings.ContainsRune(s, '[') {
+		return s
+	}
+
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+
+	var buf bytes.Buffer
+	if err := writeElided(dec, &buf, n); err != nil {
+		return s
+	}
+	if _, err := dec.Token(); err == nil {
+		// trailing data after the top-level value
+		return s
+	}
+	return buf.String()
+}
+
+func writeElided(dec *json.Decoder, buf *bytes.Buffer, n int) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			buf.WriteByte('{')
+			for i := 0; dec.More(); i++ {
+				key, err := dec.Token()
+				if err != nil {
+					return err
+				}
+				if i > 0 {
+					buf.WriteByte(',')
+				}
+				writeJSONString(buf, fmt.Sprint(key))
+				buf.WriteByte(':')
+				if err := writeElided(dec, buf, n); err != nil {
+					return err
+				}
+			}
+			buf.WriteByte('}')
+		case '[':
+			buf.WriteByte('[')
+			count := 0
+			for ; dec.More(); count++ {
+				if count >= n {
+					var skip json.RawMessage
+					if err := dec.Decode(&skip); err != nil {
+						return err
+					}
+					continue
+				}
+				if count > 0 {
+					buf.WriteByte(',')
+				}
+				if err := writeElided(dec, buf, n); err != nil {
+					return err
+				}
+			}
+			if count > n {
+				buf.WriteByte(',')
+				writeJSONString(buf, fmt.Sprintf("... %d more elided", count-n))
+			}
+			buf.WriteByte(']')
+		default:
+			return fmt.Errorf("unexpected delimiter %q", t)
+		}
+		// consume the closing delimiter
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	case string:
+		writeJSONString(buf, t)
+	case json.Number:
+		buf.WriteString(t.String())
+	case bool:
+		fmt.Fprintf(buf, "%t", t)
+	case nil:
+		buf.WriteString("null")
+	default:
+		return fmt.Errorf("unexpected token %v", t)
+	}
+	return nil
+}
+
+func writeJSONString(buf *bytes.Buffer, s string) {
+	enc := json.NewEncoder(buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	// Encode appends a newline.
+	buf.Truncate(buf.Len() - 1)
+}
@@ -0,0 +1,53 @@
+package tui_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/mickamy/grpc-scope/tui"
+)
+
+func TestElideArrays(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{
+			name: "long array is elided",
+			in:   `{"values":["v0","v1","v2","v3","v4"]}`,
+			want: `{"values":["v0","v1","v2","... 2 more elided"]}`,
+		},
+		{
+			name: "short array is kept",
+			in:   `{"values":["v0","v1","v2"]}`,
+			want: `{"values":["v0","v1","v2"]}`,
+		},
+		{
+			name: "nested arrays keep key order",
+			in:   `{"a":[[1,2,3,4],2,3,4,5],"b":"<tag>"}`,
+			want: `{"a":[[1,2,3,"... 1 more elided"],2,3,"... 2 more elided"],"b":"<tag>"}`,
+		},
+		{
+			name: "not json",
+			in:   `[1,2,3,4`,
+			want: `[1,2,3,4`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got := tui.ElideArrays(tt.in, 3)
+			if json.Valid([]byte(tt.in)) && !json.Valid([]byte(got)) {
+				t.Fatalf("ElideArrays() produced invalid JSON: %s", got)
+			}
+			if got != tt.want {
+				t.Errorf("ElideArrays() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
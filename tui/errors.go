@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mickamy/grpc-scope/scope/domain"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+)
+
+// maxErrorRows is the number of recent errors shown in the errors panel.
+const maxErrorRows = 5
+
+// recentErrors returns up to limit of the newest failed calls. It ignores the
+// list filters, so failures stay visible while browsing other methods.
+func (m Model) recentErrors(limit int) []*scopev1.CallEvent {
+	var out []*scopev1.CallEvent
+	for _, ev := range m.events {
+		if domain.StatusCode(ev.GetStatusCode()) == domain.StatusOK {
+			continue
+		}
+		out = append(out, ev)
+		if len(out) == limit {
+			break
+		}
+	}
+	return out
+}
+
+func (m Model) countErrors() int {
+	n := 0
+	for _, ev := range m.events {
+		if domain.StatusCode(ev.GetStatusCode()) != domain.StatusOK {
+			n++
+		}
+	}
+	return n
+}
+
+func (m Model) renderErrors(maxRows int) string {
+	errs := m.recentErrors(maxRows)
+	lines := make([]string, 0, len(errs))
+	width := m.width - 6 // border(2) + padding(2) + margin(2)
+	for _, ev := range errs {
+		line := fmt.Sprintf("%s %s %s", m.formatTime(ev.GetStartTime()), domain.StatusCode(ev.GetStatusCode()), ev.GetMethod())
+		if msg := ev.GetStatusMessage(); msg != "" {
+			line += fmt.Sprintf(" (%s)", msg)
+		}
+		if n := len(ev.GetStatusDetails()); n == 1 {
+			line += " [1 detail]"
+		} else if n > 1 {
+			line += fmt.Sprintf(" [%d details]", n)
+		}
+		if width > 8 {
+			line = truncate(line, width)
+		}
+		lines = append(lines, errorStyle.Render(line))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, helpStyle.Render("No errors."))
+	}
+
+	title := fmt.Sprintf(" Errors (%d) ", m.countErrors())
+	return borderStyle.Width(m.width - 2).Render(title + "\n" + strings.Join(lines, "\n"))
+}
@@ -0,0 +1,90 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mickamy/grpc-scope/replay"
+	"github.com/mickamy/grpc-scope/scope/domain"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"github.com/muesli/termenv"
+	"google.golang.org/protobuf/proto"
+)
+
+// appTargetPlaceholder is used in exported commands when no app address was given.
+const appTargetPlaceholder = "<app-addr>"
+
+// grpcurlResolveTimeout bounds resolving a method to re-encode its payload.
+const grpcurlResolveTimeout = 5 * time.Second
+
+// grpcurlCommand builds a grpcurl invocation that reproduces the given event
+// against appTarget. Metadata is filtered the same way replay filters it.
+func grpcurlCommand(ev *scopev1.CallEvent, appTarget string) string {
+	if appTarget == "" {
+		appTarget = appTargetPlaceholder
+	}
+
+	parts := []string{"grpcurl", "-plaintext"}
+
+	md := domain.Metadata(replay.FilterMetadata(metadataFromEvent(ev)))
+	for _, k := range md.Keys() {
+		for _, v := range md[k] {
+			parts = append(parts, "-H", shellQuote(fmt.Sprintf("%s: %s", k, v)))
+		}
+	}
+
+	if payload := ev.GetRequestPayload(); payload != "" {
+		parts = append(parts, "-d", shellQuote(payload))
+	}
+
+	parts = append(parts, appTarget, strings.TrimPrefix(ev.GetMethod(), "/"))
+	return strings.Join(parts, " ")
+}
+
+// grpcurlMsg carries a grpcurl command whose payload was re-encoded in the
+// background. err is set if that failed and the captured payload was kept.
+type grpcurlMsg struct {
+	cmd string
+	err error
+}
+
+// copyGrpcurl copies a grpcurl command reproducing ev. Captured payloads use
+// lowerCamelCase JSON names, which grpcurl accepts; with WithProtoNames the
+// payload is first re-encoded with proto field names, which needs the
+// method's descriptor from the replay client.
+func (m Model) copyGrpcurl(ev *scopev1.CallEvent) (Model, tea.Cmd) {
+	if !m.protoNames || m.replayClient == nil || ev.GetRequestPayload() == "" {
+		cmd := grpcurlCommand(ev, m.appTarget)
+		m.status = "Copied: " + cmd
+		return m, copyToClipboard(cmd)
+	}
+
+	client, appTarget := m.replayClient, m.appTarget
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), grpcurlResolveTimeout)
+		defer cancel()
+		payload, err := client.RequestJSON(ctx, ev.GetMethod(), ev.GetRequestPayload(), true)
+		if err != nil {
+			return grpcurlMsg{cmd: grpcurlCommand(ev, appTarget), err: err}
+		}
+		renamed := proto.CloneOf(ev)
+		renamed.RequestPayload = payload
+		return grpcurlMsg{cmd: grpcurlCommand(renamed, appTarget)}
+	}
+}
+
+// shellQuote wraps s in single quotes so it can be pasted into a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// copyToClipboard writes s to the system clipboard using the OSC 52 escape sequence.
+func copyToClipboard(s string) tea.Cmd {
+	return func() tea.Msg {
+		termenv.Copy(s)
+		return nil
+	}
+}
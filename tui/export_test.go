@@ -0,0 +1,70 @@
+package tui
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mickamy/grpc-scope/replay"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var HighlightJSON = highlightJSON
+
+var GrpcurlCommand = grpcurlCommand
+
+var ElideArrays = elideArrays
+
+var (
+	NewEditorEnvelope   = newEditorEnvelope
+	ParseEditorEnvelope = parseEditorEnvelope
+)
+
+// WithExportDir writes session exports to dir instead of the working directory.
+func WithExportDir(dir string) Option {
+	return func(m *Model) {
+		m.exportDir = dir
+	}
+}
+
+var ReconnectDelay = reconnectDelay
+
+// Connected marks m as having established a Watch stream, as a successful
+// connect would.
+func Connected(m Model) Model {
+	m.connected = true
+	return m
+}
+
+// LivenessMsg returns the message the liveness check sends at now.
+func LivenessMsg(now time.Time) tea.Msg {
+	return livenessMsg{now: now}
+}
+
+// AppTargetMsg returns the message sent when the scope server reports the
+// application address target.
+func AppTargetMsg(target string) tea.Msg {
+	return serverInfoMsg{info: &scopev1.ServerInfoResponse{AppTarget: target}}
+}
+
+// ServerInfoMsg returns the message sent when the scope server reports info.
+func ServerInfoMsg(info *scopev1.ServerInfoResponse) tea.Msg {
+	return serverInfoMsg{info: info}
+}
+
+// IdempotencyMsg returns the message sent once the idempotency level of a
+// replayed method is resolved.
+func IdempotencyMsg(method, payload string, level replay.Idempotency) tea.Msg {
+	return idempotencyMsg{replay: pendingReplay{method: method, payload: payload, level: level}}
+}
+
+// ReplayRequest returns the request a resend from the replay view sends.
+func ReplayRequest(m Model) replay.Request {
+	return m.replayRequest()
+}
+
+// SchemaMsg returns the message sent once the payload schema of method is
+// resolved.
+func SchemaMsg(method string, request, response protoreflect.MessageDescriptor) tea.Msg {
+	return schemaMsg{method: method, schema: methodSchema{request: request, response: response}}
+}
@@ -0,0 +1,64 @@
+package tui
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+)
+
+// matchesMethodFilter reports whether ev matches the method filter typed
+// after /, as MatchesFilter does.
+func (m Model) matchesMethodFilter(ev *scopev1.CallEvent) bool {
+	return MatchesFilter(m.methodFilter, ev)
+}
+
+// MatchesFilter reports whether ev's method contains filter, ignoring case.
+// A filter of the form key=value instead matches events whose key label
+// contains value. An empty filter matches every event.
+func MatchesFilter(filter string, ev *scopev1.CallEvent) bool {
+	if filter == "" {
+		return true
+	}
+	if key, value, ok := strings.Cut(filter, "="); ok {
+		label, found := ev.GetLabels()[key]
+		return found && strings.Contains(strings.ToLower(label), strings.ToLower(value))
+	}
+	return strings.Contains(strings.ToLower(ev.GetMethod()), strings.ToLower(filter))
+}
+
+// handleFilterKey edits the method filter while it is being typed. The list
+// updates as the user types; enter keeps the filter and esc clears it.
+func (m Model) handleFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	selected := m.selectedEvent()
+
+	switch msg.Type {
+	case tea.KeyEnter:
+		m.editingFilter = false
+		return m, nil
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.editingFilter = false
+		m.methodFilter = ""
+	case tea.KeyBackspace:
+		if m.methodFilter != "" {
+			runes := []rune(m.methodFilter)
+			m.methodFilter = string(runes[:len(runes)-1])
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		m.methodFilter += string(msg.Runes)
+	default:
+		return m, nil
+	}
+	return m.reselect(selected), nil
+}
+
+// formatLabels renders labels as key=value pairs in sorted key order.
+func formatLabels(labels map[string]string) string {
+	pairs := make([]string, 0, len(labels))
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, k+"="+labels[k])
+	}
+	return strings.Join(pairs, "  ")
+}
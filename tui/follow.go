@@ -0,0 +1,30 @@
+package tui
+
+// toggleFollow switches follow mode, in which the newest visible call stays
+// selected as events arrive. Turning it on selects the newest call at once.
+func (m Model) toggleFollow() Model {
+	m.following = !m.following
+	if m.following {
+		return m.selectNewest()
+	}
+	return m
+}
+
+// selectNewest selects the newest visible call: the top of the list, or its
+// row in the tree.
+func (m Model) selectNewest() Model {
+	if !m.grouped {
+		m.cursor = 0
+		return m
+	}
+	if visible := m.visibleEvents(); len(visible) > 0 {
+		return m.selectTreeEvent(visible[0])
+	}
+	return m
+}
+
+// unfollow ends follow mode, as any manual navigation of the list does.
+func (m Model) unfollow() Model {
+	m.following = false
+	return m
+}
@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	jsonKeyStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("4"))
+	jsonStringStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	jsonNumberStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("5"))
+	jsonBoolStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	jsonNullStyle   = lipgloss.NewStyle().Faint(true)
+	jsonNoteStyle   = lipgloss.NewStyle().Faint(true).Italic(true)
+)
+
+// highlightJSON colorizes the keys, strings, numbers, booleans and nulls of
+// JSON produced by prettyJSON, and dims its notes. Lines truncated or wrapped
+// by prettyJSON are handled; anything that does not look like JSON is returned unchanged.
+// Coloring is disabled when the NO_COLOR environment variable is set.
+func highlightJSON(s string) string {
+	if s == "" || os.Getenv("NO_COLOR") != "" {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			b.WriteByte(c)
+			i++
+		case strings.IndexByte("{}[]:,", c) >= 0:
+			b.WriteByte(c)
+			i++
+		case c == '"':
+			end := scanJSONString(s, i)
+			style := jsonStringStyle
+			if isJSONKey(s, end) {
+				style = jsonKeyStyle
+			}
+			writeStyled(&b, style, s[i:end])
+			i = end
+		case c == '-' || (c >= '0' && c <= '9'):
+			end := i + 1
+			for end < len(s) && strings.IndexByte("0123456789.eE+-", s[end]) >= 0 {
+				end++
+			}
+			writeStyled(&b, jsonNumberStyle, s[i:end])
+			i = end
+		case strings.HasPrefix(s[i:], "true"):
+			writeStyled(&b, jsonBoolStyle, "true")
+			i += len("true")
+		case strings.HasPrefix(s[i:], "false"):
+			writeStyled(&b, jsonBoolStyle, "false")
+			i += len("false")
+		case strings.HasPrefix(s[i:], "null"):
+			writeStyled(&b, jsonNullStyle, "null")
+			i += len("null")
+		case strings.HasPrefix(s[i:], jsonNotePrefix):
+			// note added by annotateJSON, up to the end of the line
+			end := strings.IndexByte(s[i:], '\n')
+			if end < 0 {
+				end = len(s) - i
+			}
+			writeStyled(&b, jsonNoteStyle, s[i:i+end])
+			i += end
+		case strings.HasPrefix(s[i:], "..."):
+			// truncation marker added by prettyJSON
+			b.WriteString("...")
+			i += len("...")
+		default:
+			return s
+		}
+	}
+	return b.String()
+}
+
+// scanJSONString returns the index just past the string literal starting at
+// s[start]. A string cut short by truncation ends at the end of its line;
+// a string split by wrapping continues onto the next line.
+func scanJSONString(s string, start int) int {
+	for i := start + 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			return i + 1
+		case '\n':
+			if strings.HasSuffix(s[:i], "...") {
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// isJSONKey reports whether the next non-space character after end is a colon.
+func isJSONKey(s string, end int) bool {
+	rest := strings.TrimLeft(s[end:], " \t")
+	return strings.HasPrefix(rest, ":")
+}
+
+// writeStyled renders each line of a token separately so that lipgloss does
+// not pad multi-line tokens to a common width.
+func writeStyled(b *strings.Builder, style lipgloss.Style, token string) {
+	for i, line := range strings.Split(token, "\n") {
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		if line != "" {
+			b.WriteString(style.Render(line))
+		}
+	}
+}
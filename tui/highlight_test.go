@@ -0,0 +1,77 @@
+package tui_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/mickamy/grpc-scope/tui"
+	"github.com/muesli/termenv"
+)
+
+func TestHighlightJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{name: "object", in: "{\n  \"name\": \"grpc\",\n  \"count\": -1.5e3,\n  \"ok\": true,\n  \"off\": false,\n  \"none\": null\n}"},
+		{name: "escaped quote", in: `{"msg": "say \"hi\""}`},
+		{name: "truncated", in: "{\n  \"long\": \"abcdef...\n  ..."},
+		{name: "wrapped", in: "{\n  \"long\": \"abc\ndef\"\n}"},
+		{name: "note", in: "{\n  \"data\": \"AAEC\",\n  // 3 bytes: 00 01 02\n}"},
+		{name: "not json", in: "hello world"},
+		{name: "empty", in: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := ansi.Strip(tui.HighlightJSON(tt.in)); got != tt.in {
+				t.Errorf("HighlightJSON() text = %q, want %q", got, tt.in)
+			}
+		})
+	}
+}
+
+func TestHighlightJSON_DistinctStyles(t *testing.T) {
+	// Not parallel: the color profile is global.
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+
+	got := tui.HighlightJSON("{\n  \"count\": 42\n}")
+	key, number := sgrBefore(got, `"count"`), sgrBefore(got, "42")
+	if key == "" || number == "" {
+		t.Fatalf("HighlightJSON() = %q, want styled key and number", got)
+	}
+	if key == number {
+		t.Errorf("key and number share style %q, want distinct styles", key)
+	}
+}
+
+// sgrBefore returns the escape sequence that styles the first occurrence of
+// token in s, or "" if it is not styled.
+func sgrBefore(s, token string) string {
+	i := strings.Index(s, token)
+	if i < 0 {
+		return ""
+	}
+	start := strings.LastIndex(s[:i], "\x1b[")
+	if start < 0 || !strings.HasSuffix(s[:i], "m") {
+		return ""
+	}
+	return s[start:i]
+}
+
+func TestHighlightJSON_NoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+
+	in := `{"key": "value"}`
+	if got := tui.HighlightJSON(in); got != in {
+		t.Errorf("HighlightJSON() = %q, want %q", got, in)
+	}
+}
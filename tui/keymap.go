@@ -0,0 +1,223 @@
+package tui
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Action names a rebindable command of the monitor.
+type Action string
+
+// Rebindable actions. Keys of every other command are fixed.
+const (
+	ActionUp     Action = "up"
+	ActionDown   Action = "down"
+	ActionReplay Action = "replay"
+	ActionEdit   Action = "edit"
+	ActionQuit   Action = "quit"
+	ActionSearch Action = "search"
+)
+
+// KeyMap maps actions to the keys that trigger them, in the key names
+// Bubble Tea uses, e.g. "k", "up", "ctrl+r".
+type KeyMap map[Action][]string
+
+// DefaultKeyMap returns the bindings used when no key map is configured.
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		ActionUp:     {"up", "k"},
+		ActionDown:   {"down", "j"},
+		ActionReplay: {"r"},
+		ActionEdit:   {"e"},
+		ActionQuit:   {"q"},
+		ActionSearch: {"/"},
+	}
+}
+
+// fixedKeys are the keys of the commands that cannot be rebound, as runKey
+// dispatches on them. A key map may not take them over.
+var fixedKeys = []string{
+	"ctrl+c", "esc", "enter", " ", ":", "ctrl+p", "ctrl+l", "@",
+	"0", "1", "2", "3", "4", "5", "6", "7", "8", "9",
+	"b", "c", "d", "E", "f", "g", "L", "s", "t", "T", "w", "W", "x", "y", "z",
+}
+
+// WithKeyMap rebinds the actions in km. Actions it leaves out keep their
+// default keys; a rebound action no longer answers to its default keys.
+// ctrl+c always quits.
+func WithKeyMap(km KeyMap) Option {
+	return func(m *Model) {
+		m.keys = newKeyBindings(km)
+	}
+}
+
+// keyBindings translates pressed keys into the default keys handleKey
+// dispatches on.
+type keyBindings struct {
+	bound  map[string]string // pressed key -> default key of its action
+	hidden map[string]bool   // default keys of rebound actions
+	custom KeyMap
+}
+
+func newKeyBindings(km KeyMap) keyBindings {
+	k := keyBindings{
+		bound:  make(map[string]string),
+		hidden: make(map[string]bool),
+		custom: km,
+	}
+	defaults := DefaultKeyMap()
+	for action, keys := range km {
+		def, ok := defaults[action]
+		if !ok {
+			continue
+		}
+		for _, key := range def {
+			k.hidden[key] = true
+		}
+		for _, key := range keys {
+			k.bound[key] = def[0]
+		}
+	}
+	return k
+}
+
+// resolve returns the default key that pressed stands for, or "" if pressed
+// was a default key of an action that has been rebound.
+func (k keyBindings) resolve(pressed string) string {
+	if key, ok := k.bound[pressed]; ok {
+		return key
+	}
+	if k.hidden[pressed] {
+		return ""
+	}
+	return pressed
+}
+
+// label returns how to show the keys of action in help text: def, the
+// default label, unless the action has been rebound.
+func (k keyBindings) label(action Action, def string) string {
+	if keys, ok := k.custom[action]; ok && len(keys) > 0 {
+		return strings.Join(keys, "/")
+	}
+	return def
+}
+
+// keyLabel returns how to show the default key key in help text: the keys
+// of its action if that has been rebound, or key itself.
+func (k keyBindings) keyLabel(key string) string {
+	for action, def := range DefaultKeyMap() {
+		if def[0] == key {
+			return k.label(action, key)
+		}
+	}
+	return key
+}
+
+// ParseKeyMap reads key bindings from the [keys] table of a TOML config
+// file. Each entry maps an action to a key or an array of keys:
+//
+//	[keys]
+//	up = ["k", "up"]
+//	replay = "R"
+//
+// Other tables are ignored, so the file can hold future settings. A key may
+// trigger only one action, and may not be a key of a fixed command or a
+// default key of an action left unbound.
+func ParseKeyMap(r io.Reader) (KeyMap, error) {
+	defaults := DefaultKeyMap()
+	km := KeyMap{}
+	err := scanTable(r, "keys", func(name, value string) error {
+		action := Action(name)
+		if _, ok := defaults[action]; !ok {
+			return fmt.Errorf("unknown action %q", action)
+		}
+		keys, err := parseKeys(value)
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("no keys for %q", action)
+		}
+		km[action] = keys
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := checkKeyClashes(km); err != nil {
+		return nil, err
+	}
+	return km, nil
+}
+
+// checkKeyClashes reports a key of km that would take over the key of
+// another command: a fixed one, another action of km, or an action km leaves
+// on its default keys.
+func checkKeyClashes(km KeyMap) error {
+	owner := make(map[string]string)
+	for _, key := range fixedKeys {
+		owner[key] = "a fixed command"
+	}
+	for action, keys := range DefaultKeyMap() {
+		if _, ok := km[action]; !ok {
+			for _, key := range keys {
+				owner[key] = strconv.Quote(string(action))
+			}
+		}
+	}
+	for _, action := range slices.Sorted(maps.Keys(km)) {
+		name := strconv.Quote(string(action))
+		for _, key := range km[action] {
+			if other, ok := owner[key]; ok && other != name {
+				return fmt.Errorf("key %q of %s is already bound to %s", key, name, other)
+			}
+			owner[key] = name
+		}
+	}
+	return nil
+}
+
+// parseKeys parses a TOML string or array of strings, ignoring a trailing
+// comment.
+func parseKeys(value string) ([]string, error) {
+	array := strings.HasPrefix(value, "[")
+	if array {
+		value = strings.TrimSpace(value[1:])
+	}
+
+	var keys []string
+	for {
+		if !strings.HasPrefix(value, `"`) {
+			break
+		}
+		s, err := strconv.QuotedPrefix(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid string %s", value)
+		}
+		key, _ := strconv.Unquote(s)
+		if key == "" {
+			return nil, fmt.Errorf("empty key")
+		}
+		keys = append(keys, key)
+		value = strings.TrimSpace(value[len(s):])
+		if !array {
+			break
+		}
+		value = strings.TrimSpace(strings.TrimPrefix(value, ","))
+	}
+
+	if array {
+		if !strings.HasPrefix(value, "]") {
+			return nil, fmt.Errorf("expected ] to close array")
+		}
+		value = strings.TrimSpace(value[1:])
+	}
+	if value != "" && !strings.HasPrefix(value, "#") {
+		return nil, fmt.Errorf("expected a string or array of strings")
+	}
+	return keys, nil
+}
@@ -0,0 +1,112 @@
+package tui_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mickamy/grpc-scope/tui"
+)
+
+func TestParseKeyMap(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		in      string
+		want    tui.KeyMap
+		wantErr bool
+	}{
+		{
+			name: "strings and arrays",
+			in: `# grpc-scope config
+[keys]
+up = ["i", "up"]   # Dvorak-friendly
+down = [ "n" , "down", ]
+replay = "R"
+
+[ui]
+theme = "dark"
+`,
+			want: tui.KeyMap{
+				tui.ActionUp:     {"i", "up"},
+				tui.ActionDown:   {"n", "down"},
+				tui.ActionReplay: {"R"},
+			},
+		},
+		{name: "empty", in: "", want: tui.KeyMap{}},
+		{name: "unknown action", in: "[keys]\nfly = \"f\"\n", wantErr: true},
+		{name: "unquoted key", in: "[keys]\nreplay = R\n", wantErr: true},
+		{name: "unclosed array", in: "[keys]\nup = [\"k\"\n", wantErr: true},
+		{name: "no keys", in: "[keys]\nup = []\n", wantErr: true},
+		{name: "missing value", in: "[keys]\nup\n", wantErr: true},
+		{name: "fixed key", in: "[keys]\nreplay = \"w\"\n", wantErr: true},
+		{name: "key of another rebound action", in: "[keys]\nup = \"i\"\ndown = [\"n\", \"i\"]\n", wantErr: true},
+		{name: "default key of an unbound action", in: "[keys]\nup = \"r\"\n", wantErr: true},
+		{
+			name: "default key freed by rebinding",
+			in:   "[keys]\nreplay = \"R\"\nedit = \"r\"\n",
+			want: tui.KeyMap{tui.ActionReplay: {"R"}, tui.ActionEdit: {"r"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := tui.ParseKeyMap(strings.NewReader(tt.in))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseKeyMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseKeyMap() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestModel_WithKeyMap(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "", tui.WithKeyMap(tui.KeyMap{
+		tui.ActionUp:   {"i"},
+		tui.ActionQuit: {"x"},
+	}))
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	m = updated.(tui.Model)
+	for i := range 2 {
+		ev := newTestEvent(string(rune('a'+i)), "/test.v1.Test/Method"+string(rune('A'+i)), 1)
+		updated, _ = m.Update(tui.EventMsg{Event: ev})
+		m = updated.(tui.Model)
+	}
+	press := func(key string) tea.Cmd {
+		updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = updated.(tui.Model)
+		return cmd
+	}
+
+	// Events are listed newest first: [B, A], with A selected.
+	press("k")
+	if view := m.View(); !strings.Contains(view, "Method: /test.v1.Test/MethodA") {
+		t.Errorf("expected the rebound default key to do nothing, got:\n%s", view)
+	}
+	press("i")
+	if view := m.View(); !strings.Contains(view, "Method: /test.v1.Test/MethodB") {
+		t.Errorf("expected the custom key to move up, got:\n%s", view)
+	}
+	press("j")
+	if view := m.View(); !strings.Contains(view, "Method: /test.v1.Test/MethodA") {
+		t.Errorf("expected default keys of other actions to still work, got:\n%s", view)
+	}
+
+	if view := m.View(); !strings.Contains(view, "x: quit") || !strings.Contains(view, "j/↓/i: navigate") {
+		t.Errorf("expected the help bar to show the custom keys, got:\n%s", view)
+	}
+
+	if cmd := press("q"); cmd != nil {
+		t.Error("expected q to no longer quit")
+	}
+	if cmd := press("x"); cmd == nil {
+		t.Error("expected x to quit")
+	}
+}
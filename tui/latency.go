@@ -0,0 +1,65 @@
+package tui
+
+import (
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+)
+
+// Default latency thresholds of the list's Latency column: calls faster
+// than DefaultLatencyWarn are green, those at DefaultLatencyCritical or
+// slower red, and those in between yellow.
+const (
+	DefaultLatencyWarn     = 50 * time.Millisecond
+	DefaultLatencyCritical = 500 * time.Millisecond
+)
+
+var (
+	latencyFastStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	latencyWarnStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
+	latencyCritStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+)
+
+// WithLatencyThresholds sets the latencies from which the list colors a call
+// yellow (warn) and red (critical). A non-positive threshold keeps its
+// default.
+func WithLatencyThresholds(warn, critical time.Duration) Option {
+	return func(m *Model) {
+		if warn > 0 {
+			m.latencyWarn = warn
+		}
+		if critical > 0 {
+			m.latencyCritical = critical
+		}
+	}
+}
+
+// latencyStyle returns the style of latency d in the list.
+func (m Model) latencyStyle(d time.Duration) lipgloss.Style {
+	switch {
+	case d >= m.latencyCritical:
+		return latencyCritStyle
+	case d >= m.latencyWarn:
+		return latencyWarnStyle
+	default:
+		return latencyFastStyle
+	}
+}
+
+// latencyCell formats the Latency column of ev, padded to width. Only the
+// text is colored, and only if styled is set: a row drawn in a single style,
+// such as the selected or a failed one, keeps its own color throughout.
+func (m Model) latencyCell(ev *scopev1.CallEvent, width int, styled bool) string {
+	if ev.GetDuration() == nil {
+		return strings.Repeat(" ", width)
+	}
+	d := ev.GetDuration().AsDuration()
+	text := d.String()
+	pad := strings.Repeat(" ", max(width-len(text), 0))
+	if !styled {
+		return text + pad
+	}
+	return m.latencyStyle(d).Render(text) + pad
+}
@@ -0,0 +1,45 @@
+package tui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const (
+	// livenessInterval is how often the monitor checks the Watch stream.
+	livenessInterval = time.Second
+	// staleHeartbeats is how many heartbeat intervals a stream that sends
+	// heartbeats may stay silent before it is flagged.
+	staleHeartbeats = 3
+	// defaultHeartbeatInterval is the scope server's default, assumed until
+	// the server reports its own.
+	defaultHeartbeatInterval = 5 * time.Second
+)
+
+// livenessMsg is sent every livenessInterval to re-check the Watch stream.
+type livenessMsg struct {
+	now time.Time
+}
+
+func livenessTick() tea.Cmd {
+	return tea.Tick(livenessInterval, func(now time.Time) tea.Msg {
+		return livenessMsg{now: now}
+	})
+}
+
+// stale reports whether the Watch stream has gone quiet for longer than its
+// heartbeats allow. Streams from servers that never sent a heartbeat, such
+// as a replayed session, are never stale.
+func (m Model) stale() bool {
+	interval := m.heartbeatInterval
+	if interval <= 0 {
+		interval = defaultHeartbeatInterval
+	}
+	return m.heartbeats && !m.reconnecting && m.quietFor >= staleHeartbeats*interval
+}
+
+func (m Model) renderLastSeen() string {
+	return errorStyle.Render(fmt.Sprintf("⚠ last seen %s ago ", m.quietFor.Truncate(time.Second)))
+}
@@ -7,7 +7,10 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -17,7 +20,10 @@ import (
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
 )
 
 type viewMode int
@@ -25,12 +31,23 @@ type viewMode int
 const (
 	viewList viewMode = iota
 	viewReplay
+	viewStats
+	viewTimeline
 )
 
 // EventMsg is sent when a new call event is received from the Watch stream.
 type EventMsg struct {
-	Event  *scopev1.CallEvent
-	stream scopev1.ScopeService_WatchClient
+	Event   *scopev1.CallEvent
+	Dropped uint64 // events the server has dropped for this stream so far
+	stream  scopev1.ScopeService_WatchClient
+}
+
+// EventBatchMsg is sent when a batch of call events is received from a
+// batched Watch stream. Events are ordered oldest first.
+type EventBatchMsg struct {
+	Events  []*scopev1.CallEvent
+	Dropped uint64 // events the server has dropped for this stream so far
+	stream  scopev1.ScopeService_WatchClient
 }
 
 // ErrMsg is sent when the Watch stream encounters an error.
@@ -49,75 +66,252 @@ type ReplayResultMsg struct {
 	Result      *replay.Result
 	Method      string
 	RequestJSON string
+	RequestRaw  []byte              // wire-encoded request sent in place of RequestJSON, if captured
+	Metadata    map[string][]string // metadata sent with the call; nil means the event's
+	Event       *scopev1.CallEvent  // the replayed call, whose metadata, deadline and response are reused
 	Err         error
 }
 
 // EditorFinishedMsg is sent when the $EDITOR exits.
 type EditorFinishedMsg struct {
-	Payload string
-	Event   *scopev1.CallEvent
-	Err     error
+	Payload  string
+	Metadata map[string][]string
+	Event    *scopev1.CallEvent
+	Err      error
 }
 
 // Model is the Bubbletea model for the monitor TUI.
 type Model struct {
-	target       string
-	appTarget    string // application server address for replay (empty = disabled)
-	events       []*scopev1.CallEvent
-	cursor       int
-	width        int
-	height       int
-	err          error
-	conn         *grpc.ClientConn
-	cancel       context.CancelFunc
-	mode         viewMode
-	replayResult *replayResultView
-	replaying    bool
+	target             string
+	appTarget          string // application server address for replay (empty = disabled)
+	serverInfoQueries  int    // ServerInfo calls made on this connection; see queryServerInfo
+	serverInfoQuerying bool   // a ServerInfo call is in flight
+	replayClient       *replay.Client
+	replayOpts         []replay.Option
+	replayErr          error         // error creating replayClient, reported on replay
+	keepDeadline       bool          // replay with the original call's deadline
+	rotateKeys         []string      // metadata keys given a fresh UUID on every replay
+	replayAllow        []string      // method patterns replay is limited to; empty allows all
+	replayDeny         []string      // method patterns that may never be replayed
+	protoNames         bool          // copy grpcurl payloads with proto field names
+	batchSize          int           // events per WatchResponse; 0 or 1 disables batching
+	batchInterval      time.Duration // flush interval for batches; 0 uses the server default
+	token              string        // shared token sent to the scope server
+	exportDir          string        // directory for session exports; empty means the working directory
+	connected          bool          // a Watch stream has been established at least once
+	reconnecting       bool          // the Watch stream dropped and a reconnect is pending
+	reconnectAttempt   int           // reconnects tried since the stream last dropped
+	dropped            uint64        // events the server dropped because the TUI fell behind
+	droppedBase        uint64        // dropped count carried over from previous Watch streams
+	lastSeen           time.Time     // when the Watch stream last sent anything
+	quietFor           time.Duration // time since lastSeen as of the latest liveness check
+	heartbeats         bool          // the Watch stream has sent a heartbeat
+	heartbeatInterval  time.Duration // heartbeat interval the scope server reported; 0 if unknown
+	events             []*scopev1.CallEvent
+	cursor             int
+	width              int
+	height             int
+	err                error
+	conn               *grpc.ClientConn
+	cancel             context.CancelFunc
+	mode               viewMode
+	replayResult       *replayResultView
+	replaying          bool
+	status             string           // one-shot message shown in place of the help bar
+	errorsOnly         bool             // show only events with a non-OK status
+	directionFilter    domain.Direction // show only events in this direction; unspecified shows all
+	methodFilter       string           // show only events whose method contains this, or key=value label
+	editingFilter      bool             // typing into methodFilter
+	timeWindow         *timeWindow      // show only events started within this; nil shows all
+	timeWindowInput    string           // the time window as typed
+	editingTimeWindow  bool             // typing into timeWindowInput
+	showErrors         bool             // show the errors panel above the detail pane
+	statsSort          statsSort
+	maxStatsMethods    int // methods listed in stats before the rest are folded; <= 0 lists all
+	maxShown           int // array elements shown in payloads, as the scope server reports; 0 shows all
+	latencyWarn        time.Duration
+	latencyCritical    time.Duration
+	statsScroll        int
+	timelineIndex      int                      // index into timelineWindows
+	collapsed          [detailSectionCount]bool // detail sections folded to their label
+	wrapDetail         bool                     // wrap long JSON lines in the detail pane instead of truncating them
+	grouped            bool                     // list calls in a tree by service and method
+	following          bool                     // keep the newest call selected as events arrive
+	treeSelected       string                   // key of the selected tree row
+	treeToggled        map[string]bool          // tree nodes expanded or collapsed from their default
+	schemas            map[string]methodSchema  // payload schemas by method, for annotations
+	timeLayout         string                   // layout of start times; empty means DefaultTimeLayout
+	utc                bool                     // show start times in UTC instead of local time
+	confirmClear       bool                     // waiting for the user to confirm clearing events
+	confirmReplay      *pendingReplay           // a replay waiting for the user to confirm it
+	palette            *paletteState            // non-nil while the command palette is open
+	resendCount        string                   // digits typed in the replay view before r
+	burst              *resendBurst             // latest multi-resend, kept after it finishes
+	loadTest           *loadTestView            // latest load test, kept after it finishes
+	burstSeq           int
+	keys               keyBindings // user key bindings, applied in handleKey
 }
 
 type replayResultView struct {
 	method      string
 	requestJSON string
+	requestRaw  []byte              // captured wire bytes sent in place of requestJSON
+	metadata    map[string][]string // nil means event's metadata
+	event       *scopev1.CallEvent  // the replayed call, fixed when the replay starts
 	result      *replay.Result
 	err         error
-	scroll      int // scroll offset for viewing long content
-	totalLines  int // set during render for scroll bounds
+	scroll      int  // scroll offset for viewing long content
+	totalLines  int  // set during render for scroll bounds
+	showDiff    bool // show the response as a diff against the captured one
+}
+
+// Option configures a Model.
+type Option func(*Model)
+
+// WithReplayOptions sets the options used to create the replay client.
+func WithReplayOptions(opts ...replay.Option) Option {
+	return func(m *Model) {
+		m.replayOpts = append(m.replayOpts, opts...)
+	}
+}
+
+// WithOriginalDeadline makes replays use the timeout the original client set,
+// so a call that hit its deadline can be reproduced. Calls without a deadline
+// use the replay client's default timeout.
+func WithOriginalDeadline() Option {
+	return func(m *Model) {
+		m.keepDeadline = true
+	}
+}
+
+// WithRotatedMetadata makes every replay send a fresh UUID in each of the
+// given metadata keys, e.g. "idempotency-key", instead of the captured value,
+// so servers that deduplicate requests treat each resend as new.
+func WithRotatedMetadata(keys ...string) Option {
+	return func(m *Model) {
+		m.rotateKeys = append(m.rotateKeys, keys...)
+	}
+}
+
+// WithProtoNames makes copied grpcurl commands use proto field names, e.g.
+// "batch_size", instead of the lowerCamelCase JSON names payloads are
+// captured with. Both replay the same; this matches scripts that expect
+// proto names. It needs an app address to resolve the method.
+func WithProtoNames() Option {
+	return func(m *Model) {
+		m.protoNames = true
+	}
+}
+
+// WithWatchBatch asks the scope server to group up to size events into each
+// Watch response, flushing a partial batch after interval. This reduces
+// per-message overhead on busy servers. A size of 0 or 1 keeps single-event
+// delivery; a zero interval uses the server's default.
+func WithWatchBatch(size int, interval time.Duration) Option {
+	return func(m *Model) {
+		m.batchSize = size
+		m.batchInterval = interval
+	}
+}
+
+// WithAuthToken sends token in the x-scope-token metadata key when watching,
+// for scope servers started with WithAuthToken.
+func WithAuthToken(token string) Option {
+	return func(m *Model) {
+		m.token = token
+	}
 }
 
 // NewModel creates a new TUI model that connects to the given target address.
 // appTarget is the application server address for replay; empty disables replay.
-func NewModel(target, appTarget string) Model {
-	return Model{
-		target:    target,
-		appTarget: appTarget,
+func NewModel(target, appTarget string, opts ...Option) Model {
+	m := Model{
+		target:          target,
+		appTarget:       appTarget,
+		maxStatsMethods: DefaultMaxStatsMethods,
+		latencyWarn:     DefaultLatencyWarn,
+		latencyCritical: DefaultLatencyCritical,
+	}
+	for _, opt := range opts {
+		opt(&m)
 	}
+	if appTarget != "" {
+		// Reuse one client so reflection results are cached across replays.
+		m.replayClient, m.replayErr = replay.NewClient(appTarget, m.replayOpts...)
+	}
+	return m
 }
 
 func (m Model) Init() tea.Cmd {
-	return m.connect()
+	return tea.Batch(m.connect(), livenessTick())
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		return m.handleKey(msg)
+	case tea.MouseMsg:
+		return m.handleMouse(msg), nil
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 	case connectedMsg:
 		m.conn = msg.conn
-		return m, recvEvent(msg.stream)
+		m.connected = true
+		m.droppedBase = m.dropped
+		m.reconnecting = false
+		m.reconnectAttempt = 0
+		m.heartbeats = false
+		m.seen()
+		m.serverInfoQueries = 0
+		m, query := m.queryServerInfo()
+		return m, tea.Batch(recvEvent(msg.stream), query)
+	case reconnectMsg:
+		if !m.reconnecting {
+			return m, nil
+		}
+		return m, m.connect()
 	case EventMsg:
-		if !strings.HasPrefix(msg.Event.GetMethod(), "/grpc.reflection.") {
-			m.events = append(m.events, nil)
-			copy(m.events[1:], m.events)
-			m.events[0] = msg.Event
-			if len(m.events) > 1 {
-				m.cursor++
+		if msg.Event != nil {
+			m.addEvent(msg.Event)
+			if m.following {
+				m = m.selectNewest()
 			}
+		} else {
+			m.heartbeats = true
+		}
+		m.seen()
+		m.dropped = max(m.dropped, m.droppedBase+msg.Dropped)
+		m, query := m.queryAppTargetAfter(msg.Event)
+		m, resolve := m.resolveSchemas(msg.Event)
+		return m, tea.Batch(recvEvent(msg.stream), query, resolve)
+	case EventBatchMsg:
+		for _, ev := range msg.Events {
+			m.addEvent(ev)
+		}
+		if m.following {
+			m = m.selectNewest()
+		}
+		m.seen()
+		m.dropped = max(m.dropped, m.droppedBase+msg.Dropped)
+		m, query := m.queryAppTargetAfter(msg.Events...)
+		m, resolve := m.resolveSchemas(msg.Events...)
+		return m, tea.Batch(recvEvent(msg.stream), query, resolve)
+	case grpcurlMsg:
+		m.status = "Copied: " + msg.cmd
+		if msg.err != nil {
+			m.status = fmt.Sprintf("Copied with captured field names (%v): %s", msg.err, msg.cmd)
 		}
-		return m, recvEvent(msg.stream)
+		return m, copyToClipboard(msg.cmd)
+	case livenessMsg:
+		if !m.lastSeen.IsZero() {
+			m.quietFor = msg.now.Sub(m.lastSeen)
+		}
+		return m, livenessTick()
 	case ErrMsg:
+		if m.shouldReconnect(msg.Err) {
+			return m.scheduleReconnect()
+		}
 		m.err = msg.Err
 	case ReplayResultMsg:
 		m.replaying = false
@@ -125,30 +319,106 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.replayResult = &replayResultView{
 			method:      msg.Method,
 			requestJSON: msg.RequestJSON,
+			requestRaw:  msg.RequestRaw,
+			metadata:    msg.Metadata,
+			event:       msg.Event,
 			result:      msg.Result,
 			err:         msg.Err,
+			// A resend from the replay view keeps showing the diff.
+			showDiff: m.replayResult != nil && m.replayResult.showDiff,
 		}
+	case ResendProgressMsg:
+		return m.handleResendProgress(msg)
+	case LoadTestResultMsg:
+		return m.handleLoadTestResult(msg), nil
+	case sessionExportedMsg:
+		return m.handleSessionExported(msg), nil
+	case statsExportedMsg:
+		return m.handleStatsExported(msg), nil
+	case serverInfoMsg:
+		// A replay client created for the reported address resolves the
+		// schemas of the calls seen so far.
+		return m.handleServerInfo(msg).resolveSchemas(m.events...)
+	case schemaMsg:
+		return m.handleSchema(msg), nil
 	case EditorFinishedMsg:
 		if msg.Err != nil {
 			m.replaying = false
 			m.mode = viewReplay
 			m.replayResult = &replayResultView{
 				method: msg.Event.GetMethod(),
+				event:  msg.Event,
 				err:    msg.Err,
 			}
 			return m, nil
 		}
-		return m, m.doReplay(msg.Event, msg.Payload)
+		return m.checkReplay(pendingReplay{
+			method:   msg.Event.GetMethod(),
+			metadata: msg.Metadata,
+			payload:  msg.Payload,
+			event:    msg.Event,
+		})
+	case idempotencyMsg:
+		return m.handleIdempotency(msg)
 	}
 	return m, nil
 }
 
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
+	m.status = ""
+
+	if m.palette != nil {
+		return m.handlePaletteKey(msg)
+	}
+
+	if m.editingFilter {
+		return m.handleFilterKey(msg)
+	}
+
+	if m.editingTimeWindow {
+		return m.handleTimeWindowKey(msg)
+	}
+
+	if m.confirmClear {
+		m.confirmClear = false
+		if msg.String() == "y" {
+			return m.clearEvents(), nil
+		}
+		return m, nil
+	}
+
+	if p := m.confirmReplay; p != nil {
+		m.confirmReplay = nil
+		if msg.String() == "y" {
+			m.replaying = true
+			return m, m.doReplay(p.method, p.metadata, p.payload, p.raw, p.event)
+		}
+		return m, nil
+	}
+
+	key := msg.String()
+	if key != "ctrl+c" {
+		key = m.keys.resolve(key)
+	}
+	return m.runKey(key)
+}
+
+// runKey runs the command bound to key by default. The palette calls it
+// directly, so its commands work whatever the user's key bindings.
+func (m Model) runKey(key string) (tea.Model, tea.Cmd) {
+	switch key {
 	case "q", "ctrl+c":
 		if m.mode == viewReplay {
+			m = m.cancelResend().cancelLoadTest()
 			m.mode = viewList
 			m.replayResult = nil
+			m.resendCount = ""
+			m.burst = nil
+			m.loadTest = nil
+			return m, nil
+		}
+		if m.mode == viewStats || m.mode == viewTimeline {
+			m.mode = viewList
 			return m, nil
 		}
 		m.cleanup()
@@ -157,32 +427,246 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.navigateUp(), nil
 	case "down", "j":
 		return m.navigateDown(), nil
+	case "esc":
+		if m.mode == viewReplay {
+			m.resendCount = ""
+			return m.cancelResend().cancelLoadTest(), nil
+		}
+	case "0", "1", "2", "3", "4", "5", "6", "7", "8", "9":
+		if m.mode == viewReplay && m.appTarget != "" && !m.replaying && m.replayResult != nil {
+			return m.appendResendDigit(key), nil
+		}
+		if s, ok := detailSectionForKey(key); ok && m.mode == viewList {
+			m.collapsed[s] = !m.collapsed[s]
+		}
 	case "r":
 		if m.mode == viewReplay && m.appTarget != "" && !m.replaying && m.replayResult != nil {
+			n, _ := strconv.Atoi(m.resendCount)
+			m.resendCount = ""
+			if n > 1 {
+				return m.startResend(n)
+			}
 			m.replaying = true
-			ev := m.events[m.cursor]
-			return m, m.doReplay(ev, m.replayResult.requestJSON)
+			return m, m.doReplay(m.replayResult.method, m.replayResult.metadata, m.replayResult.requestJSON, m.replayResult.requestRaw, m.replayResult.event)
 		}
-		if m.canReplay() {
-			m.replaying = true
-			ev := m.events[m.cursor]
-			return m, m.doReplay(ev, ev.GetRequestPayload())
+		if m.replayable() {
+			ev := m.selectedEvent()
+			if reason := m.replayBlocked(ev.GetMethod()); reason != "" {
+				m.status = reason
+				return m, nil
+			}
+			return m.checkReplay(pendingReplay{
+				method:  ev.GetMethod(),
+				payload: ev.GetRequestPayload(),
+				raw:     ev.GetRequestBytesRaw(),
+				event:   ev,
+			})
+		}
+	case "L":
+		if m.mode == viewReplay && m.appTarget != "" && !m.replaying && m.replayResult != nil {
+			m.resendCount = ""
+			return m.startLoadTest()
 		}
 	case "e":
-		if m.canReplay() {
+		if m.replayable() {
+			ev := m.selectedEvent()
+			if reason := m.replayBlocked(ev.GetMethod()); reason != "" {
+				m.status = reason
+				return m, nil
+			}
 			m.replaying = true
-			ev := m.events[m.cursor]
 			return m, m.openEditor(ev)
 		}
+	case "y":
+		if ev := m.selectedEvent(); m.mode == viewList && ev != nil {
+			return m.copyGrpcurl(ev)
+		}
+	case "w", "W":
+		if m.mode == viewList && len(m.events) > 0 {
+			return m, m.exportSession(key == "W")
+		}
+		if m.mode == viewStats && key == "w" && len(m.events) > 0 {
+			return m, m.exportStats()
+		}
+	case "z":
+		if m.mode == viewList {
+			m.wrapDetail = !m.wrapDetail
+		}
+	case ":", "ctrl+p":
+		if m.mode == viewList {
+			m.palette = &paletteState{}
+		}
+	case "c", "ctrl+l":
+		if m.mode == viewList && len(m.events) > 0 {
+			m.confirmClear = true
+			m.status = fmt.Sprintf("Clear %d events? (y: confirm, any other key: cancel)", len(m.events))
+		}
+	case "x":
+		if m.mode == viewList {
+			return m.toggleErrorsOnly(), nil
+		}
+	case "/":
+		if m.mode == viewList {
+			m.editingFilter = true
+		}
+	case "@":
+		if m.mode == viewList {
+			m.editingTimeWindow = true
+		}
+	case "d":
+		if m.mode == viewList {
+			return m.cycleDirectionFilter(), nil
+		}
+		if m.mode == viewReplay && m.replayResult != nil && m.replayResult.err == nil {
+			rr := *m.replayResult
+			rr.showDiff = !rr.showDiff
+			rr.scroll = 0
+			m.replayResult = &rr
+		}
+	case "g":
+		if m.mode == viewList {
+			return m.toggleTree(), nil
+		}
+	case "f":
+		if m.mode == viewList {
+			return m.toggleFollow(), nil
+		}
+	case "enter", " ":
+		if m.mode == viewList && m.grouped {
+			return m.toggleTreeNode(), nil
+		}
+	case "E":
+		if m.mode == viewList {
+			m.showErrors = !m.showErrors
+		}
+	case "t":
+		switch m.mode {
+		case viewList:
+			m.mode = viewStats
+			m.statsScroll = 0
+		case viewStats:
+			m.mode = viewList
+		case viewReplay, viewTimeline:
+		}
+	case "T":
+		switch m.mode {
+		case viewList:
+			m.mode = viewTimeline
+		case viewTimeline:
+			m.mode = viewList
+		case viewReplay, viewStats:
+		}
+	case "b":
+		if m.mode == viewTimeline {
+			m.timelineIndex = (m.timelineIndex + 1) % len(timelineWindows)
+		}
+	case "s":
+		if m.mode == viewStats {
+			m.statsSort = (m.statsSort + 1) % statsSortCount
+		}
 	}
 	return m, nil
 }
 
+// visibleEvents returns the events that pass the active filters, newest first.
+func (m Model) visibleEvents() []*scopev1.CallEvent {
+	if !m.errorsOnly && m.methodFilter == "" && m.directionFilter == domain.DirectionUnspecified && m.timeWindow == nil {
+		return m.events
+	}
+	visible := make([]*scopev1.CallEvent, 0, len(m.events))
+	for _, ev := range m.events {
+		if m.matchesFilter(ev) {
+			visible = append(visible, ev)
+		}
+	}
+	return visible
+}
+
+func (m Model) matchesFilter(ev *scopev1.CallEvent) bool {
+	if m.errorsOnly && domain.StatusCode(ev.GetStatusCode()) == domain.StatusOK {
+		return false
+	}
+	if m.directionFilter != domain.DirectionUnspecified && domain.Direction(ev.GetDirection()) != m.directionFilter {
+		return false
+	}
+	return m.matchesMethodFilter(ev) && m.matchesTimeWindow(ev)
+}
+
+// selectedEvent returns the event under the cursor, or nil if nothing is
+// visible or, when grouped by service, a service or method is selected.
+func (m Model) selectedEvent() *scopev1.CallEvent {
+	if m.grouped {
+		rows := m.treeRows()
+		if len(rows) == 0 {
+			return nil
+		}
+		return rows[m.treeCursor(rows)].ev
+	}
+	visible := m.visibleEvents()
+	if m.cursor < 0 || m.cursor >= len(visible) {
+		return nil
+	}
+	return visible[m.cursor]
+}
+
+// clearEvents drops all captured events from the TUI. The scope server is not affected.
+func (m Model) clearEvents() Model {
+	m.events = nil
+	m.cursor = 0
+	m.status = "Cleared"
+	return m
+}
+
+func (m Model) toggleErrorsOnly() Model {
+	selected := m.selectedEvent()
+	m.errorsOnly = !m.errorsOnly
+	return m.reselect(selected)
+}
+
+// cycleDirectionFilter steps the direction filter from all events to inbound
+// calls only, then outbound calls only, and back.
+func (m Model) cycleDirectionFilter() Model {
+	selected := m.selectedEvent()
+	switch m.directionFilter {
+	case domain.DirectionUnspecified:
+		m.directionFilter = domain.DirectionInbound
+	case domain.DirectionInbound:
+		m.directionFilter = domain.DirectionOutbound
+	default:
+		m.directionFilter = domain.DirectionUnspecified
+	}
+	return m.reselect(selected)
+}
+
+// reselect moves the cursor onto ev if it is still visible, clamping the
+// cursor to the visible range otherwise.
+func (m Model) reselect(ev *scopev1.CallEvent) Model {
+	visible := m.visibleEvents()
+	for i, v := range visible {
+		if v == ev {
+			m.cursor = i
+			return m
+		}
+	}
+	if m.cursor >= len(visible) {
+		m.cursor = len(visible) - 1
+	}
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	return m
+}
+
 func (m Model) navigateUp() Model {
 	if m.mode == viewReplay && m.replayResult != nil && m.replayResult.scroll > 0 {
 		m.replayResult.scroll--
-	} else if m.mode == viewList && m.cursor > 0 {
-		m.cursor--
+	} else if m.mode == viewStats && m.statsScroll > 0 {
+		m.statsScroll--
+	} else if m.mode == viewList && m.grouped {
+		m = m.unfollow().moveTreeCursor(-1)
+	} else if m.mode == viewList {
+		m = m.unfollow()
+		m.cursor = max(m.cursor-1, 0)
 	}
 	return m
 }
@@ -192,8 +676,17 @@ func (m Model) navigateDown() Model {
 		if max := m.replayScrollMax(); m.replayResult.scroll < max {
 			m.replayResult.scroll++
 		}
-	} else if m.mode == viewList && m.cursor < len(m.events)-1 {
-		m.cursor++
+	} else if m.mode == viewStats {
+		if max := m.statsScrollMax(); m.statsScroll < max {
+			m.statsScroll++
+		}
+	} else if m.mode == viewList && m.grouped {
+		m = m.unfollow().moveTreeCursor(1)
+	} else if m.mode == viewList {
+		m = m.unfollow()
+		if m.cursor < len(m.visibleEvents())-1 {
+			m.cursor++
+		}
 	}
 	return m
 }
@@ -213,8 +706,28 @@ func (m Model) replayScrollMax() int {
 	return max
 }
 
+func (m Model) statsScrollMax() int {
+	// Must match the rows and visible rows computed in renderStats.
+	rows := len(computeStats(m.events, m.statsSort, m.maxStatsMethods))
+	visibleMax := m.height - 2 - 1 - 1 - 1
+	if visibleMax < 1 {
+		visibleMax = 1
+	}
+	max := rows - visibleMax
+	if max < 0 {
+		return 0
+	}
+	return max
+}
+
+// canReplay reports whether the selected event can be replayed now.
 func (m Model) canReplay() bool {
-	return m.appTarget != "" && len(m.events) > 0 && !m.replaying && m.mode == viewList
+	return m.replayable() && m.replayBlocked(m.selectedEvent().GetMethod()) == ""
+}
+
+// replayable is canReplay regardless of the replay allow and deny lists.
+func (m Model) replayable() bool {
+	return m.appTarget != "" && m.selectedEvent() != nil && !m.replaying && m.mode == viewList
 }
 
 func (m Model) View() string {
@@ -230,29 +743,41 @@ func (m Model) View() string {
 		return m.renderReplayResult()
 	}
 
-	maxListHeight := m.height/3 - 1
-	if maxListHeight < 3 {
-		maxListHeight = 3
-	}
-	listHeight := len(m.events)
-	if listHeight > maxListHeight {
-		listHeight = maxListHeight
+	if m.mode == viewStats {
+		return m.renderStats()
 	}
-	if listHeight < 1 {
-		listHeight = 1
+
+	if m.mode == viewTimeline {
+		return m.renderTimeline()
 	}
 
+	listHeight := m.listHeight()
 	list := m.renderList(listHeight)
 	// list panel = border(2) + title(1) + header(1) + rows = listHeight + 4
 	// detail panel = border(2) + content
 	// help = 1
 	detailMaxLines := m.height - (listHeight + 4) - 1 - 2 // 2 for detail border
+	var errorsPanel string
+	if m.showErrors {
+		// errors panel = border(2) + title(1) + rows
+		rows := min(maxErrorRows, max(1, len(m.recentErrors(maxErrorRows))))
+		errorsPanel = m.renderErrors(rows)
+		detailMaxLines -= rows + 3
+	}
 	if detailMaxLines < 3 {
 		detailMaxLines = 3
 	}
-	detail := m.renderDetail(detailMaxLines)
+	var detail string
+	if m.palette != nil {
+		detail = m.renderPalette(detailMaxLines)
+	} else {
+		detail = m.renderDetail(detailMaxLines)
+	}
 	help := m.renderHelp()
 
+	if errorsPanel != "" {
+		return lipgloss.JoinVertical(lipgloss.Left, list, errorsPanel, detail, help)
+	}
 	return lipgloss.JoinVertical(lipgloss.Left, list, detail, help)
 }
 
@@ -260,66 +785,134 @@ var (
 	headerStyle   = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("4"))
 	selectedStyle = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("2"))
 	errorStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+	warnStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("3"))
 	borderStyle   = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).Padding(0, 1)
 	labelStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("6"))
 	helpStyle     = lipgloss.NewStyle().Faint(true)
 	successStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("2"))
 )
 
+// directionBadge marks calls served by this process with "← " and calls it
+// made with "→ ". Events without a direction get blanks, keeping methods
+// aligned.
+func directionBadge(ev *scopev1.CallEvent) string {
+	switch ev.GetDirection() {
+	case scopev1.Direction_DIRECTION_INBOUND:
+		return "← "
+	case scopev1.Direction_DIRECTION_OUTBOUND:
+		return "→ "
+	default:
+		return "  "
+	}
+}
+
 func (m Model) methodColumnWidth() int {
-	// 2(cursor) + method + 1 + 12(status) + 1 + 10(latency) + 1 + 8(time) + 4(border/padding)
-	const fixed = 2 + 1 + 12 + 1 + 10 + 1 + 8 + 4
-	w := m.width - fixed
+	// 2(cursor) + method + 1 + 12(status) + 1 + 8(protocol) + 1 + 10(latency) + 1 + time + 4(border/padding)
+	const fixed = 2 + 1 + 12 + 1 + 8 + 1 + 10 + 1 + 4
+	w := m.width - fixed - m.timeColumnWidth()
 	if w < 40 {
 		w = 40
 	}
 	return w
 }
 
+// listHeight returns the number of event rows the list panel shows.
+func (m Model) listHeight() int {
+	maxListHeight := m.height/3 - 1
+	if maxListHeight < 3 {
+		maxListHeight = 3
+	}
+	listHeight := m.listLen()
+	if listHeight > maxListHeight {
+		listHeight = maxListHeight
+	}
+	if listHeight < 1 {
+		listHeight = 1
+	}
+	return listHeight
+}
+
+// listStart returns the index of the first visible row when the list shows
+// maxRows rows, scrolled so the cursor stays in view.
+func (m Model) listStart(maxRows int) int {
+	if cur := m.listCursor(); cur >= maxRows {
+		return cur - maxRows + 1
+	}
+	return 0
+}
+
+// listLen returns the number of rows in the list panel: visible events, or
+// tree rows when grouped by service.
+func (m Model) listLen() int {
+	if m.grouped {
+		return len(m.treeRows())
+	}
+	return len(m.visibleEvents())
+}
+
+// listCursor returns the index of the selected row in the list panel.
+func (m Model) listCursor() int {
+	if m.grouped {
+		return m.treeCursor(m.treeRows())
+	}
+	return m.cursor
+}
+
+// renderListRow formats the columns of a call in the list panel, labeled
+// method. styled colors its latency; see latencyCell.
+func (m Model) renderListRow(ev *scopev1.CallEvent, mw int, method string, styled bool) string {
+	statusStr := domain.StatusCode(ev.GetStatusCode()).String()
+	if ev.GetNilResponse() || len(ev.GetSchemaWarnings()) > 0 {
+		statusStr += " ⚠"
+	}
+	return fmt.Sprintf("%-*s %-12s %-8s %s %s",
+		mw,
+		truncate(method, mw),
+		statusStr,
+		ev.GetProtocol(),
+		m.latencyCell(ev, 10, styled),
+		m.formatTime(ev.GetStartTime()),
+	)
+}
+
 func (m Model) renderList(maxRows int) string {
 	mw := m.methodColumnWidth()
-	header := fmt.Sprintf("  %-*s %-12s %-10s %s", mw, "Method", "Status", "Latency", "Time")
+	header := fmt.Sprintf("  %-*s %-12s %-8s %-10s %s", mw, "Method", "Status", "Protocol", "Latency", "Time")
 	lines := []string{headerStyle.Render(header)}
 
-	start := 0
-	if m.cursor >= maxRows {
-		start = m.cursor - maxRows + 1
-	}
+	start := m.listStart(maxRows)
+	end := min(start+maxRows, m.listLen())
+	cur := m.listCursor()
 
-	end := start + maxRows
-	if end > len(m.events) {
-		end = len(m.events)
+	visible := m.visibleEvents()
+	var rows []treeRow
+	if m.grouped {
+		rows = m.treeRows()
 	}
-
 	for i := start; i < end; i++ {
-		ev := m.events[i]
 		cursor := "  "
-		if i == m.cursor {
+		if i == cur {
 			cursor = "▶ "
 		}
 
-		statusStr := domain.StatusCode(ev.GetStatusCode()).String()
-		latency := ""
-		if ev.GetDuration() != nil {
-			latency = ev.GetDuration().AsDuration().String()
-		}
-		timeStr := ""
-		if ev.GetStartTime() != nil {
-			timeStr = ev.GetStartTime().AsTime().Local().Format("15:04:05")
+		var line string
+		var failed bool
+		if m.grouped {
+			failed = rows[i].errors > 0 || rows[i].ev != nil && domain.StatusCode(rows[i].ev.GetStatusCode()) != domain.StatusOK
+			line = cursor + m.renderTreeRow(rows[i], mw, i != cur && !failed)
+		} else {
+			ev := visible[i]
+			method := ev.GetMethod()
+			if n := ev.GetAttempt(); n > 0 {
+				method = fmt.Sprintf("%s (retry %d)", method, n)
+			}
+			failed = domain.StatusCode(ev.GetStatusCode()) != domain.StatusOK
+			line = cursor + m.renderListRow(ev, mw, directionBadge(ev)+method, i != cur && !failed)
 		}
 
-		line := fmt.Sprintf("%s%-*s %-12s %-10s %s",
-			cursor,
-			mw,
-			truncate(ev.GetMethod(), mw),
-			statusStr,
-			latency,
-			timeStr,
-		)
-
-		if i == m.cursor {
+		if i == cur {
 			line = selectedStyle.Render(line)
-		} else if domain.StatusCode(ev.GetStatusCode()) != domain.StatusOK {
+		} else if failed {
 			line = errorStyle.Render(line)
 		}
 
@@ -328,19 +921,79 @@ func (m Model) renderList(maxRows int) string {
 
 	content := strings.Join(lines, "\n")
 	title := fmt.Sprintf(" gRPC Traffic (%d events) ", len(m.events))
+	var filters []string
+	if m.errorsOnly {
+		filters = append(filters, "[errors only]")
+	}
+	if m.directionFilter != domain.DirectionUnspecified {
+		filters = append(filters, fmt.Sprintf("[%s only]", m.directionFilter))
+	}
+	if m.methodFilter != "" {
+		filters = append(filters, fmt.Sprintf("[/%s]", m.methodFilter))
+	}
+	if m.timeWindow != nil {
+		filters = append(filters, fmt.Sprintf("[@%s]", m.timeWindow.text))
+	}
+	if m.grouped {
+		filters = append(filters, "[by service]")
+	}
+	if len(filters) > 0 {
+		title = fmt.Sprintf(" gRPC Traffic %s (%d/%d events) ", strings.Join(filters, " "), len(visible), len(m.events))
+	}
+	if m.following {
+		title += successStyle.Render("FOLLOW ")
+	}
+	if m.dropped > 0 {
+		noun := "events"
+		if m.dropped == 1 {
+			noun = "event"
+		}
+		title += errorStyle.Render(fmt.Sprintf("⚠ %d %s dropped ", m.dropped, noun))
+	}
+	if m.stale() {
+		title += m.renderLastSeen()
+	}
+	title += m.renderConnection(m.width - 4 - lipgloss.Width(title))
 	return borderStyle.Width(m.width - 2).Render(title + "\n" + content)
 }
 
 func (m Model) renderDetail(maxLines int) string {
-	if len(m.events) == 0 {
+	ev := m.selectedEvent()
+	if ev == nil && m.grouped {
+		if rows := m.treeRows(); len(rows) > 0 {
+			return m.renderTreeNodeDetail(rows[m.treeCursor(rows)])
+		}
+	}
+	if ev == nil {
+		if len(m.events) > 0 {
+			return borderStyle.Width(m.width - 2).Render("No events match the current filter.")
+		}
 		return borderStyle.Width(m.width - 2).Render("No events yet.")
 	}
 
-	ev := m.events[m.cursor]
-
 	var b strings.Builder
 	b.WriteString(labelStyle.Render("Method: "))
 	b.WriteString(ev.GetMethod())
+	if d := domain.Direction(ev.GetDirection()); d != domain.DirectionUnspecified {
+		b.WriteString("  ")
+		b.WriteString(labelStyle.Render("Direction: "))
+		b.WriteString(d.String())
+	}
+	if id := ev.GetPeerIdentity(); id != "" {
+		b.WriteString("  ")
+		b.WriteString(labelStyle.Render("Peer: "))
+		b.WriteString(id)
+	}
+	if path := ev.GetHttpPath(); path != "" {
+		b.WriteString("  ")
+		b.WriteString(labelStyle.Render("HTTP Path: "))
+		b.WriteString(path)
+	}
+	if ct := ev.GetResponseContentType(); ct != "" {
+		b.WriteString("  ")
+		b.WriteString(labelStyle.Render("Response Type: "))
+		b.WriteString(ct)
+	}
 	b.WriteString("\n")
 
 	b.WriteString(labelStyle.Render("Status: "))
@@ -353,22 +1006,117 @@ func (m Model) renderDetail(maxLines int) string {
 		b.WriteString("  ")
 		b.WriteString(labelStyle.Render("Latency: "))
 		b.WriteString(ev.GetDuration().AsDuration().String())
+		if total := ev.GetTotalDuration(); total != nil {
+			b.WriteString(fmt.Sprintf(" handler, %s total", total.AsDuration()))
+		}
+	}
+	if ev.GetStreaming() {
+		b.WriteString("  ")
+		b.WriteString(labelStyle.Render("Messages: "))
+		b.WriteString(fmt.Sprintf("%d sent, %d received", ev.GetSentCount(), ev.GetRecvCount()))
+	}
+	if n := ev.GetAttempt(); n > 0 {
+		b.WriteString("  ")
+		b.WriteString(labelStyle.Render("Retry: "))
+		b.WriteString(fmt.Sprintf("attempt %d (%d previous)", n+1, n))
+	}
+	if ev.GetDeadline() != nil {
+		b.WriteString("  ")
+		b.WriteString(labelStyle.Render("Deadline: "))
+		if d := eventTimeout(ev); d > 0 {
+			b.WriteString(d.String())
+		} else {
+			b.WriteString("expired on arrival")
+		}
+		if src := ev.GetDeadlineSource(); src != "" {
+			b.WriteString(fmt.Sprintf(" (set by %s)", src))
+		}
+	}
+	if enc := ev.GetContentEncoding(); enc != "" {
+		b.WriteString("  ")
+		b.WriteString(labelStyle.Render("Encoding: "))
+		b.WriteString(enc)
+	}
+	if n := ev.GetGoroutines(); n > 0 {
+		b.WriteString("  ")
+		b.WriteString(labelStyle.Render("Goroutines: "))
+		b.WriteString(strconv.Itoa(int(n)))
 	}
 	b.WriteString("\n")
 
+	if labels := ev.GetLabels(); len(labels) > 0 {
+		b.WriteString(labelStyle.Render("Labels: "))
+		b.WriteString(formatLabels(labels))
+		b.WriteString("\n")
+	}
+
+	if ev.GetSizeMismatch() {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("⚠ Content-Length %d does not match the %d-byte re-encoded request body",
+			ev.GetRequestContentLength(), ev.GetRequestBodySize())))
+		b.WriteString("\n")
+	}
+
+	if ev.GetNilResponse() {
+		b.WriteString(errorStyle.Render("⚠ Handler returned neither a response nor an error"))
+		b.WriteString("\n")
+	}
+
+	for _, w := range ev.GetSchemaWarnings() {
+		b.WriteString(warnStyle.Render("⚠ Schema: " + w))
+		b.WriteString("\n")
+	}
+
 	jsonWidth := m.width - 6 // border(2) + padding(2) + margin(2)
+	jsonMode := jsonTruncate
+	if m.wrapDetail {
+		jsonMode = jsonWrap
+	}
+	for _, detail := range ev.GetStatusDetails() {
+		b.WriteString(errorStyle.Render("Error Detail: "))
+		b.WriteString(highlightJSON(prettyJSON(detail, jsonWidth, jsonMode, nil)))
+		b.WriteString("\n")
+	}
+
 	if ev.GetRequestPayload() != "" {
 		b.WriteString(labelStyle.Render("Request: "))
-		b.WriteString(prettyJSON(ev.GetRequestPayload(), jsonWidth, jsonTruncate))
+		if m.collapsed[sectionRequest] {
+			b.WriteString(collapsedHint(sectionRequest))
+		} else {
+			b.WriteString(highlightJSON(prettyJSON(elideArrays(ev.GetRequestPayload(), m.maxShown), jsonWidth, jsonMode, m.schemas[ev.GetMethod()].request)))
+		}
 		b.WriteString("\n")
 	}
 
 	if ev.GetResponsePayload() != "" {
 		b.WriteString(labelStyle.Render("Response: "))
-		b.WriteString(prettyJSON(ev.GetResponsePayload(), jsonWidth, jsonTruncate))
+		if m.collapsed[sectionResponse] {
+			b.WriteString(collapsedHint(sectionResponse))
+		} else {
+			b.WriteString(highlightJSON(prettyJSON(elideArrays(ev.GetResponsePayload(), m.maxShown), jsonWidth, jsonMode, m.schemas[ev.GetMethod()].response)))
+		}
+		b.WriteString("\n")
 	}
 
-	content := b.String()
+	if m.collapsed[sectionMetadata] {
+		if len(ev.GetRequestMetadata())+len(ev.GetResponseHeaders())+len(ev.GetResponseTrailers()) > 0 {
+			b.WriteString(labelStyle.Render("Metadata: "))
+			b.WriteString(collapsedHint(sectionMetadata))
+			b.WriteString("\n")
+		}
+	} else {
+		var replayed metadata.MD
+		if m.appTarget != "" {
+			replayed = replay.FilterMetadata(metadataFromEvent(ev))
+			if replayed == nil {
+				replayed = metadata.MD{}
+			}
+		}
+		writeMetadata(&b, "Request Metadata:", ev.GetRequestMetadata(), jsonWidth, replayed)
+		writeMetadata(&b, "Response Headers:", ev.GetResponseHeaders(), jsonWidth, nil)
+		writeMetadata(&b, "Response Trailers:", ev.GetResponseTrailers(), jsonWidth, nil)
+	}
+
+	content := strings.TrimSuffix(b.String(), "\n")
 	lines := strings.Split(content, "\n")
 	if len(lines) > maxLines {
 		lines = lines[:maxLines-1]
@@ -378,6 +1126,19 @@ func (m Model) renderDetail(maxLines int) string {
 	return borderStyle.Width(m.width - 2).Render(strings.Join(lines, "\n"))
 }
 
+// renderIdempotency describes whether replaying a method is safe, based on
+// the idempotency_level it declares.
+func renderIdempotency(level replay.Idempotency) string {
+	switch level {
+	case replay.NoSideEffects:
+		return successStyle.Render("Safe: no side effects")
+	case replay.Idempotent:
+		return labelStyle.Render("Idempotent: ") + "repeated replays have the same effect"
+	default:
+		return errorStyle.Render("Warning: ") + "method may have side effects"
+	}
+}
+
 func (m Model) renderReplayResult() string {
 	if m.replayResult == nil {
 		return ""
@@ -389,6 +1150,13 @@ func (m Model) renderReplayResult() string {
 	b.WriteString(m.replayResult.method)
 	b.WriteString("\n")
 
+	if m.burst != nil {
+		m.renderResend(&b)
+	}
+	if m.loadTest != nil {
+		m.renderLoadTest(&b)
+	}
+
 	if m.replayResult.err != nil {
 		b.WriteString(errorStyle.Render("Error: "))
 		b.WriteString(m.replayResult.err.Error())
@@ -399,6 +1167,7 @@ func (m Model) renderReplayResult() string {
 			b.WriteString("Add to your server:\n")
 			b.WriteString("  import \"google.golang.org/grpc/reflection\"\n")
 			b.WriteString("  reflection.Register(srv)\n")
+			b.WriteString("Or pass --descriptor-set with a compiled FileDescriptorSet.\n")
 		}
 	} else {
 		r := m.replayResult.result
@@ -414,16 +1183,28 @@ func (m Model) renderReplayResult() string {
 		b.WriteString(labelStyle.Render("Duration: "))
 		b.WriteString(r.Duration.String())
 		b.WriteString("\n")
+		b.WriteString(renderIdempotency(r.Idempotency))
+		b.WriteString("\n")
 
 		if m.replayResult.requestJSON != "" {
 			b.WriteString(labelStyle.Render("Request: "))
-			b.WriteString(prettyJSON(m.replayResult.requestJSON, m.width-6, jsonWrap))
+			b.WriteString(highlightJSON(prettyJSON(m.replayResult.requestJSON, m.width-6, jsonWrap, nil)))
+			b.WriteString("\n")
+		}
+		if raw := m.replayResult.requestRaw; raw != nil {
+			b.WriteString(helpStyle.Render(fmt.Sprintf("Sent as the %d captured wire bytes", len(raw))))
 			b.WriteString("\n")
 		}
 
-		if r.ResponseJSON != "" {
+		switch {
+		case m.replayResult.showDiff:
+			b.WriteString(renderResponseDiff(m.replayResult.event.GetResponsePayload(), r.ResponseJSON, m.width-6))
+		case r.ResponseJSON != "":
+			b.WriteString(labelStyle.Render("Response: "))
+			b.WriteString(highlightJSON(prettyJSON(r.ResponseJSON, m.width-6, jsonWrap, nil)))
+		case len(r.RawResponse) > 0:
 			b.WriteString(labelStyle.Render("Response: "))
-			b.WriteString(prettyJSON(r.ResponseJSON, m.width-6, jsonWrap))
+			b.WriteString(fmt.Sprintf("%d bytes, not decoded: the method's types could not be resolved", len(r.RawResponse)))
 		}
 	}
 
@@ -458,44 +1239,208 @@ func (m Model) renderReplayResult() string {
 	for range pad {
 		visible = append(visible, "")
 	}
-	visible = append(visible, helpStyle.Render("q: back  j/k/↑/↓: scroll  r: resend"))
+	visible = append(visible, helpStyle.Render(m.replayHelp()))
 
 	return borderStyle.Width(m.width - 2).Render(strings.Join(visible, "\n"))
 }
 
+func (m Model) replayHelp() string {
+	switch {
+	case m.burst != nil && m.burst.running(), m.loadTest != nil && m.loadTest.running:
+		return "esc: cancel  q: back  j/k/↑/↓: scroll"
+	case m.resendCount != "":
+		return fmt.Sprintf("r: resend ×%s  esc: reset count  q: back", m.resendCount)
+	case m.replayResult != nil && m.replayResult.err == nil && m.replayResult.showDiff:
+		return "q: back  j/k/↑/↓: scroll  r: resend  0-9 r: resend N times  L: load test  d: response"
+	case m.replayResult != nil && m.replayResult.err == nil:
+		return "q: back  j/k/↑/↓: scroll  r: resend  0-9 r: resend N times  L: load test  d: diff"
+	default:
+		return "q: back  j/k/↑/↓: scroll  r: resend  0-9 r: resend N times  L: load test"
+	}
+}
+
 func (m Model) renderHelp() string {
-	parts := []string{"q: quit", "j/k/↑/↓: navigate"}
-	if m.appTarget != "" && len(m.events) > 0 {
-		parts = append(parts, "r: replay", "e: edit & replay")
+	if m.palette != nil {
+		return helpStyle.Render("  enter: run  esc: close  ↑/↓: select  type to search")
+	}
+	if m.editingFilter {
+		return labelStyle.Render("  /") + m.methodFilter + "█" + helpStyle.Render("  enter: apply  esc: clear")
+	}
+	if m.editingTimeWindow {
+		hint := "e.g. 30s or 15:04:05..15:05:00  enter: apply  esc: clear"
+		if m.status != "" {
+			hint = m.status
+		}
+		return labelStyle.Render("  @") + m.timeWindowInput + "█" + helpStyle.Render("  "+hint)
+	}
+	if m.status != "" {
+		status := m.status
+		if m.width > 8 {
+			status = truncate(status, m.width-2)
+		}
+		return helpStyle.Render("  " + status)
+	}
+	if m.reconnecting {
+		return m.renderReconnecting()
+	}
+	parts := []string{m.keys.label(ActionQuit, "q") + ": quit", m.navigateLabel() + ": navigate"}
+	hasSelection := m.selectedEvent() != nil
+	if m.appTarget != "" && hasSelection && m.replayBlocked(m.selectedEvent().GetMethod()) == "" {
+		parts = append(parts, m.keys.label(ActionReplay, "r")+": replay", m.keys.label(ActionEdit, "e")+": edit & replay")
 	}
-	return helpStyle.Render("  " + strings.Join(parts, "  "))
+	if hasSelection {
+		parts = append(parts, "y: copy grpcurl", "1/2/3: fold", "z: wrap")
+	}
+	if len(m.events) > 0 {
+		parts = append(parts, "w/W: export", "c: clear")
+	}
+	if m.errorsOnly {
+		parts = append(parts, "x: show all")
+	} else {
+		parts = append(parts, "x: errors only")
+	}
+	parts = append(parts, m.keys.label(ActionSearch, "/")+": filter", "@: time", "d: direction", "E: errors", "g: tree", "f: follow", "t: stats", "T: timeline", ": commands")
+	help := strings.Join(parts, "  ")
+	if m.width > 8 {
+		help = truncate(help, m.width-2)
+	}
+	return helpStyle.Render("  " + help)
 }
 
-func (m Model) doReplay(ev *scopev1.CallEvent, payloadJSON string) tea.Cmd {
-	appTarget := m.appTarget
-	method := ev.GetMethod()
-	md := metadataFromEvent(ev)
+// navigateLabel returns the help text for the navigation keys.
+func (m Model) navigateLabel() string {
+	if _, ok := m.keys.custom[ActionDown]; !ok {
+		if _, ok := m.keys.custom[ActionUp]; !ok {
+			return "j/k/↑/↓"
+		}
+	}
+	return m.keys.label(ActionDown, "j/↓") + "/" + m.keys.label(ActionUp, "k/↑")
+}
+
+// doReplay sends the call ev again. A nil md sends ev's own metadata;
+// either way it is filtered by replay.FilterMetadata. raw, the request's
+// captured wire bytes, is sent instead of payloadJSON when set. ev rather
+// than the selected event is used, since follow mode moves the selection
+// while the replay view is open.
+func (m Model) doReplay(method string, md map[string][]string, payloadJSON string, raw []byte, ev *scopev1.CallEvent) tea.Cmd {
+	client, clientErr := m.replayClient, m.replayErr
+	sent := md
+	if sent == nil {
+		sent = metadataFromEvent(ev)
+	}
+	sent = replay.FilterMetadata(sent)
+	timeout := m.replayTimeout(ev)
+	rotateKeys := m.rotateKeys
 
 	return func() tea.Msg {
-		client, err := replay.NewClient(appTarget)
-		if err != nil {
-			return ReplayResultMsg{Method: method, RequestJSON: payloadJSON, Err: err}
+		if clientErr != nil {
+			return ReplayResultMsg{Method: method, RequestJSON: payloadJSON, RequestRaw: raw, Metadata: md, Event: ev, Err: clientErr}
 		}
-		defer client.Close()
 
 		result, err := client.Send(context.Background(), replay.Request{
-			Method:      method,
-			PayloadJSON: payloadJSON,
-			Metadata:    md,
+			Method:             method,
+			PayloadJSON:        payloadJSON,
+			RawRequest:         raw,
+			Metadata:           sent,
+			RotateMetadataKeys: rotateKeys,
+			Timeout:            timeout,
 		})
-		return ReplayResultMsg{Result: result, Method: method, RequestJSON: payloadJSON, Err: err}
+		return ReplayResultMsg{Result: result, Method: method, RequestJSON: payloadJSON, RequestRaw: raw, Metadata: md, Event: ev, Err: err}
 	}
 }
 
+// replayRequest returns the request shown in the replay view, using edited
+// metadata when the call was replayed from the editor.
+func (m Model) replayRequest() replay.Request {
+	md := m.replayResult.metadata
+	if md == nil {
+		md = metadataFromEvent(m.replayResult.event)
+	}
+	return replay.Request{
+		Method:             m.replayResult.method,
+		PayloadJSON:        m.replayResult.requestJSON,
+		RawRequest:         m.replayResult.requestRaw,
+		Metadata:           replay.FilterMetadata(md),
+		RotateMetadataKeys: m.rotateKeys,
+		Timeout:            m.replayTimeout(m.replayResult.event),
+	}
+}
+
+// replayTimeout returns the timeout the client of ev set, when replays keep
+// the original deadline, or zero for the replay client's default.
+func (m Model) replayTimeout(ev *scopev1.CallEvent) time.Duration {
+	if !m.keepDeadline {
+		return 0
+	}
+	return eventTimeout(ev)
+}
+
+// eventTimeout returns how long the client allowed ev to run, or zero if
+// it set no deadline.
+func eventTimeout(ev *scopev1.CallEvent) time.Duration {
+	if ev.GetDeadline() == nil || ev.GetStartTime() == nil {
+		return 0
+	}
+	d := ev.GetDeadline().AsTime().Sub(ev.GetStartTime().AsTime())
+	if d <= 0 {
+		return 0
+	}
+	return d.Round(time.Millisecond)
+}
+
+// editorEnvelope is the document opened in $EDITOR for edit & replay.
+type editorEnvelope struct {
+	Metadata map[string][]string `json:"metadata"`
+	Payload  json.RawMessage     `json:"payload"`
+}
+
+// newEditorEnvelope renders the event's replayable metadata and request
+// payload as an indented JSON document.
+func newEditorEnvelope(ev *scopev1.CallEvent) (string, error) {
+	env := editorEnvelope{
+		Metadata: replay.FilterMetadata(metadataFromEvent(ev)),
+		Payload:  json.RawMessage("{}"),
+	}
+	if env.Metadata == nil {
+		env.Metadata = map[string][]string{}
+	}
+	if p := ev.GetRequestPayload(); json.Valid([]byte(p)) {
+		env.Payload = json.RawMessage(p)
+	}
+	b, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(b) + "\n", nil
+}
+
+// parseEditorEnvelope reads back an edited envelope. A missing payload
+// means an empty request.
+func parseEditorEnvelope(s string) (map[string][]string, string, error) {
+	var env editorEnvelope
+	if err := json.Unmarshal([]byte(s), &env); err != nil {
+		return nil, "", fmt.Errorf("parse edited request: %w", err)
+	}
+	if env.Metadata == nil {
+		env.Metadata = map[string][]string{}
+	}
+	payload := "{}"
+	if len(env.Payload) > 0 && string(env.Payload) != "null" {
+		var buf bytes.Buffer
+		if err := json.Compact(&buf, env.Payload); err != nil {
+			return nil, "", fmt.Errorf("parse edited payload: %w", err)
+		}
+		payload = buf.String()
+	}
+	return env.Metadata, payload, nil
+}
+
 func (m Model) openEditor(ev *scopev1.CallEvent) tea.Cmd {
-	payload := ev.GetRequestPayload()
-	if payload == "" {
-		payload = "{}"
+	payload, err := newEditorEnvelope(ev)
+	if err != nil {
+		return func() tea.Msg {
+			return EditorFinishedMsg{Event: ev, Err: fmt.Errorf("build editor document: %w", err)}
+		}
 	}
 
 	tmpFile, err := os.CreateTemp("", "grpc-scope-*.json")
@@ -530,22 +1475,127 @@ func (m Model) openEditor(ev *scopev1.CallEvent) tea.Cmd {
 		if err != nil {
 			return EditorFinishedMsg{Event: ev, Err: fmt.Errorf("read edited file: %w", err)}
 		}
-		return EditorFinishedMsg{Payload: string(edited), Event: ev}
+		md, payload, err := parseEditorEnvelope(string(edited))
+		if err != nil {
+			return EditorFinishedMsg{Event: ev, Err: err}
+		}
+		return EditorFinishedMsg{Payload: payload, Metadata: md, Event: ev}
 	})
 }
 
-func metadataFromEvent(ev *scopev1.CallEvent) map[string][]string {
-	rm := ev.GetRequestMetadata()
-	if len(rm) == 0 {
+func metadataFromEvent(ev *scopev1.CallEvent) domain.Metadata {
+	return metadataFromProto(ev.GetRequestMetadata())
+}
+
+func metadataFromProto(pm map[string]*scopev1.MetadataValues) domain.Metadata {
+	if len(pm) == 0 {
 		return nil
 	}
-	md := make(map[string][]string, len(rm))
-	for k, v := range rm {
+	md := make(domain.Metadata, len(pm))
+	for k, v := range pm {
 		md[k] = v.GetValues()
 	}
 	return md
 }
 
+// writeMetadata renders metadata entries in sorted key order, wrapping long
+// values onto lines indented past their key. When replayed is non-nil, entries
+// missing from it are dimmed and marked as not sent by a replay.
+func writeMetadata(b *strings.Builder, label string, pm map[string]*scopev1.MetadataValues, maxWidth int, replayed metadata.MD) {
+	md := metadataFromProto(pm)
+	if len(md) == 0 {
+		return
+	}
+	b.WriteString(labelStyle.Render(label))
+	b.WriteString("\n")
+	for _, k := range md.Keys() {
+		prefix := fmt.Sprintf("  %s: ", k)
+		value := strings.Join(md[k], ", ")
+		skipped := replayed != nil && replayed.Get(k) == nil
+		if skipped {
+			value += " (not replayed)"
+		}
+		for _, line := range wrapEntry(prefix, value, maxWidth) {
+			if skipped {
+				line = helpStyle.Render(line)
+			}
+			b.WriteString(line)
+			b.WriteString("\n")
+		}
+	}
+}
+
+// wrapEntry lays out prefix followed by value within width, breaking value at
+// spaces where it can and continuing it under its first character. A key too
+// long to leave room for its value indents continuations by four spaces.
+func wrapEntry(prefix, value string, width int) []string {
+	if width <= 3 || len(prefix)+len(value) <= width {
+		return []string{prefix + value}
+	}
+	indent := len(prefix)
+	if indent > width/2 {
+		indent = 4
+	}
+	var lines []string
+	line, room := prefix, width-len(prefix)
+	for value != "" {
+		if room <= 0 {
+			lines = append(lines, strings.TrimRight(line, " "))
+			line, room = strings.Repeat(" ", indent), width-indent
+		}
+		n := min(len(value), room)
+		if n < len(value) {
+			if i := strings.LastIndexByte(value[:n+1], ' '); i > 0 {
+				n = i + 1
+			}
+		}
+		line += value[:n]
+		value = value[n:]
+		room = 0
+	}
+	return append(lines, strings.TrimRight(line, " "))
+}
+
+// seen records that the Watch stream is alive.
+func (m *Model) seen() {
+	m.lastSeen = time.Now()
+	m.quietFor = 0
+}
+
+// addEvent inserts ev into the event list, newest first, keeping the cursor
+// on the same event. Events are ordered by start time, then by Seq, since
+// the server may deliver them out of order. An event already listed is
+// skipped, as a scope server that keeps history resends it on reconnect.
+func (m *Model) addEvent(ev *scopev1.CallEvent) {
+	selected := m.selectedEvent()
+	// New events almost always belong at the front, so scan from there.
+	i := 0
+	for i < len(m.events) && !newerEvent(ev, m.events[i]) {
+		i++
+	}
+	if i < len(m.events) && sameEvent(ev, m.events[i]) {
+		return
+	}
+	m.events = slices.Insert(m.events, i, ev)
+	*m = m.reselect(selected)
+}
+
+// newerEvent reports whether a should be listed before b. Events with the
+// same start time and sequence keep arrival order, newest arrival first.
+func newerEvent(a, b *scopev1.CallEvent) bool {
+	if c := a.GetStartTime().AsTime().Compare(b.GetStartTime().AsTime()); c != 0 {
+		return c > 0
+	}
+	return a.GetSeq() >= b.GetSeq()
+}
+
+// sameEvent reports whether a and b are the same publish of one call.
+// Events without a Seq come from older servers and are never the same.
+func sameEvent(a, b *scopev1.CallEvent) bool {
+	return a.GetSeq() != 0 && a.GetSeq() == b.GetSeq() && a.GetId() == b.GetId() &&
+		a.GetStartTime().AsTime().Equal(b.GetStartTime().AsTime())
+}
+
 func (m Model) connect() tea.Cmd {
 	return func() tea.Msg {
 		conn, err := grpc.NewClient(
@@ -557,7 +1607,15 @@ func (m Model) connect() tea.Cmd {
 		}
 
 		client := scopev1.NewScopeServiceClient(conn)
-		stream, err := client.Watch(context.Background(), &scopev1.WatchRequest{})
+		req := &scopev1.WatchRequest{BatchSize: int32(m.batchSize)}
+		if m.batchInterval > 0 {
+			req.BatchInterval = durationpb.New(m.batchInterval)
+		}
+		ctx := context.Background()
+		if m.token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, domain.HeaderScopeToken, m.token)
+		}
+		stream, err := client.Watch(ctx, req)
 		if err != nil {
 			conn.Close()
 			return ErrMsg{Err: fmt.Errorf("failed to start watch: %w", err)}
@@ -573,7 +1631,10 @@ func recvEvent(stream scopev1.ScopeService_WatchClient) tea.Cmd {
 		if err != nil {
 			return ErrMsg{Err: fmt.Errorf("watch stream error: %w", err)}
 		}
-		return EventMsg{Event: resp.GetEvent(), stream: stream}
+		if events := resp.GetEvents(); len(events) > 0 {
+			return EventBatchMsg{Events: events, Dropped: resp.GetDropped(), stream: stream}
+		}
+		return EventMsg{Event: resp.GetEvent(), Dropped: resp.GetDropped(), stream: stream}
 	}
 }
 
@@ -584,6 +1645,9 @@ func (m *Model) cleanup() {
 	if m.conn != nil {
 		_ = m.conn.Close()
 	}
+	if m.replayClient != nil {
+		_ = m.replayClient.Close()
+	}
 }
 
 func friendlyError(target string, err error) string {
@@ -607,6 +1671,13 @@ func friendlyError(target string, err error) string {
 					"Make sure you are connecting to the interceptor port, not your app port.",
 				target,
 			)
+		case codes.Unauthenticated:
+			return fmt.Sprintf(
+				"Connected to %s, but the scope server rejected the token.\n\n"+
+					"The interceptor was started with WithAuthToken. Pass the same token\n"+
+					"with --token or the GRPC_SCOPE_TOKEN environment variable.",
+				target,
+			)
 		}
 	}
 
@@ -623,12 +1694,15 @@ func friendlyError(target string, err error) string {
 
 const maxJSONLines = 6
 
-func prettyJSON(s string, maxWidth int, mode jsonDisplayMode) string {
+// prettyJSON indents s, annotated from its schema md when that is known,
+// and fits it to maxWidth as mode says. Notes do not count toward
+// maxJSONLines.
+func prettyJSON(s string, maxWidth int, mode jsonDisplayMode, md protoreflect.MessageDescriptor) string {
 	var buf bytes.Buffer
 	if err := json.Indent(&buf, []byte(s), "", "  "); err != nil {
 		return s
 	}
-	lines := strings.Split(buf.String(), "\n")
+	lines := annotateJSON(strings.Split(buf.String(), "\n"), md)
 	if maxWidth > 0 {
 		switch mode {
 		case jsonTruncate:
@@ -637,12 +1711,22 @@ func prettyJSON(s string, maxWidth int, mode jsonDisplayMode) string {
 					lines[i] = line[:maxWidth-3] + "..."
 				}
 			}
-			if len(lines) > maxJSONLines {
-				lines = append(lines[:maxJSONLines-1], "  ...")
+			var payload []int // indexes of the lines that are not notes
+			for i, line := range lines {
+				if !strings.HasPrefix(strings.TrimLeft(line, " "), jsonNotePrefix) {
+					payload = append(payload, i)
+				}
+			}
+			if len(payload) > maxJSONLines {
+				lines = append(lines[:payload[maxJSONLines-1]], "  ...")
 			}
 		case jsonWrap:
 			var wrapped []string
 			for _, line := range lines {
+				if strings.HasPrefix(strings.TrimLeft(line, " "), jsonNotePrefix) {
+					wrapped = append(wrapped, truncate(line, maxWidth))
+					continue
+				}
 				for len(line) > maxWidth {
 					wrapped = append(wrapped, line[:maxWidth])
 					line = line[maxWidth:]
@@ -1,15 +1,26 @@
 package tui_test
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
 	"strings"
 	"testing"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	"github.com/mickamy/grpc-scope/replay"
 	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"github.com/mickamy/grpc-scope/session"
 	"github.com/mickamy/grpc-scope/tui"
+	"github.com/muesli/termenv"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
@@ -59,6 +70,217 @@ func TestModel_Update_EventMsg(t *testing.T) {
 	}
 }
 
+func TestModel_Update_EventBatchMsg(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+
+	updated, _ = updated.Update(tui.EventBatchMsg{Events: []*scopev1.CallEvent{
+		newTestEvent("a", "/test.v1.Test/First", 1),
+		newTestEvent("b", "/test.v1.Test/Second", 1),
+		newTestEvent("c", "/test.v1.Test/Third", 1),
+	}})
+	view := updated.View()
+
+	first := strings.Index(view, "/test.v1.Test/First")
+	third := strings.Index(view, "/test.v1.Test/Third")
+	if first < 0 || third < 0 {
+		t.Fatalf("expected every batched event in the list, got:\n%s", view)
+	}
+	if third > first {
+		t.Errorf("expected the newest event of the batch to be listed first, got:\n%s", view)
+	}
+}
+
+func TestModel_Update_EventMsg_OutOfOrder(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+
+	base := time.Now()
+	newEvent := func(method string, offset time.Duration, seq uint64) *scopev1.CallEvent {
+		ev := newTestEvent(method, "/test.v1.Test/"+method, 1)
+		ev.StartTime = timestamppb.New(base.Add(offset))
+		ev.Seq = seq
+		return ev
+	}
+	// Delivered out of order; Fourth and Third share a start time.
+	for _, ev := range []*scopev1.CallEvent{
+		newEvent("Fourth", 2*time.Second, 4),
+		newEvent("First", 0, 1),
+		newEvent("Third", 2*time.Second, 3),
+		newEvent("Second", time.Second, 2),
+	} {
+		updated, _ = m.Update(tui.EventMsg{Event: ev})
+		m = updated.(tui.Model)
+	}
+
+	view := m.View()
+	prev := -1
+	for _, method := range []string{"Fourth", "Third", "Second", "First"} {
+		i := strings.Index(view, "/test.v1.Test/"+method)
+		if i < prev {
+			t.Errorf("expected %s to be listed after the newer events, got:\n%s", method, view)
+		}
+		prev = i
+	}
+	if !strings.Contains(view, "Method: /test.v1.Test/Fourth") {
+		t.Errorf("expected the first event to stay selected, got:\n%s", view)
+	}
+
+	// A server keeping history resends events after a reconnect.
+	resent := newEvent("Second", time.Second, 2)
+	updated, _ = m.Update(tui.EventMsg{Event: resent})
+	m = updated.(tui.Model)
+	if view := m.View(); !strings.Contains(view, "(4 events)") {
+		t.Errorf("expected the resent event to be skipped, got:\n%s", view)
+	}
+}
+
+func TestModel_Update_EventMsg_Dropped(t *testing.T) {
+	t.Parallel()
+
+	m := setupModelWithEvent("")
+	if view := m.View(); strings.Contains(view, "dropped") {
+		t.Errorf("expected no dropped indicator without drops, got:\n%s", view)
+	}
+
+	updated, _ := m.Update(tui.EventMsg{Event: newTestEvent("evt-2", "/test.v1.Test/Get", 1), Dropped: 1})
+	if view := updated.View(); !strings.Contains(view, "⚠ 1 event dropped") {
+		t.Errorf("expected dropped indicator in title, got:\n%s", view)
+	}
+
+	updated, _ = updated.Update(tui.EventMsg{Event: newTestEvent("evt-3", "/test.v1.Test/Get", 1), Dropped: 3})
+	if view := updated.View(); !strings.Contains(view, "⚠ 3 events dropped") {
+		t.Errorf("expected dropped indicator in title, got:\n%s", view)
+	}
+}
+
+func TestModel_View_ConnectionInTitle(t *testing.T) {
+	t.Parallel()
+
+	dropped := fmt.Errorf("watch stream error: %w", status.Error(codes.Unavailable, "transport is closing"))
+
+	tests := []struct {
+		name  string
+		model func() tui.Model
+		want  []string
+	}{
+		{
+			name:  "connecting without replay",
+			model: func() tui.Model { return setupModelWithEvent("") },
+			want:  []string{"○ localhost:9090 connecting", "replay off"},
+		},
+		{
+			name:  "connected with replay",
+			model: func() tui.Model { return tui.Connected(setupModelWithEvent("localhost:8080")) },
+			want:  []string{"● localhost:9090 · replay → localhost:8080"},
+		},
+		{
+			name: "reconnecting",
+			model: func() tui.Model {
+				updated, _ := tui.Connected(setupModelWithEvent("")).Update(tui.ErrMsg{Err: dropped})
+				return updated.(tui.Model)
+			},
+			want: []string{"↻ localhost:9090 reconnecting · replay off"},
+		},
+		{
+			name: "stale",
+			model: func() tui.Model {
+				updated, _ := tui.Connected(setupModelWithEvent("")).Update(tui.EventMsg{})
+				updated, _ = updated.Update(tui.LivenessMsg(time.Now().Add(time.Minute)))
+				return updated.(tui.Model)
+			},
+			want: []string{"◌ localhost:9090 stale · replay off"},
+		},
+		{
+			name: "replay target reported by the scope server",
+			model: func() tui.Model {
+				updated, _ := tui.Connected(setupModelWithEvent("")).Update(tui.AppTargetMsg("localhost:50051"))
+				return updated.(tui.Model)
+			},
+			want: []string{"replay → localhost:50051"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			title := strings.Split(tt.model().View(), "\n")[1]
+			for _, want := range tt.want {
+				if !strings.Contains(title, want) {
+					t.Errorf("expected %q in the list title, got:\n%s", want, title)
+				}
+			}
+		})
+	}
+
+	t.Run("narrow terminal leaves out the replay target", func(t *testing.T) {
+		t.Parallel()
+
+		m := tui.Connected(setupModelWithEvent("localhost:8080"))
+		updated, _ := m.Update(tea.WindowSizeMsg{Width: 50, Height: 40})
+		title := strings.Split(updated.View(), "\n")[1]
+		if !strings.Contains(title, "● localhost:9090") || strings.Contains(title, "replay") {
+			t.Errorf("expected only the scope target in a narrow title, got:\n%s", title)
+		}
+	})
+}
+
+func TestModel_Update_Heartbeat(t *testing.T) {
+	t.Parallel()
+
+	m := setupModelWithEvent("")
+
+	updated, _ := m.Update(tui.LivenessMsg(time.Now().Add(time.Minute)))
+	if view := updated.View(); strings.Contains(view, "last seen") {
+		t.Errorf("expected no indicator before the server sent a heartbeat, got:\n%s", view)
+	}
+
+	updated, _ = updated.Update(tui.EventMsg{})
+	m = updated.(tui.Model)
+	if view := m.View(); !strings.Contains(view, "(1 events)") {
+		t.Errorf("expected a heartbeat to add no event, got:\n%s", view)
+	}
+
+	updated, _ = m.Update(tui.LivenessMsg(time.Now().Add(time.Second)))
+	if view := updated.View(); strings.Contains(view, "last seen") {
+		t.Errorf("expected no indicator right after a heartbeat, got:\n%s", view)
+	}
+
+	updated, _ = m.Update(tui.LivenessMsg(time.Now().Add(time.Minute)))
+	if view := updated.View(); !strings.Contains(view, "⚠ last seen 1m") {
+		t.Errorf("expected a last seen indicator for a silent stream, got:\n%s", view)
+	}
+
+	updated, _ = updated.Update(tui.EventMsg{})
+	if view := updated.View(); strings.Contains(view, "last seen") {
+		t.Errorf("expected the next heartbeat to clear the indicator, got:\n%s", view)
+	}
+}
+
+func TestModel_Update_HeartbeatInterval(t *testing.T) {
+	t.Parallel()
+
+	// A server with a 30s heartbeat may stay silent for 90s.
+	m := setupModelWithEvent("")
+	updated, _ := m.Update(tui.ServerInfoMsg(&scopev1.ServerInfoResponse{HeartbeatInterval: durationpb.New(30 * time.Second)}))
+	updated, _ = updated.Update(tui.EventMsg{})
+
+	updated, _ = updated.Update(tui.LivenessMsg(time.Now().Add(time.Minute)))
+	if view := updated.View(); strings.Contains(view, "last seen") {
+		t.Errorf("expected no indicator within three heartbeat intervals, got:\n%s", view)
+	}
+
+	updated, _ = updated.Update(tui.LivenessMsg(time.Now().Add(2 * time.Minute)))
+	if view := updated.View(); !strings.Contains(view, "⚠ last seen 2m") {
+		t.Errorf("expected a last seen indicator after three heartbeat intervals, got:\n%s", view)
+	}
+}
+
 func TestModel_Update_CursorNavigation(t *testing.T) {
 	t.Parallel()
 
@@ -102,6 +324,43 @@ func TestModel_Update_CursorNavigation(t *testing.T) {
 	}
 }
 
+func TestModel_Update_Mouse(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+
+	// Events are listed newest first: [C, B, A].
+	for i := range 3 {
+		ev := newTestEvent(string(rune('a'+i)), "/test.v1.Test/Method"+string(rune('A'+i)), 1)
+		updated, _ = m.Update(tui.EventMsg{Event: ev})
+		m = updated.(tui.Model)
+	}
+
+	steps := []struct {
+		name string
+		msg  tea.MouseMsg
+		want string
+	}{
+		// Rows start below the border, the title, and the column header.
+		{name: "click first row", msg: tea.MouseMsg{X: 10, Y: 3, Button: tea.MouseButtonLeft}, want: "MethodC"},
+		{name: "click third row", msg: tea.MouseMsg{X: 10, Y: 5, Button: tea.MouseButtonLeft}, want: "MethodA"},
+		{name: "click header", msg: tea.MouseMsg{X: 10, Y: 2, Button: tea.MouseButtonLeft}, want: "MethodA"},
+		{name: "click below list", msg: tea.MouseMsg{X: 10, Y: 20, Button: tea.MouseButtonLeft}, want: "MethodA"},
+		{name: "wheel up", msg: tea.MouseMsg{X: 10, Y: 20, Button: tea.MouseButtonWheelUp}, want: "MethodB"},
+		{name: "wheel down", msg: tea.MouseMsg{X: 10, Y: 20, Button: tea.MouseButtonWheelDown}, want: "MethodA"},
+	}
+	for _, step := range steps {
+		updated, _ = m.Update(step.msg)
+		m = updated.(tui.Model)
+
+		if view := m.View(); !strings.Contains(view, "Method: /test.v1.Test/"+step.want) {
+			t.Errorf("%s: expected %s selected, got:\n%s", step.name, step.want, view)
+		}
+	}
+}
+
 func TestModel_Update_CursorBounds(t *testing.T) {
 	t.Parallel()
 
@@ -129,6 +388,18 @@ func TestModel_Update_ErrMsg(t *testing.T) {
 	}
 }
 
+func TestModel_Update_ErrMsg_Unauthenticated(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	err := fmt.Errorf("watch stream error: %w", status.Error(codes.Unauthenticated, "missing or invalid x-scope-token"))
+	updated, _ := m.Update(tui.ErrMsg{Err: err})
+
+	if view := updated.View(); !strings.Contains(view, "--token") {
+		t.Errorf("expected hint about --token in view, got:\n%s", view)
+	}
+}
+
 func TestModel_View_NoEvents(t *testing.T) {
 	t.Parallel()
 
@@ -205,6 +476,173 @@ func TestModel_Update_ReplayResultMsg(t *testing.T) {
 	}
 }
 
+func TestModel_Update_ReplayResultMsg_RawRequest(t *testing.T) {
+	t.Parallel()
+
+	m := setupModelWithEvent("localhost:8080")
+
+	// The app server's types could not be resolved, so the response came back
+	// undecoded.
+	updated, _ := m.Update(tui.ReplayResultMsg{
+		Result: &replay.Result{
+			RawResponse: []byte{0x0a, 0x02, 0x6f, 0x6b},
+			Duration:    50 * time.Millisecond,
+		},
+		Method:      "/test.v1.Test/Get",
+		RequestJSON: `{"key":"value"}`,
+		RequestRaw:  []byte{0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65},
+	})
+
+	view := updated.View()
+	if !strings.Contains(view, "Sent as the 7 captured wire bytes") {
+		t.Errorf("expected the raw request to be noted, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Response: 4 bytes, not decoded") {
+		t.Errorf("expected the undecoded response size, got:\n%s", view)
+	}
+}
+
+func TestModel_Update_ReplayResponseDiff(t *testing.T) {
+	t.Parallel()
+
+	// The selected event's response is {"result":"ok"}; the diff must use
+	// the response captured when the replay started instead.
+	tests := []struct {
+		name     string
+		captured string
+		response string
+		want     []string
+	}{
+		{
+			name:     "changed",
+			captured: `{"result":"ok"}`,
+			response: `{"result":"changed","extra":1}`,
+			want:     []string{`-   "result": "ok"`, `+   "extra": 1,`, `+   "result": "changed"`},
+		},
+		{
+			name:     "reordered only",
+			captured: `{"result":"ok"}`,
+			response: `{ "result" : "ok" }`,
+			want:     []string{"Response diff: unchanged"},
+		},
+		{
+			name:     "empty replay",
+			captured: `{"result":"ok"}`,
+			response: "",
+			want:     []string{"the replay returned no response"},
+		},
+		{
+			name:     "selection moved",
+			captured: `{"result":"before"}`,
+			response: `{"result":"before"}`,
+			want:     []string{"Response diff: unchanged"},
+		},
+		{
+			name:     "not captured",
+			response: `{"result":"ok"}`,
+			want:     []string{"the original response was not captured"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := setupModelWithEvent("localhost:8080")
+			updated, _ := m.Update(tui.ReplayResultMsg{
+				Result: &replay.Result{ResponseJSON: tt.response},
+				Method: "/test.v1.Test/Get",
+				Event:  &scopev1.CallEvent{ResponsePayload: tt.captured},
+			})
+			updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+
+			view := updated.View()
+			for _, want := range tt.want {
+				if !strings.Contains(view, want) {
+					t.Errorf("expected %q in the diff, got:\n%s", want, view)
+				}
+			}
+			if !strings.Contains(view, "d: response") {
+				t.Errorf("expected d to switch back to the response, got:\n%s", view)
+			}
+
+			updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+			if view := updated.View(); strings.Contains(view, "Response diff") {
+				t.Errorf("expected d to hide the diff again, got:\n%s", view)
+			}
+		})
+	}
+}
+
+func TestModel_Update_ReplayResultMsg_Idempotency(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		level replay.Idempotency
+		want  string
+	}{
+		{name: "no side effects", level: replay.NoSideEffects, want: "Safe: no side effects"},
+		{name: "idempotent", level: replay.Idempotent, want: "Idempotent"},
+		{name: "unknown", level: replay.IdempotencyUnknown, want: "may have side effects"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := setupModelWithEvent("localhost:8080")
+			updated, _ := m.Update(tui.ReplayResultMsg{
+				Result: &replay.Result{Idempotency: tt.level},
+				Method: "/test.v1.Test/Get",
+			})
+
+			if view := updated.(tui.Model).View(); !strings.Contains(view, tt.want) {
+				t.Errorf("expected %q in replay result, got:\n%s", tt.want, view)
+			}
+		})
+	}
+}
+
+func TestModel_Update_ReplayConfirmsUnsafeMethods(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		level       replay.Idempotency
+		wantConfirm string
+	}{
+		{name: "no side effects replays at once", level: replay.NoSideEffects},
+		{name: "idempotent asks first", level: replay.Idempotent, wantConfirm: "It is idempotent but may have side effects"},
+		{name: "unknown asks first", level: replay.IdempotencyUnknown, wantConfirm: "It may have side effects"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := setupModelWithEvent("localhost:8080")
+			updated, cmd := m.Update(tui.IdempotencyMsg("/test.v1.Test/Get", `{"key":"value"}`, tt.level))
+			if tt.wantConfirm == "" {
+				if cmd == nil {
+					t.Error("expected a safe method to replay without confirmation")
+				}
+				return
+			}
+			if cmd != nil {
+				t.Fatal("expected the replay to wait for confirmation")
+			}
+			if view := updated.View(); !strings.Contains(view, "Replay /test.v1.Test/Get? "+tt.wantConfirm) {
+				t.Errorf("expected a confirmation prompt, got:\n%s", view)
+			}
+
+			if _, cmd := updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}}); cmd != nil {
+				t.Error("expected any other key to cancel the replay")
+			}
+			if _, cmd := updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}}); cmd == nil {
+				t.Error("expected y to send the replay")
+			}
+		})
+	}
+}
+
 func TestModel_Update_ReplayResultMsg_Error(t *testing.T) {
 	t.Parallel()
 
@@ -288,6 +726,78 @@ func TestModel_Update_ReplayKeyIgnored_NoAppTarget(t *testing.T) {
 	}
 }
 
+func TestModel_Update_ReportedAppTarget(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		appTarget  string
+		wantStatus string
+	}{
+		{name: "enables replay", wantStatus: "Replay enabled for localhost:50051"},
+		{name: "app-addr overrides", appTarget: "localhost:8080"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := setupModelWithEvent(tt.appTarget)
+			updated, _ := m.Update(tui.AppTargetMsg("localhost:50051"))
+
+			view := updated.View()
+			if tt.wantStatus != "" && !strings.Contains(view, tt.wantStatus) {
+				t.Errorf("expected %q, got:\n%s", tt.wantStatus, view)
+			}
+			if tt.wantStatus == "" && strings.Contains(view, "Replay enabled") {
+				t.Errorf("expected the reported target to be ignored, got:\n%s", view)
+			}
+
+			if _, cmd := updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}}); cmd == nil {
+				t.Error("expected r to replay")
+			}
+		})
+	}
+}
+
+func TestModel_Update_ReplayElidedPayload(t *testing.T) {
+	t.Parallel()
+
+	const payload = `{"values":["a","b","c","d","e"]}`
+
+	// Nothing listens on port 1, so the replay fails after building its
+	// request.
+	m := tui.NewModel("localhost:9090", "127.0.0.1:1")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	updated, _ = updated.Update(tui.ServerInfoMsg(&scopev1.ServerInfoResponse{MaxShownElements: 2}))
+	ev := newTestEvent("evt-1", "/test.v1.Test/Get", 1)
+	ev.RequestPayload = payload
+	updated, _ = updated.Update(tui.EventMsg{Event: ev})
+
+	if view := updated.View(); !strings.Contains(view, "... 3 more elided") {
+		t.Errorf("expected the request array to be elided in the detail pane, got:\n%s", view)
+	}
+
+	updated, cmd := updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	if cmd == nil {
+		t.Fatal("expected r to replay")
+	}
+	// The method's idempotency level cannot be resolved, so the replay waits
+	// for confirmation.
+	updated, _ = updated.Update(cmd())
+	_, cmd = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	if cmd == nil {
+		t.Fatal("expected y to confirm the replay")
+	}
+	got := cmd()
+	msg, ok := got.(tui.ReplayResultMsg)
+	if !ok {
+		t.Fatalf("expected a replay result, got %T", got)
+	}
+	if msg.RequestJSON != payload {
+		t.Errorf("got replayed request %s, want the full capture %s", msg.RequestJSON, payload)
+	}
+}
+
 func TestModel_Update_ReplayKeyIgnored_NoEvents(t *testing.T) {
 	t.Parallel()
 
@@ -304,6 +814,93 @@ func TestModel_Update_ReplayKeyIgnored_NoEvents(t *testing.T) {
 	}
 }
 
+func TestModel_Update_ReplayPolicy(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		opts      []tui.Option
+		method    string
+		wantAllow bool
+	}{
+		{
+			name:   "denied",
+			opts:   []tui.Option{tui.WithReplayDenyList([]string{"/*/Delete*"})},
+			method: "/test.v1.Test/DeleteUser",
+		},
+		{
+			name:      "not denied",
+			opts:      []tui.Option{tui.WithReplayDenyList([]string{"/*/Delete*"})},
+			method:    "/test.v1.Test/GetUser",
+			wantAllow: true,
+		},
+		{
+			name:      "allowed",
+			opts:      []tui.Option{tui.WithReplayAllowList([]string{"/test.v1.Test/Get*"})},
+			method:    "/test.v1.Test/GetUser",
+			wantAllow: true,
+		},
+		{
+			name:   "not allowed",
+			opts:   []tui.Option{tui.WithReplayAllowList([]string{"/test.v1.Test/Get*"})},
+			method: "/test.v1.Test/UpdateUser",
+		},
+		{
+			name: "deny wins over allow",
+			opts: []tui.Option{
+				tui.WithReplayAllowList([]string{"/test.v1.Test/*"}),
+				tui.WithReplayDenyList([]string{"/*/Delete*"}),
+			},
+			method: "/test.v1.Test/DeleteUser",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// Nothing listens on port 1; allowed replays are never run.
+			m := tui.NewModel("localhost:9090", "127.0.0.1:1", tt.opts...)
+			updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+			updated, _ = updated.Update(tui.EventMsg{Event: newTestEvent("evt-1", tt.method, 1)})
+
+			for _, key := range []rune{'r', 'e'} {
+				got, cmd := updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+				if allowed := cmd != nil; allowed != tt.wantAllow {
+					t.Errorf("%c: got a replay command %v, want %v", key, allowed, tt.wantAllow)
+				}
+				blocked := strings.Contains(got.View(), "Replay of "+tt.method+" is disabled")
+				if blocked == tt.wantAllow {
+					t.Errorf("%c: got disabled message %v, want %v", key, blocked, !tt.wantAllow)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateMethodPatterns(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		patterns []string
+		wantErr  bool
+	}{
+		{name: "valid", patterns: []string{"/*/Delete*", "/test.v1.Test/Get"}},
+		{name: "malformed", patterns: []string{"/*/Get*", "/[Delete"}, wantErr: true},
+		{name: "empty", patterns: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tui.ValidateMethodPatterns(tt.patterns)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("got error %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
 func TestModel_Update_CursorIgnoredInReplayView(t *testing.T) {
 	t.Parallel()
 
@@ -375,3 +972,1667 @@ func TestModel_Update_EditorFinishedMsg_Error(t *testing.T) {
 		t.Errorf("expected editor error in view, got:\n%s", view)
 	}
 }
+
+func TestModel_Update_CopyGrpcurlUsesAppTarget(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "localhost:8080")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+	m = updated.(tui.Model)
+
+	ev := newTestEvent("evt-1", "/test.v1.Test/Get", 1)
+	updated, _ = m.Update(tui.EventMsg{Event: ev})
+	m = updated.(tui.Model)
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = updated.(tui.Model)
+	if cmd == nil {
+		t.Fatal("expected clipboard command")
+	}
+
+	view := m.View()
+	want := `grpcurl -plaintext -d '{"key":"value"}' localhost:8080 test.v1.Test/Get`
+	if !strings.Contains(view, want) {
+		t.Errorf("expected exported command %q in view, got:\n%s", want, view)
+	}
+}
+
+func TestGrpcurlCommand_Metadata(t *testing.T) {
+	t.Parallel()
+
+	ev := newTestEvent("evt-1", "/test.v1.Test/Get", 1)
+	ev.RequestMetadata = map[string]*scopev1.MetadataValues{
+		"authorization": {Values: []string{"Bearer it's-me"}},
+		"x-tenant":      {Values: []string{"a", "b"}},
+		"content-type":  {Values: []string{"application/grpc"}},
+		"grpc-timeout":  {Values: []string{"1S"}},
+		":authority":    {Values: []string{"localhost:8080"}},
+		"user-agent":    {Values: []string{"grpc-go/1.78.0"}},
+	}
+
+	got := tui.GrpcurlCommand(ev, "localhost:8080")
+	want := `grpcurl -plaintext -H 'authorization: Bearer it'\''s-me' -H 'x-tenant: a' -H 'x-tenant: b' ` +
+		`-d '{"key":"value"}' localhost:8080 test.v1.Test/Get`
+	if got != want {
+		t.Errorf("GrpcurlCommand() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestModel_Update_CopyGrpcurlWithoutAppTarget(t *testing.T) {
+	t.Parallel()
+
+	m := setupModelWithEvent("")
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = updated.(tui.Model)
+
+	view := m.View()
+	if !strings.Contains(view, "<app-addr> test.v1.Test/Get") {
+		t.Errorf("expected placeholder address in exported command, got:\n%s", view)
+	}
+}
+
+func TestModel_Update_ErrorsOnlyFilter(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+
+	// Status codes use the domain offset: 1 = OK, 6 = NOT_FOUND.
+	for i, code := range []int32{1, 6, 1} {
+		ev := newTestEvent(fmt.Sprintf("evt-%d", i), fmt.Sprintf("/test.v1.Test/Method%d", i), code)
+		updated, _ = m.Update(tui.EventMsg{Event: ev})
+		m = updated.(tui.Model)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(tui.Model)
+
+	view := m.View()
+	if !strings.Contains(view, "[errors only]") || !strings.Contains(view, "1/3 events") {
+		t.Errorf("expected filtered title with counts, got:\n%s", view)
+	}
+	if strings.Contains(view, "/test.v1.Test/Method0") || strings.Contains(view, "/test.v1.Test/Method2") {
+		t.Errorf("expected OK events to be hidden, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Status: NOT_FOUND") {
+		t.Errorf("expected cursor clamped onto the error event, got:\n%s", view)
+	}
+
+	// Cursor must stay within the filtered set.
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'j'}})
+	m = updated.(tui.Model)
+	if view := m.View(); !strings.Contains(view, "Status: NOT_FOUND") {
+		t.Errorf("expected cursor to stay on the only visible event, got:\n%s", view)
+	}
+
+	// New OK events do not show up while the filter is active.
+	updated, _ = m.Update(tui.EventMsg{Event: newTestEvent("evt-3", "/test.v1.Test/Method3", 1)})
+	m = updated.(tui.Model)
+	if view := m.View(); strings.Contains(view, "/test.v1.Test/Method3") || !strings.Contains(view, "1/4 events") {
+		t.Errorf("expected new OK event to be filtered out, got:\n%s", view)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'x'}})
+	m = updated.(tui.Model)
+
+	view = m.View()
+	if strings.Contains(view, "[errors only]") || !strings.Contains(view, "(4 events)") {
+		t.Errorf("expected unfiltered title, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Status: NOT_FOUND") {
+		t.Errorf("expected selection to be kept when clearing the filter, got:\n%s", view)
+	}
+}
+
+func TestModel_View_StatsPanel(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+
+	events := []struct {
+		method   string
+		code     int32
+		duration time.Duration
+	}{
+		{"/test.v1.Test/Get", 1, 10 * time.Millisecond},
+		{"/test.v1.Test/Get", 1, 20 * time.Millisecond},
+		{"/test.v1.Test/Get", 14, 300 * time.Millisecond},
+		{"/test.v1.Test/List", 1, 5 * time.Millisecond},
+	}
+	for i, e := range events {
+		ev := newTestEvent(fmt.Sprintf("evt-%d", i), e.method, e.code)
+		ev.Duration = durationpb.New(e.duration)
+		updated, _ = m.Update(tui.EventMsg{Event: ev})
+		m = updated.(tui.Model)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	m = updated.(tui.Model)
+
+	view := m.View()
+	if !strings.Contains(view, "Stats (2 methods, 4 events, sorted by calls)") {
+		t.Fatalf("expected stats title, got:\n%s", view)
+	}
+
+	var getRow, listRow string
+	for _, line := range strings.Split(view, "\n") {
+		switch {
+		case strings.Contains(line, "/test.v1.Test/Get"):
+			getRow = line
+		case strings.Contains(line, "/test.v1.Test/List"):
+			listRow = line
+		}
+	}
+	for _, want := range []string{" 3 ", " 1 ", "33.3%", "20ms", "300ms"} {
+		if !strings.Contains(getRow, want) {
+			t.Errorf("expected Get row to contain %q, got %q", want, getRow)
+		}
+	}
+	if strings.Index(view, "/test.v1.Test/Get") > strings.Index(view, "/test.v1.Test/List") {
+		t.Error("expected Get (3 calls) to sort before List (1 call)")
+	}
+	if !strings.Contains(listRow, "0.0%") {
+		t.Errorf("expected List row with no errors, got %q", listRow)
+	}
+
+	// Sort by method name.
+	for range 4 {
+		updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'s'}})
+		m = updated.(tui.Model)
+	}
+	if view := m.View(); !strings.Contains(view, "sorted by method") {
+		t.Errorf("expected method sort, got:\n%s", view)
+	}
+
+	// Stats update live as events arrive.
+	updated, _ = m.Update(tui.EventMsg{Event: newTestEvent("evt-9", "/test.v1.Test/Delete", 1)})
+	m = updated.(tui.Model)
+	if view := m.View(); !strings.Contains(view, "/test.v1.Test/Delete") {
+		t.Errorf("expected new method in stats, got:\n%s", view)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}})
+	m = updated.(tui.Model)
+	if view := m.View(); !strings.Contains(view, "gRPC Traffic") {
+		t.Errorf("expected list view after toggling stats off, got:\n%s", view)
+	}
+}
+
+func TestModel_View_Timeline(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+	events := []struct {
+		offset time.Duration
+		code   int32
+	}{
+		{0, 1},
+		{100 * time.Millisecond, 1},
+		{200 * time.Millisecond, 1},
+		{300 * time.Millisecond, 1},
+		// Nothing in the second second.
+		{2100 * time.Millisecond, 1},
+		{2200 * time.Millisecond, 14},
+		{3500 * time.Millisecond, 14},
+	}
+	for i, e := range events {
+		ev := newTestEvent(fmt.Sprintf("evt-%d", i), "/test.v1.Test/Get", e.code)
+		ev.StartTime = timestamppb.New(base.Add(e.offset))
+		updated, _ = m.Update(tui.EventMsg{Event: ev})
+		m = updated.(tui.Model)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+	m = updated.(tui.Model)
+
+	view := m.View()
+	if !strings.Contains(view, "Timeline (7 events, 1s windows, peak 4)") {
+		t.Fatalf("expected timeline title, got:\n%s", view)
+	}
+
+	rows := make(map[string]string)
+	for _, line := range strings.Split(view, "\n") {
+		for _, ts := range []string{"12:00:00", "12:00:01", "12:00:02", "12:00:03"} {
+			if strings.Contains(line, ts) {
+				rows[ts] = line
+			}
+		}
+	}
+	tests := []struct {
+		ts    string
+		calls int
+		rate  string
+	}{
+		{ts: "12:00:00", calls: 4, rate: "0.0%"},
+		{ts: "12:00:01", calls: 0, rate: "0.0%"},
+		{ts: "12:00:02", calls: 2, rate: "50.0%"},
+		{ts: "12:00:03", calls: 1, rate: "100.0%"},
+	}
+	bars := make(map[string]int)
+	for _, tt := range tests {
+		row, ok := rows[tt.ts]
+		if !ok {
+			t.Errorf("expected a row for %s, got:\n%s", tt.ts, view)
+			continue
+		}
+		if want := fmt.Sprintf(" %d ", tt.calls); !strings.Contains(row, want) || !strings.Contains(row, tt.rate) {
+			t.Errorf("row %s: got %q, want %d calls at %s", tt.ts, row, tt.calls, tt.rate)
+		}
+		bars[tt.ts] = strings.Count(row, "█")
+	}
+	if bars["12:00:01"] != 0 {
+		t.Errorf("expected an empty bar for the idle second, got %d cells", bars["12:00:01"])
+	}
+	if !(bars["12:00:00"] > bars["12:00:02"] && bars["12:00:02"] > bars["12:00:03"] && bars["12:00:03"] > 0) {
+		t.Errorf("expected bar lengths to follow call counts, got %v", bars)
+	}
+	if strings.Index(view, "12:00:00") > strings.Index(view, "12:00:03") {
+		t.Error("expected buckets oldest first")
+	}
+
+	// A wider window folds every call into one bucket.
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'b'}})
+	m = updated.(tui.Model)
+	if view := m.View(); !strings.Contains(view, "5s windows, peak 7") {
+		t.Errorf("expected 5s windows, got:\n%s", view)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'T'}})
+	m = updated.(tui.Model)
+	if view := m.View(); !strings.Contains(view, "gRPC Traffic") {
+		t.Errorf("expected list view after toggling timeline off, got:\n%s", view)
+	}
+}
+
+func TestModel_View_MetadataSortedByKey(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+
+	ev := newTestEvent("evt-1", "/test.v1.Test/Get", 1)
+	ev.RequestMetadata = map[string]*scopev1.MetadataValues{
+		"x-request-id":  {Values: []string{"abc"}},
+		"authorization": {Values: []string{"Bearer token"}},
+		"content-type":  {Values: []string{"application/grpc"}},
+		":authority":    {Values: []string{"localhost:8080"}},
+	}
+	updated, _ = m.Update(tui.EventMsg{Event: ev})
+	m = updated.(tui.Model)
+
+	want := []string{":authority: localhost:8080", "authorization: Bearer token", "content-type: application/grpc", "x-request-id: abc"}
+	for range 10 {
+		view := m.View()
+		last := -1
+		for _, entry := range want {
+			idx := strings.Index(view, entry)
+			if idx < 0 {
+				t.Fatalf("expected %q in view, got:\n%s", entry, view)
+			}
+			if idx < last {
+				t.Fatalf("expected metadata in sorted key order, got:\n%s", view)
+			}
+			last = idx
+		}
+	}
+}
+
+func TestModel_View_MetadataWrapsAndMarksReplayed(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "localhost:8080")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 60, Height: 60})
+	m = updated.(tui.Model)
+
+	ev := newTestEvent("evt-1", "/test.v1.Test/Get", 1)
+	ev.RequestMetadata = map[string]*scopev1.MetadataValues{
+		"x-forwarded-for": {Values: []string{"203.0.113.10", "198.51.100.22", "192.0.2.33", "203.0.113.44", "198.51.100.55"}},
+		"content-type":    {Values: []string{"application/grpc"}},
+	}
+	updated, _ = m.Update(tui.EventMsg{Event: ev})
+	m = updated.(tui.Model)
+
+	view := m.View()
+	for _, want := range []string{
+		"  x-forwarded-for: 203.0.113.10, 198.51.100.22,",
+		"                   192.0.2.33, 203.0.113.44,",
+		"                   198.51.100.55",
+		"  content-type: application/grpc (not replayed)",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in view, got:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "198.51.100.55 (not replayed)") {
+		t.Errorf("expected forwarded header to be unmarked, got:\n%s", view)
+	}
+}
+
+func TestModel_Update_ClearEvents(t *testing.T) {
+	t.Parallel()
+
+	t.Run("confirmed", func(t *testing.T) {
+		t.Parallel()
+
+		m := setupModelWithEvent("")
+
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}})
+		m = updated.(tui.Model)
+		if view := m.View(); !strings.Contains(view, "Clear 1 events?") {
+			t.Fatalf("expected confirmation prompt, got:\n%s", view)
+		}
+
+		updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+		m = updated.(tui.Model)
+
+		view := m.View()
+		if !strings.Contains(view, "No events yet") || !strings.Contains(view, "(0 events)") {
+			t.Errorf("expected events to be cleared, got:\n%s", view)
+		}
+		if !strings.Contains(view, "Cleared") {
+			t.Errorf("expected cleared status, got:\n%s", view)
+		}
+
+		// New events are captured again after clearing.
+		updated, _ = m.Update(tui.EventMsg{Event: newTestEvent("evt-2", "/test.v1.Test/List", 1)})
+		m = updated.(tui.Model)
+		if view := m.View(); !strings.Contains(view, "Method: /test.v1.Test/List") {
+			t.Errorf("expected new event to be selected after clearing, got:\n%s", view)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		t.Parallel()
+
+		m := setupModelWithEvent("")
+
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlL})
+		m = updated.(tui.Model)
+		updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}})
+		m = updated.(tui.Model)
+
+		view := m.View()
+		if !strings.Contains(view, "/test.v1.Test/Get") || !strings.Contains(view, "(1 events)") {
+			t.Errorf("expected events to be kept, got:\n%s", view)
+		}
+	})
+}
+
+func TestModel_Update_CommandPaletteClear(t *testing.T) {
+	t.Parallel()
+
+	m := setupModelWithEvent("")
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{':'}})
+	m = updated.(tui.Model)
+
+	view := m.View()
+	if !strings.Contains(view, "Clear events") || !strings.Contains(view, "Toggle stats panel") {
+		t.Fatalf("expected palette to list commands, got:\n%s", view)
+	}
+	if strings.Contains(view, "Replay selected request") {
+		t.Error("replay should not be listed without appTarget")
+	}
+
+	for _, r := range "clear" {
+		updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updated.(tui.Model)
+	}
+	if view := m.View(); strings.Contains(view, "Toggle stats panel") {
+		t.Errorf("expected search to narrow the list, got:\n%s", view)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(tui.Model)
+	if view := m.View(); !strings.Contains(view, "Clear 1 events?") {
+		t.Fatalf("expected clear confirmation after running command, got:\n%s", view)
+	}
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+	m = updated.(tui.Model)
+	if view := m.View(); !strings.Contains(view, "No events yet") {
+		t.Errorf("expected events to be cleared, got:\n%s", view)
+	}
+}
+
+func TestModel_Update_CommandPaletteEsc(t *testing.T) {
+	t.Parallel()
+
+	m := setupModelWithEvent("")
+
+	updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyCtrlP})
+	m = updated.(tui.Model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'q'}})
+	m = updated.(tui.Model)
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(tui.Model)
+
+	if cmd != nil {
+		t.Error("expected typing in the palette not to run commands")
+	}
+	if view := m.View(); !strings.Contains(view, "Method: /test.v1.Test/Get") {
+		t.Errorf("expected detail pane after closing palette, got:\n%s", view)
+	}
+}
+
+func TestModel_Update_MultiResendProgress(t *testing.T) {
+	t.Parallel()
+
+	// Nothing listens on port 1, so every resend fails fast.
+	m := setupModelWithEvent("127.0.0.1:1")
+	updated, _ := m.Update(tui.ReplayResultMsg{
+		Result: &replay.Result{StatusCode: 0, Duration: time.Millisecond},
+		Method: "/test.v1.Test/Get",
+	})
+	m = updated.(tui.Model)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'3'}})
+	m = updated.(tui.Model)
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	m = updated.(tui.Model)
+	if cmd == nil {
+		t.Fatal("expected a command to start the resend")
+	}
+	if view := m.View(); !strings.Contains(view, "0/3") || !strings.Contains(view, "esc: cancel") {
+		t.Errorf("expected progress 0/3 with cancel hint, got:\n%s", view)
+	}
+
+	for i := 1; i <= 3; i++ {
+		msg := cmd()
+		if _, ok := msg.(tui.ResendProgressMsg); !ok {
+			t.Fatalf("got %T, want tui.ResendProgressMsg", msg)
+		}
+		updated, cmd = m.Update(msg)
+		m = updated.(tui.Model)
+		if view := m.View(); !strings.Contains(view, fmt.Sprintf("%d/3", i)) {
+			t.Errorf("expected progress %d/3, got:\n%s", i, view)
+		}
+	}
+	if cmd != nil {
+		t.Error("expected no further command after the last resend")
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "failed 3") {
+		t.Errorf("expected 3 failed resends, got:\n%s", view)
+	}
+	if !strings.Contains(view, "(done)") {
+		t.Errorf("expected finished resend, got:\n%s", view)
+	}
+}
+
+func TestModel_Update_MultiResendCancel(t *testing.T) {
+	t.Parallel()
+
+	m := setupModelWithEvent("127.0.0.1:1")
+	updated, _ := m.Update(tui.ReplayResultMsg{
+		Result: &replay.Result{StatusCode: 0, Duration: time.Millisecond},
+		Method: "/test.v1.Test/Get",
+	})
+	m = updated.(tui.Model)
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'5'}})
+	m = updated.(tui.Model)
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'r'}})
+	m = updated.(tui.Model)
+	msg := cmd()
+
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(tui.Model)
+
+	// A result arriving after cancel must not advance progress.
+	updated, cmd = m.Update(msg)
+	m = updated.(tui.Model)
+	if cmd != nil {
+		t.Error("expected no further command after cancel")
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "0/5") || !strings.Contains(view, "(canceled)") {
+		t.Errorf("expected canceled resend at 0/5, got:\n%s", view)
+	}
+}
+
+func TestModel_Update_LoadTest(t *testing.T) {
+	t.Parallel()
+
+	m := setupModelWithEvent("127.0.0.1:1")
+	updated, _ := m.Update(tui.ReplayResultMsg{
+		Result: &replay.Result{StatusCode: 0, Duration: time.Millisecond},
+		Method: "/test.v1.Test/Get",
+	})
+	m = updated.(tui.Model)
+
+	updated, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'L'}})
+	m = updated.(tui.Model)
+	if cmd == nil {
+		t.Fatal("expected a command to start the load test")
+	}
+	if view := m.View(); !strings.Contains(view, "sending 100 calls") {
+		t.Errorf("expected running load test, got:\n%s", view)
+	}
+
+	msg := cmd()
+	result, ok := msg.(tui.LoadTestResultMsg)
+	if !ok {
+		t.Fatalf("got %T, want tui.LoadTestResultMsg", msg)
+	}
+	if result.Err == nil {
+		t.Fatal("expected an error with no server listening")
+	}
+	updated, _ = m.Update(msg)
+	m = updated.(tui.Model)
+
+	view := m.View()
+	if strings.Contains(view, "sending 100 calls") {
+		t.Errorf("expected finished load test, got:\n%s", view)
+	}
+	if !strings.Contains(view, "L: load test") {
+		t.Errorf("expected load test to be available again, got:\n%s", view)
+	}
+}
+
+func TestEditorEnvelope_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ev := newTestEvent("evt-1", "/test.v1.Test/Get", 0)
+	ev.RequestMetadata = map[string]*scopev1.MetadataValues{
+		"authorization": {Values: []string{"Bearer old"}},
+		"content-type":  {Values: []string{"application/grpc"}},
+	}
+
+	doc, err := tui.NewEditorEnvelope(ev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(doc, `"authorization"`) {
+		t.Errorf("expected authorization in envelope, got:\n%s", doc)
+	}
+	if strings.Contains(doc, "content-type") {
+		t.Errorf("expected content-type to be filtered from envelope, got:\n%s", doc)
+	}
+
+	edited := strings.Replace(doc, "Bearer old", "Bearer new", 1)
+	edited = strings.Replace(edited, `"value"`, `"changed"`, 1)
+	md, payload, err := tui.ParseEditorEnvelope(edited)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := md["authorization"]; len(got) != 1 || got[0] != "Bearer new" {
+		t.Errorf("got authorization %v, want [Bearer new]", got)
+	}
+	if payload != `{"key":"changed"}` {
+		t.Errorf("got payload %q, want %q", payload, `{"key":"changed"}`)
+	}
+}
+
+func TestParseEditorEnvelope(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		input       string
+		wantPayload string
+		wantErr     bool
+	}{
+		{name: "missing payload", input: `{"metadata":{}}`, wantPayload: "{}"},
+		{name: "null payload", input: `{"metadata":{},"payload":null}`, wantPayload: "{}"},
+		{name: "invalid JSON", input: `{"metadata":`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, payload, err := tui.ParseEditorEnvelope(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("got err %v, wantErr %v", err, tt.wantErr)
+			}
+			if payload != tt.wantPayload {
+				t.Errorf("got payload %q, want %q", payload, tt.wantPayload)
+			}
+		})
+	}
+}
+
+func TestModel_View_Deadline(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+
+	ev := newTestEvent("evt-1", "/test.v1.Test/Get", 1)
+	ev.Deadline = timestamppb.New(ev.GetStartTime().AsTime().Add(250 * time.Millisecond))
+	updated, _ = m.Update(tui.EventMsg{Event: ev})
+	m = updated.(tui.Model)
+
+	if view := m.View(); !strings.Contains(view, "Deadline: 250ms") {
+		t.Errorf("expected deadline in detail pane, got:\n%s", view)
+	}
+
+	ev = newTestEvent("evt-2", "/test.v1.Test/Get", 1)
+	ev.Deadline = timestamppb.New(ev.GetStartTime().AsTime().Add(time.Second))
+	ev.DeadlineSource = "server-default"
+	updated, _ = m.Update(tui.EventMsg{Event: ev})
+	m = updated.(tui.Model)
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyUp})
+	m = updated.(tui.Model)
+
+	if view := m.View(); !strings.Contains(view, "Deadline: 1s (set by server-default)") {
+		t.Errorf("expected deadline source in detail pane, got:\n%s", view)
+	}
+}
+
+func TestModel_View_LatencyBreakdown(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+
+	ev := newTestEvent("evt-1", "/test.v1.Test/Get", 1)
+	ev.TotalDuration = durationpb.New(12 * time.Millisecond)
+	updated, _ = m.Update(tui.EventMsg{Event: ev})
+	m = updated.(tui.Model)
+
+	if view := m.View(); !strings.Contains(view, "Latency: 10ms handler, 12ms total") {
+		t.Errorf("expected handler and total latency in detail pane, got:\n%s", view)
+	}
+}
+
+func TestModel_View_SizeMismatch(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+
+	ev := newTestEvent("evt-1", "/test.v1.Test/Get", 1)
+	ev.RequestContentLength = 4
+	ev.RequestBodySize = 2
+	ev.SizeMismatch = true
+	updated, _ = m.Update(tui.EventMsg{Event: ev})
+	m = updated.(tui.Model)
+
+	if view := m.View(); !strings.Contains(view, "Content-Length 4 does not match the 2-byte re-encoded request body") {
+		t.Errorf("expected size mismatch warning in detail pane, got:\n%s", view)
+	}
+}
+
+func TestModel_View_NilResponse(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+
+	ev := newTestEvent("evt-1", "/test.v1.Test/Get", 1)
+	ev.ResponsePayload = ""
+	ev.NilResponse = true
+	updated, _ = m.Update(tui.EventMsg{Event: ev})
+	m = updated.(tui.Model)
+
+	view := m.View()
+	if !strings.Contains(view, "OK ⚠") {
+		t.Errorf("expected warning badge in list, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Handler returned neither a response nor an error") {
+		t.Errorf("expected nil response warning in detail pane, got:\n%s", view)
+	}
+}
+
+func TestModel_View_SchemaWarnings(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	ev := newTestEvent("evt-1", "/test.v1.Test/Get", 1)
+	ev.SchemaWarnings = []string{"unknown field 99 in test.v1.GetRequest"}
+	updated, _ = updated.Update(tui.EventMsg{Event: ev})
+
+	view := updated.View()
+	if !strings.Contains(view, "OK ⚠") {
+		t.Errorf("expected warning badge in list, got:\n%s", view)
+	}
+	if !strings.Contains(view, "⚠ Schema: unknown field 99 in test.v1.GetRequest") {
+		t.Errorf("expected schema warning in detail pane, got:\n%s", view)
+	}
+}
+
+func TestModel_View_PeerIdentity(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	ev := newTestEvent("evt-1", "/test.v1.Test/Get", 1)
+	ev.PeerIdentity = "spiffe://example.org/ns/default/sa/billing"
+	updated, _ = updated.Update(tui.EventMsg{Event: ev})
+
+	if view := updated.View(); !strings.Contains(view, "Peer: spiffe://example.org/ns/default/sa/billing") {
+		t.Errorf("expected peer identity in detail pane, got:\n%s", view)
+	}
+}
+
+func TestModel_View_StatusDetails(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+
+	ev := newTestEvent("evt-1", "/test.v1.Test/Create", 4) // domain.StatusInvalidArgument
+	ev.StatusDetails = []string{
+		`{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[{"field":"name"}]}`,
+		`{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"NAME_TAKEN"}`,
+	}
+	updated, _ = m.Update(tui.EventMsg{Event: ev})
+	m = updated.(tui.Model)
+
+	view := m.View()
+	if strings.Count(view, "Error Detail: ") != 2 {
+		t.Errorf("expected both status details in the detail pane, got:\n%s", view)
+	}
+	if !strings.Contains(view, "fieldViolations") || !strings.Contains(view, "NAME_TAKEN") {
+		t.Errorf("expected the status detail contents, got:\n%s", view)
+	}
+
+	m = typeKeys(m, "E")
+	if view := m.View(); !strings.Contains(view, "/test.v1.Test/Create [2 details]") {
+		t.Errorf("expected the detail count in the errors panel, got:\n%s", view)
+	}
+}
+
+func TestModel_CollapseDetailSections(t *testing.T) {
+	t.Parallel()
+
+	m := setupModelWithEvent("")
+	press := func(key rune) {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{key}})
+		m = updated.(tui.Model)
+	}
+
+	press('1')
+	view := m.View()
+	if strings.Contains(view, `"key"`) || !strings.Contains(view, "(collapsed, press 1 to expand)") {
+		t.Errorf("expected the request section collapsed, got:\n%s", view)
+	}
+	if !strings.Contains(view, `"result"`) {
+		t.Errorf("expected the response section expanded, got:\n%s", view)
+	}
+
+	press('2')
+	if view := m.View(); strings.Contains(view, `"result"`) {
+		t.Errorf("expected the response section collapsed, got:\n%s", view)
+	}
+
+	press('1')
+	press('2')
+	if view := m.View(); !strings.Contains(view, `"key"`) || !strings.Contains(view, `"result"`) {
+		t.Errorf("expected both sections expanded again, got:\n%s", view)
+	}
+}
+
+func TestModel_ToggleDetailWrap(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 60})
+	for _, id := range []string{"evt-1", "evt-2"} {
+		ev := newTestEvent(id, "/test.v1.Test/Get", 1)
+		ev.ResponsePayload = `{"f1":1,"f2":2,"f3":3,"f4":4,"f5":5,"f6":6,"f7":7,"f8":8}`
+		updated, _ = updated.Update(tui.EventMsg{Event: ev})
+	}
+	m = updated.(tui.Model)
+	press := func(key string) {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+		m = updated.(tui.Model)
+	}
+
+	if view := m.View(); strings.Contains(view, `"f8"`) {
+		t.Errorf("expected the response truncated by default, got:\n%s", view)
+	}
+
+	press("z")
+	if view := m.View(); !strings.Contains(view, `"f8"`) {
+		t.Errorf("expected the response wrapped in full, got:\n%s", view)
+	}
+
+	press("j")
+	if view := m.View(); !strings.Contains(view, `"f8"`) {
+		t.Errorf("expected wrapping to persist across selection changes, got:\n%s", view)
+	}
+
+	press("z")
+	if view := m.View(); strings.Contains(view, `"f8"`) {
+		t.Errorf("expected the response truncated again, got:\n%s", view)
+	}
+}
+
+func TestModel_WithTimeFormat(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "", tui.WithTimeFormat("2006-01-02T15:04:05.000Z07:00", true))
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 140, Height: 40})
+	ev := newTestEvent("evt-1", "/test.v1.Test/Get", 1)
+	ev.StartTime = timestamppb.New(time.Date(2026, time.March, 4, 5, 6, 7, 890_000_000, time.UTC))
+	updated, _ = updated.Update(tui.EventMsg{Event: ev})
+
+	if view := updated.View(); !strings.Contains(view, "2026-03-04T05:06:07.890Z") {
+		t.Errorf("expected start time in the configured layout and UTC, got:\n%s", view)
+	}
+}
+
+func TestModel_MethodTree(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 60})
+	for i, method := range []string{"/users.v1.Users/Get", "/users.v1.Users/Get", "/users.v1.Users/Delete", "/orders.v1.Orders/List"} {
+		code := int32(1) // domain.StatusOK
+		if method == "/users.v1.Users/Delete" {
+			code = 8 // domain.StatusNotFound
+		}
+		updated, _ = updated.Update(tui.EventMsg{Event: newTestEvent(fmt.Sprintf("evt-%d", i), method, code)})
+	}
+	m = updated.(tui.Model)
+	press := func(key string) {
+		t.Helper()
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		if key == "enter" {
+			msg = tea.KeyMsg{Type: tea.KeyEnter}
+		}
+		updated, _ := m.Update(msg)
+		m = updated.(tui.Model)
+	}
+
+	press("g")
+	view := m.View()
+	for _, want := range []string{"[by service]", "▾ orders.v1.Orders (1)", "▾ users.v1.Users (3)", "▸ Delete (1)", "1 ERR", "▸ Get (2)"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("expected %q in the tree, got:\n%s", want, view)
+		}
+	}
+	if strings.Contains(view, "evt-0") {
+		t.Errorf("expected methods collapsed, got:\n%s", view)
+	}
+
+	// orders.v1.Orders, List, users.v1.Users, Delete, Get
+	for range 4 {
+		press("j")
+	}
+	if view := m.View(); !strings.Contains(view, "Method: /users.v1.Users/Get") || !strings.Contains(view, "Calls: 2") {
+		t.Errorf("expected the Get method summarized in the detail pane, got:\n%s", view)
+	}
+
+	press("enter")
+	press("j")
+	view = m.View()
+	if !strings.Contains(view, "▾ Get (2)") || !strings.Contains(view, "evt-1") {
+		t.Errorf("expected Get expanded to its calls, got:\n%s", view)
+	}
+	if !strings.Contains(view, `"result"`) {
+		t.Errorf("expected the selected call in the detail pane, got:\n%s", view)
+	}
+
+	press("k")
+	press("k")
+	press("k")
+	press("enter")
+	if view := m.View(); strings.Contains(view, "Delete (1)") || !strings.Contains(view, "▸ users.v1.Users (3)") {
+		t.Errorf("expected users.v1.Users collapsed, got:\n%s", view)
+	}
+
+	press("g")
+	if view := m.View(); strings.Contains(view, "[by service]") || !strings.Contains(view, "/orders.v1.Orders/List") {
+		t.Errorf("expected the flat list back, got:\n%s", view)
+	}
+}
+
+func TestModel_View_Protocol(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+
+	for i, protocol := range []string{"grpc", "connect"} {
+		ev := newTestEvent(fmt.Sprintf("evt-%d", i), "/test.v1.Test/"+protocol, 1)
+		ev.Protocol = protocol
+		updated, _ = m.Update(tui.EventMsg{Event: ev})
+		m = updated.(tui.Model)
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "Protocol") {
+		t.Errorf("expected a protocol column header, got:\n%s", view)
+	}
+	for _, row := range []string{
+		`/test\.v1\.Test/grpc\s+OK\s+grpc\s`,
+		`/test\.v1\.Test/connect\s+OK\s+connect\s`,
+	} {
+		if !regexp.MustCompile(row).MatchString(view) {
+			t.Errorf("expected a row matching %s, got:\n%s", row, view)
+		}
+	}
+}
+
+func TestModel_View_Direction(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+
+	inbound := newTestEvent("evt-1", "/test.v1.Test/Get", 1)
+	inbound.Direction = scopev1.Direction_DIRECTION_INBOUND
+	outbound := newTestEvent("evt-2", "/downstream.v1.Users/Get", 1)
+	outbound.Direction = scopev1.Direction_DIRECTION_OUTBOUND
+	for _, ev := range []*scopev1.CallEvent{inbound, outbound} {
+		updated, _ = m.Update(tui.EventMsg{Event: ev})
+		m = updated.(tui.Model)
+	}
+
+	view := m.View()
+	if !strings.Contains(view, "→ /downstream.v1.Users/Get") {
+		t.Errorf("expected outbound call to be marked in the list, got:\n%s", view)
+	}
+	if !strings.Contains(view, "← /test.v1.Test/Get") {
+		t.Errorf("expected inbound call to be marked in the list, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Direction: inbound") {
+		t.Errorf("expected direction of the selected event in the detail pane, got:\n%s", view)
+	}
+
+	steps := []struct {
+		title string
+		shown string
+		gone  string
+	}{
+		{title: "[inbound only] (1/2 events)", shown: "← /test.v1.Test/Get", gone: "/downstream.v1.Users/Get"},
+		{title: "[outbound only] (1/2 events)", shown: "→ /downstream.v1.Users/Get", gone: "/test.v1.Test/Get"},
+		{title: "gRPC Traffic (2 events)", shown: "→ /downstream.v1.Users/Get"},
+	}
+	for _, step := range steps {
+		m = typeKeys(m, "d")
+		view = m.View()
+		if !strings.Contains(view, step.title) || !strings.Contains(view, step.shown) {
+			t.Errorf("expected %q listing %q, got:\n%s", step.title, step.shown, view)
+		}
+		if step.gone != "" && strings.Contains(view, step.gone) {
+			t.Errorf("expected %q to be filtered out, got:\n%s", step.gone, view)
+		}
+	}
+}
+
+func typeKeys(m tui.Model, keys string) tui.Model {
+	for _, r := range keys {
+		updated, _ := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{r}})
+		m = updated.(tui.Model)
+	}
+	return m
+}
+
+func TestModel_Update_MethodFilter(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+	for _, ev := range []*scopev1.CallEvent{
+		newTestEvent("evt-1", "/users.v1.Users/Get", 1),
+		newTestEvent("evt-2", "/orders.v1.Orders/List", 1),
+	} {
+		updated, _ = m.Update(tui.EventMsg{Event: ev})
+		m = updated.(tui.Model)
+	}
+
+	m = typeKeys(m, "/users")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(tui.Model)
+
+	view := m.View()
+	if !strings.Contains(view, "[/users] (1/2 events)") {
+		t.Errorf("expected filtered title, got:\n%s", view)
+	}
+	if strings.Contains(view, "/orders.v1.Orders/List") {
+		t.Errorf("expected orders call to be filtered out, got:\n%s", view)
+	}
+
+	m = typeKeys(m, "/")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	m = updated.(tui.Model)
+	if view := m.View(); !strings.Contains(view, "/orders.v1.Orders/List") {
+		t.Errorf("expected esc to clear the filter, got:\n%s", view)
+	}
+}
+
+func TestModel_Update_LabelFilter(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+	acme := newTestEvent("evt-1", "/users.v1.Users/Get", 1)
+	acme.Labels = map[string]string{"tenant": "Acme", "user": "42"}
+	globex := newTestEvent("evt-2", "/orders.v1.Orders/List", 1)
+	globex.Labels = map[string]string{"tenant": "globex"}
+	for _, ev := range []*scopev1.CallEvent{acme, globex, newTestEvent("evt-3", "/users.v1.Users/List", 1)} {
+		updated, _ = m.Update(tui.EventMsg{Event: ev})
+		m = updated.(tui.Model)
+	}
+
+	m = typeKeys(m, "/tenant=acme")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(tui.Model)
+
+	view := m.View()
+	if !strings.Contains(view, "[/tenant=acme] (1/3 events)") {
+		t.Errorf("expected filtered title, got:\n%s", view)
+	}
+	if strings.Contains(view, "/orders.v1.Orders/List") || strings.Contains(view, "/users.v1.Users/List") {
+		t.Errorf("expected calls without a matching label to be filtered out, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Labels: tenant=Acme  user=42") {
+		t.Errorf("expected labels in detail pane, got:\n%s", view)
+	}
+}
+
+func TestModel_Update_TimeWindowFilter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		window string
+		events map[string]time.Time // method name to start time
+		want   []string
+		hidden []string
+	}{
+		{
+			name:   "relative",
+			window: "30s",
+			events: map[string]time.Time{
+				"Recent": time.Now().Add(-5 * time.Second),
+				"Old":    time.Now().Add(-time.Hour),
+			},
+			want:   []string{"Recent"},
+			hidden: []string{"Old"},
+		},
+		{
+			name:   "range",
+			window: "2026-01-02T10:01:00Z..2026-01-02T10:02:00Z",
+			events: map[string]time.Time{
+				"Before": time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC),
+				"During": time.Date(2026, 1, 2, 10, 1, 30, 0, time.UTC),
+				"After":  time.Date(2026, 1, 2, 10, 3, 0, 0, time.UTC),
+			},
+			want:   []string{"During"},
+			hidden: []string{"Before", "After"},
+		},
+		{
+			name:   "open end",
+			window: "2026-01-02T10:01:00Z..",
+			events: map[string]time.Time{
+				"Before": time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC),
+				"After":  time.Date(2026, 1, 2, 10, 3, 0, 0, time.UTC),
+			},
+			want:   []string{"After"},
+			hidden: []string{"Before"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := tui.NewModel("localhost:9090", "")
+			updated, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+			m = updated.(tui.Model)
+			for method, start := range tt.events {
+				ev := newTestEvent(method, "/test.v1.Test/"+method, 1)
+				ev.StartTime = timestamppb.New(start)
+				updated, _ = m.Update(tui.EventMsg{Event: ev})
+				m = updated.(tui.Model)
+			}
+
+			m = typeKeys(m, "@"+tt.window)
+			updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+			m = updated.(tui.Model)
+
+			view := m.View()
+			title := fmt.Sprintf("[@%s] (%d/%d events)", tt.window, len(tt.want), len(tt.events))
+			if !strings.Contains(view, title) {
+				t.Errorf("expected %q in the title, got:\n%s", title, view)
+			}
+			for _, method := range tt.want {
+				if !strings.Contains(view, "/test.v1.Test/"+method) {
+					t.Errorf("expected %s to be shown, got:\n%s", method, view)
+				}
+			}
+			for _, method := range tt.hidden {
+				if strings.Contains(view, "/test.v1.Test/"+method) {
+					t.Errorf("expected %s to be hidden, got:\n%s", method, view)
+				}
+			}
+
+			m = typeKeys(m, "@")
+			updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+			m = updated.(tui.Model)
+			if view := m.View(); strings.Contains(view, "[@") {
+				t.Errorf("expected esc to clear the window, got:\n%s", view)
+			}
+		})
+	}
+}
+
+func TestModel_Update_TimeWindowFilterInvalid(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	m = updated.(tui.Model)
+	updated, _ = m.Update(tui.EventMsg{Event: newTestEvent("evt-1", "/test.v1.Test/Get", 1)})
+	m = updated.(tui.Model)
+
+	m = typeKeys(m, "@yesterday")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(tui.Model)
+
+	view := m.View()
+	if !strings.Contains(view, "@yesterday█") || !strings.Contains(view, "expected a duration") {
+		t.Errorf("expected the input to stay open with an error, got:\n%s", view)
+	}
+	if !strings.Contains(view, "/test.v1.Test/Get") {
+		t.Errorf("expected an invalid window to filter nothing, got:\n%s", view)
+	}
+}
+
+func TestModel_View_ErrorsPanelIgnoresFilters(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+	failed := newTestEvent("evt-1", "/orders.v1.Orders/Create", 14) // domain.StatusInternal
+	failed.StatusMessage = "db down"
+	for _, ev := range []*scopev1.CallEvent{
+		failed,
+		newTestEvent("evt-2", "/users.v1.Users/Get", 1),
+	} {
+		updated, _ = m.Update(tui.EventMsg{Event: ev})
+		m = updated.(tui.Model)
+	}
+
+	// Filter the main list down to the successful method, then open the panel.
+	m = typeKeys(m, "/users")
+	updated, _ = m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	m = updated.(tui.Model)
+	m = typeKeys(m, "E")
+
+	view := m.View()
+	if !strings.Contains(view, "(1/2 events)") {
+		t.Errorf("expected the list to stay filtered, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Errors (1)") {
+		t.Errorf("expected errors panel, got:\n%s", view)
+	}
+	if !strings.Contains(view, "INTERNAL /orders.v1.Orders/Create (db down)") {
+		t.Errorf("expected the filtered-out error in the errors panel, got:\n%s", view)
+	}
+
+	m = typeKeys(m, "E")
+	if view := m.View(); strings.Contains(view, "Errors (1)") {
+		t.Errorf("expected E to hide the errors panel, got:\n%s", view)
+	}
+}
+
+func TestModel_View_StatsFoldsLeastRecentMethods(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "", tui.WithMaxStatsMethods(2))
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	base := time.Now()
+	// B and C are the least recently called, so they are folded.
+	for i, method := range []string{"A", "B", "C", "A", "D"} {
+		ev := newTestEvent(fmt.Sprintf("evt-%d", i), "/test.v1.Test/"+method, 1)
+		ev.StartTime = timestamppb.New(base.Add(time.Duration(i) * time.Second))
+		updated, _ = updated.Update(tui.EventMsg{Event: ev})
+	}
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+
+	view := updated.View()
+	if !strings.Contains(view, "Stats (4 methods, 5 events, sorted by calls)") {
+		t.Errorf("expected all methods to be counted in the title, got:\n%s", view)
+	}
+	rows := map[string]string{}
+	for _, line := range strings.Split(view, "\n") {
+		for _, name := range []string{"/test.v1.Test/A", "/test.v1.Test/B", "/test.v1.Test/C", "/test.v1.Test/D", "(other)"} {
+			if strings.Contains(line, name) {
+				rows[name] = line
+			}
+		}
+	}
+	for _, name := range []string{"/test.v1.Test/A", "/test.v1.Test/D"} {
+		if rows[name] == "" {
+			t.Errorf("expected a row for %s, got:\n%s", name, view)
+		}
+	}
+	for _, name := range []string{"/test.v1.Test/B", "/test.v1.Test/C"} {
+		if rows[name] != "" {
+			t.Errorf("expected %s to be folded, got row %q", name, rows[name])
+		}
+	}
+	if other := rows["(other)"]; !strings.Contains(other, "(other) 2 methods") || !strings.Contains(other, " 2 ") {
+		t.Errorf("expected an (other) row with 2 methods and 2 calls, got %q", other)
+	}
+}
+
+func TestModel_Update_StatsScrollStopsAtFoldedRows(t *testing.T) {
+	t.Parallel()
+
+	// 10 methods fold into 4 rows and (other); 3 rows fit, so 2 scroll steps.
+	m := tui.NewModel("localhost:9090", "", tui.WithMaxStatsMethods(4))
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 8})
+	for i := range 10 {
+		updated, _ = updated.Update(tui.EventMsg{Event: newTestEvent(fmt.Sprintf("evt-%d", i), fmt.Sprintf("/test.v1.Test/M%d", i), 1)})
+	}
+	m = typeKeys(updated.(tui.Model), "t")
+
+	bottom := typeKeys(m, "jj").View()
+	if view := typeKeys(m, "jjjjjjjjjj").View(); view != bottom {
+		t.Errorf("expected scrolling to stop at the last row, got:\n%s\nwant:\n%s", view, bottom)
+	}
+	if view := typeKeys(m, "jjjjjjjjjjk").View(); view == bottom {
+		t.Errorf("expected k to scroll back up right after the last row, got:\n%s", view)
+	}
+}
+
+func TestModel_ExportStats(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	m := tui.NewModel("localhost:9090", "", tui.WithExportDir(dir))
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	for i, code := range []int32{1, 1, 1, 14} {
+		updated, _ = updated.Update(tui.EventMsg{Event: newTestEvent(fmt.Sprintf("get-%d", i), "/test.v1.Test/Get", code)})
+	}
+	updated, _ = updated.Update(tui.EventMsg{Event: newTestEvent("list-0", "/test.v1.Test/List", 1)})
+
+	updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("t")})
+	updated, cmd := updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("w")})
+	if cmd == nil {
+		t.Fatal("expected an export command")
+	}
+	updated, _ = updated.Update(cmd())
+	if view := updated.View(); !strings.Contains(view, "Exported stats for 2 methods") {
+		t.Errorf("expected export status, got:\n%s", view)
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "grpc-scope-stats-*.json"))
+	if err != nil || len(paths) != 1 {
+		t.Fatalf("got export files %v (err %v), want exactly one", paths, err)
+	}
+	b, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report struct {
+		Events  int `json:"events"`
+		Methods []struct {
+			Method       string  `json:"method"`
+			Calls        int     `json:"calls"`
+			Errors       int     `json:"errors"`
+			ErrorPercent float64 `json:"errorPercent"`
+			P50Ms        float64 `json:"p50Ms"`
+			P99Ms        float64 `json:"p99Ms"`
+		} `json:"methods"`
+	}
+	if err := json.Unmarshal(b, &report); err != nil {
+		t.Fatalf("report is not valid JSON: %v\n%s", err, b)
+	}
+	if report.Events != 5 {
+		t.Errorf("events = %d, want 5", report.Events)
+	}
+	if len(report.Methods) != 2 {
+		t.Fatalf("got %d methods, want 2: %s", len(report.Methods), b)
+	}
+	get := report.Methods[0]
+	if get.Method != "/test.v1.Test/Get" || get.Calls != 4 || get.Errors != 1 || get.ErrorPercent != 25 {
+		t.Errorf("got Get aggregates %+v, want 4 calls, 1 error, 25%%", get)
+	}
+	if get.P50Ms != 10 || get.P99Ms != 10 {
+		t.Errorf("got Get latency p50=%v p99=%v, want 10ms", get.P50Ms, get.P99Ms)
+	}
+	if list := report.Methods[1]; list.Method != "/test.v1.Test/List" || list.Calls != 1 || list.Errors != 0 {
+		t.Errorf("got List aggregates %+v, want 1 call, no errors", list)
+	}
+}
+
+func TestModel_ExportSession(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		key        string
+		wantIDs    []string
+		wantInName string
+	}{
+		{name: "plain", key: "w", wantIDs: []string{"evt-a", "evt-b"}},
+		{name: "anonymized", key: "W", wantIDs: []string{"call-1", "call-2"}, wantInName: "-anon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			m := tui.NewModel("localhost:9090", "", tui.WithExportDir(dir))
+			updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+			for _, id := range []string{"evt-a", "evt-b"} {
+				updated, _ = updated.Update(tui.EventMsg{Event: newTestEvent(id, "/test.v1.Test/Get", 1)})
+			}
+
+			updated, cmd := updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(tt.key)})
+			if cmd == nil {
+				t.Fatal("expected an export command")
+			}
+			updated, _ = updated.Update(cmd())
+			if view := updated.View(); !strings.Contains(view, "Exported 2 events") {
+				t.Errorf("expected export status, got:\n%s", view)
+			}
+
+			paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+			if err != nil || len(paths) != 1 {
+				t.Fatalf("got export files %v (err %v), want exactly one", paths, err)
+			}
+			if !strings.Contains(filepath.Base(paths[0]), tt.wantInName) {
+				t.Errorf("got file %q, want name containing %q", paths[0], tt.wantInName)
+			}
+			f, err := os.Open(paths[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			t.Cleanup(func() { _ = f.Close() })
+			events, err := session.Read(f)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var ids []string
+			for _, ev := range events {
+				ids = append(ids, ev.GetId())
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("got IDs %v, want %v (oldest first)", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestModel_Reconnect(t *testing.T) {
+	t.Parallel()
+
+	dropped := fmt.Errorf("watch stream error: %w", status.Error(codes.Unavailable, "transport is closing"))
+
+	t.Run("dropped stream reconnects", func(t *testing.T) {
+		t.Parallel()
+
+		m := tui.Connected(setupModelWithEvent(""))
+		updated, cmd := m.Update(tui.ErrMsg{Err: dropped})
+		if cmd == nil {
+			t.Fatal("expected a reconnect to be scheduled")
+		}
+		view := updated.View()
+		if !strings.Contains(view, "Reconnecting to localhost:9090") || !strings.Contains(view, "attempt 1") {
+			t.Errorf("expected reconnecting banner, got:\n%s", view)
+		}
+		if !strings.Contains(view, "/test.v1.Test/Get") {
+			t.Errorf("expected captured events to stay visible, got:\n%s", view)
+		}
+
+		updated, _ = updated.Update(tui.ErrMsg{Err: dropped})
+		if view := updated.View(); !strings.Contains(view, "attempt 2") {
+			t.Errorf("expected second attempt in banner, got:\n%s", view)
+		}
+	})
+
+	t.Run("quit while reconnecting", func(t *testing.T) {
+		t.Parallel()
+
+		m := tui.Connected(setupModelWithEvent(""))
+		updated, _ := m.Update(tui.ErrMsg{Err: dropped})
+		_, cmd := updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("q")})
+		if cmd == nil {
+			t.Fatal("expected quit command")
+		}
+		if _, ok := cmd().(tea.QuitMsg); !ok {
+			t.Error("expected q to quit during reconnection")
+		}
+	})
+
+	t.Run("rejected token is not retried", func(t *testing.T) {
+		t.Parallel()
+
+		m := tui.Connected(setupModelWithEvent(""))
+		err := fmt.Errorf("watch stream error: %w", status.Error(codes.Unauthenticated, "missing or invalid x-scope-token"))
+		updated, cmd := m.Update(tui.ErrMsg{Err: err})
+		if cmd != nil {
+			t.Error("expected no reconnect for a rejected token")
+		}
+		if view := updated.View(); !strings.Contains(view, "--token") {
+			t.Errorf("expected token error, got:\n%s", view)
+		}
+	})
+}
+
+func TestReconnectDelay(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: 500 * time.Millisecond},
+		{attempt: 2, want: time.Second},
+		{attempt: 4, want: 4 * time.Second},
+		{attempt: 10, want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := tui.ReconnectDelay(tt.attempt); got != tt.want {
+			t.Errorf("attempt %d: got delay %s, want %s", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestModel_View_PayloadAnnotations(t *testing.T) {
+	t.Parallel()
+
+	// CallEvent serves as the schema: request_bytes_raw is bytes, labels a
+	// map<string, string>, and id and method plain strings.
+	schema := (&scopev1.CallEvent{}).ProtoReflect().Descriptor()
+	payload := `{"id":"user1234","method":"AbCd1234","requestBytesRaw":"iVBORw0KGgoAAAANSUhEUg==","labels":{"env":"prod","team":"core"}}`
+	tests := []struct {
+		name    string
+		schema  protoreflect.MessageDescriptor
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "schema resolved",
+			schema: schema,
+			// a PNG signature followed by the start of its IHDR chunk
+			want: []string{"// 16 bytes: 89 50 4e 47 0d 0a 1a 0a ...", "// map, 2 entries"},
+			// "user1234" and "AbCd1234" are valid base64 of 6 bytes.
+			notWant: []string{"// 6 bytes"},
+		},
+		{
+			name:    "no schema",
+			notWant: []string{"// 16 bytes", "// 6 bytes", "// map"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			m := tui.NewModel("localhost:9090", "localhost:8080")
+			updated, _ := m.Update(tea.WindowSizeMsg{Width: 200, Height: 40})
+			ev := newTestEvent("evt-1", "/test.v1.Test/Upload", 1)
+			ev.RequestPayload = payload
+			updated, _ = updated.Update(tui.EventMsg{Event: ev})
+			if tt.schema != nil {
+				updated, _ = updated.Update(tui.SchemaMsg("/test.v1.Test/Upload", tt.schema, nil))
+			}
+
+			view := updated.View()
+			if !strings.Contains(view, `"requestBytesRaw": "iVBORw0KGgoAAAANSUhEUg=="`) {
+				t.Errorf("expected raw base64 value in detail pane, got:\n%s", view)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(view, want) {
+					t.Errorf("expected %q in the detail pane, got:\n%s", want, view)
+				}
+			}
+			for _, notWant := range tt.notWant {
+				if strings.Contains(view, notWant) {
+					t.Errorf("expected no %q in the detail pane, got:\n%s", notWant, view)
+				}
+			}
+
+			updated, _ = updated.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}})
+			want := "grpcurl -plaintext -d '" + payload + "' localhost:8080 test.v1.Test/Upload"
+			if view := updated.View(); !strings.Contains(view, want) {
+				t.Errorf("expected copied command %q to keep the raw payload, got:\n%s", want, view)
+			}
+		})
+	}
+}
+
+func TestModel_View_StreamMessageCounts(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	ev := newTestEvent("evt-1", "/test.v1.Test/Watch", 15)
+	ev.Streaming = true
+	ev.RecvCount = 1
+	updated, _ = updated.Update(tui.EventMsg{Event: ev})
+
+	if view := updated.View(); !strings.Contains(view, "Messages: 0 sent, 1 received") {
+		t.Errorf("expected message counts in detail pane, got:\n%s", view)
+	}
+
+	unary := newTestEvent("evt-2", "/test.v1.Test/Get", 1)
+	updated, _ = tui.NewModel("localhost:9090", "").Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	updated, _ = updated.Update(tui.EventMsg{Event: unary})
+	if view := updated.View(); strings.Contains(view, "Messages:") {
+		t.Errorf("expected no message counts for a unary call, got:\n%s", view)
+	}
+}
+
+func TestModel_View_LatencyColors(t *testing.T) {
+	// Not parallel: the color profile is global.
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(termenv.ANSI)
+	t.Cleanup(func() { lipgloss.SetColorProfile(profile) })
+
+	m := tui.NewModel("localhost:9090", "", tui.WithLatencyThresholds(20*time.Millisecond, 0))
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 160, Height: 40})
+	// The selection stays on the first call.
+	durations := []time.Duration{2 * time.Millisecond, 5 * time.Millisecond, 30 * time.Millisecond, 700 * time.Millisecond, time.Second}
+	for i, d := range durations {
+		ev := newTestEvent(fmt.Sprintf("evt-%d", i), "/test.v1.Test/Get", 1)
+		ev.Duration = durationpb.New(d)
+		if d == time.Second {
+			ev.StatusCode = 14
+		}
+		updated, _ = updated.Update(tui.EventMsg{Event: ev})
+	}
+
+	view := updated.View()
+	fast, warn, crit := sgrBefore(view, "5ms"), sgrBefore(view, "30ms"), sgrBefore(view, "700ms")
+	if fast == "" || warn == "" || crit == "" {
+		t.Fatalf("expected styled latencies, got %q, %q and %q in:\n%s", fast, warn, crit, view)
+	}
+	if fast == warn || warn == crit || fast == crit {
+		t.Errorf("expected distinct latency styles, got %q, %q and %q", fast, warn, crit)
+	}
+	if got := sgrBefore(view, "1s"); got != "" {
+		t.Errorf("expected the failed row to keep its own color, got latency styled %q", got)
+	}
+	if got := sgrBefore(view, "2ms"); got != "" {
+		t.Errorf("expected the selected row to keep its own color, got latency styled %q", got)
+	}
+}
+
+func TestModel_Update_FollowMode(t *testing.T) {
+	t.Parallel()
+
+	m := tui.NewModel("localhost:9090", "")
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m = updated.(tui.Model)
+	add := func(m tui.Model, id, method string) tui.Model {
+		updated, _ := m.Update(tui.EventMsg{Event: newTestEvent(id, method, 1)})
+		return updated.(tui.Model)
+	}
+	m = add(m, "evt-1", "/test.v1.Test/First")
+	m = add(m, "evt-2", "/test.v1.Test/Second")
+	if view := m.View(); !strings.Contains(view, "Method: /test.v1.Test/First") || strings.Contains(view, "FOLLOW") {
+		t.Fatalf("expected the selection to stay on the first call without follow, got:\n%s", view)
+	}
+
+	m = typeKeys(m, "f")
+	if view := m.View(); !strings.Contains(view, "FOLLOW") || !strings.Contains(view, "Method: /test.v1.Test/Second") {
+		t.Fatalf("expected follow to select the newest call, got:\n%s", view)
+	}
+	m = add(m, "evt-3", "/test.v1.Test/Third")
+	if view := m.View(); !strings.Contains(view, "Method: /test.v1.Test/Third") {
+		t.Errorf("expected follow to select the call that arrived, got:\n%s", view)
+	}
+
+	m = typeKeys(m, "j")
+	m = add(m, "evt-4", "/test.v1.Test/Fourth")
+	view := m.View()
+	if strings.Contains(view, "FOLLOW") {
+		t.Errorf("expected navigation to turn follow off, got:\n%s", view)
+	}
+	if !strings.Contains(view, "Method: /test.v1.Test/Second") {
+		t.Errorf("expected the selection to stay put once follow is off, got:\n%s", view)
+	}
+}
+
+func TestModel_Update_ReplayKeepsSourceEventInFollowMode(t *testing.T) {
+	t.Parallel()
+
+	withUser := func(id, user string) *scopev1.CallEvent {
+		ev := newTestEvent(id, "/test.v1.Test/Get", 1)
+		ev.RequestMetadata = map[string]*scopev1.MetadataValues{"x-user": {Values: []string{user}}}
+		return ev
+	}
+	m := tui.NewModel("localhost:9090", "localhost:8080", tui.WithOriginalDeadline())
+	updated, _ := m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	source := withUser("evt-1", "alice")
+	source.Deadline = timestamppb.New(source.GetStartTime().AsTime().Add(3 * time.Second))
+	updated, _ = updated.Update(tui.EventMsg{Event: source})
+	m = typeKeys(updated.(tui.Model), "f")
+
+	updated, _ = m.Update(tui.ReplayResultMsg{
+		Result: &replay.Result{ResponseJSON: `{"result":"ok"}`},
+		Method: "/test.v1.Test/Get",
+		Event:  source,
+	})
+	updated, _ = updated.Update(tui.EventMsg{Event: withUser("evt-2", "bob")})
+
+	req := tui.ReplayRequest(updated.(tui.Model))
+	if got := req.Metadata["x-user"]; len(got) != 1 || got[0] != "alice" {
+		t.Errorf("got x-user %q, want the replayed call's [alice]", got)
+	}
+	if req.Timeout != 3*time.Second {
+		t.Errorf("got timeout %v, want the replayed call's 3s", req.Timeout)
+	}
+}
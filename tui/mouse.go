@@ -0,0 +1,42 @@
+package tui
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// listRowOffset is the screen row of the first event in the list panel:
+// below the top border, the title, and the column header.
+const listRowOffset = 3
+
+// handleMouse scrolls with the wheel and selects the clicked event in the
+// list view. The wheel moves like the up and down keys, so it scrolls the
+// replay and stats views too. Clicks outside the list rows are ignored.
+func (m Model) handleMouse(msg tea.MouseMsg) Model {
+	if m.palette != nil || m.editingFilter || m.editingTimeWindow || m.confirmClear {
+		return m
+	}
+
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		return m.navigateUp()
+	case tea.MouseButtonWheelDown:
+		return m.navigateDown()
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress || m.mode != viewList {
+			return m
+		}
+		listHeight := m.listHeight()
+		row := msg.Y - listRowOffset
+		if row < 0 || row >= listHeight {
+			return m
+		}
+		i := m.listStart(listHeight) + row
+		m = m.unfollow()
+		if m.grouped {
+			if rows := m.treeRows(); i < len(rows) {
+				m.treeSelected = rows[i].key
+			}
+		} else if i < len(m.visibleEvents()) {
+			m.cursor = i
+		}
+	}
+	return m
+}
@@ -0,0 +1,127 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// paletteCommand is an action listed in the command palette. Running it
+// dispatches key to runKey, so the palette and keybindings share handlers.
+type paletteCommand struct {
+	name      string
+	key       string
+	available func(m Model) bool
+}
+
+var paletteCommands = []paletteCommand{
+	{name: "Replay selected request", key: "r", available: func(m Model) bool { return m.canReplay() }},
+	{name: "Edit & replay selected request", key: "e", available: func(m Model) bool { return m.canReplay() }},
+	{name: "Copy grpcurl command", key: "y", available: func(m Model) bool {
+		return m.mode == viewList && m.selectedEvent() != nil
+	}},
+	{name: "Toggle detail line wrap", key: "z", available: func(m Model) bool { return m.mode == viewList }},
+	{name: "Export session", key: "w", available: func(m Model) bool {
+		return m.mode == viewList && len(m.events) > 0
+	}},
+	{name: "Export anonymized session", key: "W", available: func(m Model) bool {
+		return m.mode == viewList && len(m.events) > 0
+	}},
+	{name: "Clear events", key: "c", available: func(m Model) bool {
+		return m.mode == viewList && len(m.events) > 0
+	}},
+	{name: "Toggle errors-only filter", key: "x", available: func(m Model) bool { return m.mode == viewList }},
+	{name: "Filter by method or label", key: "/", available: func(m Model) bool { return m.mode == viewList }},
+	{name: "Filter by time window", key: "@", available: func(m Model) bool { return m.mode == viewList }},
+	{name: "Cycle direction filter", key: "d", available: func(m Model) bool { return m.mode == viewList }},
+	{name: "Toggle method tree", key: "g", available: func(m Model) bool { return m.mode == viewList }},
+	{name: "Toggle follow mode", key: "f", available: func(m Model) bool { return m.mode == viewList }},
+	{name: "Toggle errors panel", key: "E", available: func(m Model) bool { return m.mode == viewList }},
+	{name: "Toggle stats panel", key: "t", available: func(m Model) bool { return m.mode == viewList }},
+	{name: "Toggle timeline", key: "T", available: func(m Model) bool { return m.mode == viewList }},
+	{name: "Quit", key: "q", available: func(Model) bool { return true }},
+}
+
+type paletteState struct {
+	query  string
+	cursor int
+}
+
+// paletteMatches returns the available commands whose name contains the query.
+func (m Model) paletteMatches() []paletteCommand {
+	query := ""
+	if m.palette != nil {
+		query = strings.ToLower(m.palette.query)
+	}
+	var out []paletteCommand
+	for _, c := range paletteCommands {
+		if !c.available(m) {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(c.name), query) {
+			continue
+		}
+		out = append(out, c)
+	}
+	return out
+}
+
+func (m Model) handlePaletteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := *m.palette
+	m.palette = &p
+
+	switch msg.Type {
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.palette = nil
+		return m, nil
+	case tea.KeyEnter:
+		matches := m.paletteMatches()
+		m.palette = nil
+		if p.cursor < len(matches) {
+			key := matches[p.cursor].key
+			return m.runKey(key)
+		}
+		return m, nil
+	case tea.KeyUp, tea.KeyCtrlP:
+		if p.cursor > 0 {
+			p.cursor--
+		}
+	case tea.KeyDown, tea.KeyCtrlN:
+		if p.cursor < len(m.paletteMatches())-1 {
+			p.cursor++
+		}
+	case tea.KeyBackspace:
+		if p.query != "" {
+			runes := []rune(p.query)
+			p.query = string(runes[:len(runes)-1])
+			p.cursor = 0
+		}
+	case tea.KeyRunes, tea.KeySpace:
+		p.query += string(msg.Runes)
+		p.cursor = 0
+	default:
+	}
+	return m, nil
+}
+
+func (m Model) renderPalette(maxLines int) string {
+	lines := []string{labelStyle.Render(": ") + m.palette.query + "█"}
+
+	matches := m.paletteMatches()
+	if len(matches) == 0 {
+		lines = append(lines, helpStyle.Render("  no matching commands"))
+	}
+	for i, c := range matches {
+		line := fmt.Sprintf("  %-40s %s", c.name, m.keys.keyLabel(c.key))
+		if i == m.palette.cursor {
+			line = selectedStyle.Render("▶ " + line[2:])
+		}
+		lines = append(lines, line)
+	}
+
+	if len(lines) > maxLines {
+		lines = lines[:maxLines]
+	}
+	return borderStyle.Width(m.width - 2).Render(strings.Join(lines, "\n"))
+}
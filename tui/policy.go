@@ -0,0 +1,123 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mickamy/grpc-scope/replay"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+)
+
+// idempotencyTimeout bounds resolving a method's idempotency level before a
+// replay.
+const idempotencyTimeout = 5 * time.Second
+
+// WithReplayDenyList disables replay and edit & replay of calls whose full
+// method name, e.g. "/pkg.Service/Method", matches any of patterns in the
+// syntax of path.Match. "/*/Delete*" keeps every Delete method of a shared
+// environment from being resent.
+func WithReplayDenyList(patterns []string) Option {
+	return func(m *Model) {
+		m.replayDeny = append(m.replayDeny, patterns...)
+	}
+}
+
+// WithReplayAllowList limits replay to calls whose full method name matches
+// any of patterns, in the syntax of WithReplayDenyList. The deny list takes
+// precedence over it.
+func WithReplayAllowList(patterns []string) Option {
+	return func(m *Model) {
+		m.replayAllow = append(m.replayAllow, patterns...)
+	}
+}
+
+// ValidateMethodPatterns returns an error for the first of patterns that is
+// not valid path.Match syntax. A malformed pattern would otherwise match no
+// method, so a deny list holding one silently denies nothing.
+func ValidateMethodPatterns(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid method pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// replayBlocked returns why the replay policy forbids replaying method, or
+// "" if it may be replayed.
+func (m Model) replayBlocked(method string) string {
+	for _, pattern := range m.replayDeny {
+		if ok, _ := path.Match(pattern, method); ok {
+			return fmt.Sprintf("Replay of %s is disabled: it matches the deny list (%s)", method, pattern)
+		}
+	}
+	if len(m.replayAllow) == 0 {
+		return ""
+	}
+	for _, pattern := range m.replayAllow {
+		if ok, _ := path.Match(pattern, method); ok {
+			return ""
+		}
+	}
+	return fmt.Sprintf("Replay of %s is disabled: it is not on the allow list", method)
+}
+
+// pendingReplay is a replay waiting for its method's idempotency level and,
+// unless the method is safe to replay, for the user to confirm it.
+type pendingReplay struct {
+	method   string
+	metadata map[string][]string // nil sends the event's own metadata
+	payload  string
+	raw      []byte
+	event    *scopev1.CallEvent // the call being replayed
+	level    replay.Idempotency
+	err      error // why level could not be resolved
+}
+
+// idempotencyMsg carries a pending replay once its level is resolved.
+type idempotencyMsg struct {
+	replay pendingReplay
+}
+
+// checkReplay resolves the idempotency level p's method declares before p is
+// sent. Methods declaring NO_SIDE_EFFECTS replay at once; any other method
+// waits for the user to confirm.
+func (m Model) checkReplay(p pendingReplay) (Model, tea.Cmd) {
+	m.replaying = true
+	if m.replayErr != nil {
+		// doReplay reports the error in the replay view.
+		return m, m.doReplay(p.method, p.metadata, p.payload, p.raw, p.event)
+	}
+	client := m.replayClient
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), idempotencyTimeout)
+		defer cancel()
+		p.level, p.err = client.Idempotency(ctx, p.method)
+		return idempotencyMsg{replay: p}
+	}
+}
+
+// handleIdempotency sends a replay whose method is safe, or asks the user to
+// confirm it.
+func (m Model) handleIdempotency(msg idempotencyMsg) (Model, tea.Cmd) {
+	p := msg.replay
+	if p.err == nil && replay.IsSafe(p.level) {
+		return m, m.doReplay(p.method, p.metadata, p.payload, p.raw, p.event)
+	}
+	m.replaying = false
+	m.confirmReplay = &p
+	var reason string
+	switch {
+	case p.err != nil:
+		reason = fmt.Sprintf("Its side effects are unknown (%v)", p.err)
+	case p.level == replay.Idempotent:
+		reason = "It is idempotent but may have side effects"
+	default:
+		reason = "It may have side effects"
+	}
+	m.status = fmt.Sprintf("Replay %s? %s (y: confirm, any other key: cancel)", p.method, reason)
+	return m, nil
+}
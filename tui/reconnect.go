@@ -0,0 +1,99 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	reconnectBaseDelay = 500 * time.Millisecond
+	reconnectMaxDelay  = 10 * time.Second
+)
+
+// reconnectMsg is sent when the backoff before a reconnect attempt elapses.
+type reconnectMsg struct{}
+
+// reconnectDelay returns the exponential backoff before the given attempt,
+// starting at reconnectBaseDelay and capped at reconnectMaxDelay.
+func reconnectDelay(attempt int) time.Duration {
+	d := reconnectBaseDelay
+	for i := 1; i < attempt && d < reconnectMaxDelay; i++ {
+		d *= 2
+	}
+	return min(d, reconnectMaxDelay)
+}
+
+// shouldReconnect reports whether err from an established Watch stream is
+// worth retrying. Errors a retry cannot fix, such as a rejected token, are
+// shown to the user instead.
+func (m Model) shouldReconnect(err error) bool {
+	if !m.connected {
+		// The first connection failed; the server is likely not running at all.
+		return false
+	}
+	var se interface{ GRPCStatus() *status.Status }
+	if !errors.As(err, &se) {
+		return true
+	}
+	switch se.GRPCStatus().Code() {
+	case codes.Unauthenticated, codes.PermissionDenied, codes.Unimplemented:
+		return false
+	default:
+		return true
+	}
+}
+
+// scheduleReconnect drops the current connection and retries after a backoff.
+func (m Model) scheduleReconnect() (Model, tea.Cmd) {
+	if m.conn != nil {
+		_ = m.conn.Close()
+		m.conn = nil
+	}
+	m.reconnecting = true
+	m.reconnectAttempt++
+	return m, tea.Tick(reconnectDelay(m.reconnectAttempt), func(time.Time) tea.Msg {
+		return reconnectMsg{}
+	})
+}
+
+func (m Model) renderReconnecting() string {
+	return errorStyle.Render(fmt.Sprintf("  Reconnecting to %s… (attempt %d)", m.target, m.reconnectAttempt)) +
+		helpStyle.Render("  q: quit")
+}
+
+// renderConnection returns the title segment naming the scope server, the
+// connection state and the replay target, e.g. "● localhost:9090 · replay →
+// localhost:8080". Parts that do not fit in width are left out, the replay
+// target first.
+func (m Model) renderConnection(width int) string {
+	var state string
+	switch {
+	case m.reconnecting:
+		state = errorStyle.Render("↻ " + m.target + " reconnecting")
+	case m.connected && m.stale():
+		state = warnStyle.Render("◌ " + m.target + " stale")
+	case m.connected:
+		state = successStyle.Render("●") + " " + m.target
+	default:
+		state = helpStyle.Render("○ " + m.target + " connecting")
+	}
+	replay := helpStyle.Render("replay off")
+	if m.appTarget != "" {
+		replay = labelStyle.Render("replay →") + " " + m.appTarget
+	}
+
+	for _, parts := range [][]string{{state, replay}, {state}} {
+		segment := strings.Join(parts, helpStyle.Render(" · ")) + " "
+		if lipgloss.Width(segment) <= width {
+			return segment
+		}
+	}
+	return ""
+}
@@ -0,0 +1,305 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mickamy/grpc-scope/replay"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	maxResendCount   = 9999 // upper bound for a typed resend count
+	maxRecentResends = 5    // rolling results kept for display
+)
+
+// ResendProgressMsg is sent each time a call of a multi-resend completes.
+type ResendProgressMsg struct {
+	Result *replay.Result
+	Err    error
+	burst  int
+}
+
+// resendBurst tracks a multi-resend started from the replay view. Calls are
+// sent one after another so the progress bar advances with each result.
+type resendBurst struct {
+	id       int
+	req      replay.Request
+	ctx      context.Context
+	cancel   context.CancelFunc
+	total    int
+	done     int
+	ok       int
+	failed   int
+	canceled bool
+	recent   []resendOutcome // newest last
+}
+
+type resendOutcome struct {
+	seq      int
+	status   string
+	message  string
+	duration time.Duration
+	failed   bool
+}
+
+func (b *resendBurst) running() bool {
+	return !b.canceled && b.done < b.total
+}
+
+// appendResendDigit adds a typed digit to the pending resend count.
+func (m Model) appendResendDigit(d string) Model {
+	next := m.resendCount + d
+	if n, err := strconv.Atoi(next); err != nil || n > maxResendCount {
+		return m
+	}
+	if next == "0" {
+		return m
+	}
+	m.resendCount = next
+	return m
+}
+
+// startResend begins sending the replayed request n times.
+func (m Model) startResend(n int) (Model, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	m.burstSeq++
+	m.burst = &resendBurst{
+		id:     m.burstSeq,
+		req:    m.replayRequest(),
+		ctx:    ctx,
+		cancel: cancel,
+		total:  n,
+	}
+	m.replaying = true
+	return m, m.resendNext(m.burst)
+}
+
+// cancelResend stops a running multi-resend; results so far stay on screen.
+func (m Model) cancelResend() Model {
+	if m.burst == nil || !m.burst.running() {
+		return m
+	}
+	b := *m.burst
+	b.canceled = true
+	b.cancel()
+	m.burst = &b
+	m.replaying = false
+	return m
+}
+
+func (m Model) resendNext(b *resendBurst) tea.Cmd {
+	client, clientErr := m.replayClient, m.replayErr
+	ctx, req, id := b.ctx, b.req, b.id
+
+	return func() tea.Msg {
+		if clientErr != nil {
+			return ResendProgressMsg{Err: clientErr, burst: id}
+		}
+		result, err := client.Send(ctx, req)
+		return ResendProgressMsg{Result: result, Err: err, burst: id}
+	}
+}
+
+func (m Model) handleResendProgress(msg ResendProgressMsg) (Model, tea.Cmd) {
+	if m.burst == nil || msg.burst != m.burst.id || !m.burst.running() {
+		return m, nil
+	}
+
+	b := *m.burst
+	b.done++
+	out := resendOutcome{seq: b.done}
+	switch {
+	case msg.Err != nil:
+		out.status = "Error"
+		out.message = msg.Err.Error()
+		out.failed = true
+	case msg.Result.StatusCode != 0:
+		out.status = codes.Code(msg.Result.StatusCode).String()
+		out.message = msg.Result.StatusMessage
+		out.duration = msg.Result.Duration
+		out.failed = true
+	default:
+		out.status = "OK"
+		out.duration = msg.Result.Duration
+	}
+	if out.failed {
+		b.failed++
+	} else {
+		b.ok++
+	}
+	b.recent = append(append([]resendOutcome(nil), b.recent...), out)
+	if len(b.recent) > maxRecentResends {
+		b.recent = b.recent[len(b.recent)-maxRecentResends:]
+	}
+	m.burst = &b
+
+	if b.running() {
+		return m, m.resendNext(m.burst)
+	}
+	b.cancel()
+	m.replaying = false
+	return m, nil
+}
+
+func (m Model) renderResend(b *strings.Builder) {
+	burst := m.burst
+	b.WriteString(labelStyle.Render("Resend: "))
+	b.WriteString(progressBar(burst.done, burst.total, 20))
+	b.WriteString(fmt.Sprintf(" %d/%d  ", burst.done, burst.total))
+	b.WriteString(successStyle.Render(fmt.Sprintf("ok %d", burst.ok)))
+	b.WriteString("  ")
+	if burst.failed > 0 {
+		b.WriteString(errorStyle.Render(fmt.Sprintf("failed %d", burst.failed)))
+	} else {
+		b.WriteString(fmt.Sprintf("failed %d", burst.failed))
+	}
+	switch {
+	case burst.canceled:
+		b.WriteString(helpStyle.Render("  (canceled)"))
+	case !burst.running():
+		b.WriteString(helpStyle.Render("  (done)"))
+	}
+	b.WriteString("\n")
+
+	for i := len(burst.recent) - 1; i >= 0; i-- {
+		out := burst.recent[i]
+		line := fmt.Sprintf("  #%-4d %-18s %s", out.seq, out.status, out.duration)
+		if out.message != "" {
+			line += fmt.Sprintf(" (%s)", out.message)
+		}
+		if m.width > 8 {
+			line = truncate(line, m.width-6)
+		}
+		if out.failed {
+			line = errorStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
+
+// progressBar renders a fixed-width bar showing done out of total.
+func progressBar(done, total, width int) string {
+	filled := 0
+	if total > 0 {
+		filled = done * width / total
+	}
+	return "[" + strings.Repeat("█", filled) + strings.Repeat("░", width-filled) + "]"
+}
+
+// loadTestCount is the number of calls the L key sends.
+const loadTestCount = 100
+
+// LoadTestResultMsg is sent when a load test started with L completes.
+type LoadTestResultMsg struct {
+	Stats *replay.Stats
+	Err   error
+	run   int
+}
+
+type loadTestView struct {
+	run     int
+	total   int
+	cancel  context.CancelFunc
+	running bool
+	stats   *replay.Stats
+	err     error
+}
+
+// startLoadTest replays the request loadTestCount times via SendN.
+func (m Model) startLoadTest() (Model, tea.Cmd) {
+	client, clientErr := m.replayClient, m.replayErr
+	req := m.replayRequest()
+	ctx, cancel := context.WithCancel(context.Background())
+	m.burstSeq++
+	run := m.burstSeq
+	m.loadTest = &loadTestView{run: run, total: loadTestCount, cancel: cancel, running: true}
+	m.replaying = true
+
+	return m, func() tea.Msg {
+		defer cancel()
+		if clientErr != nil {
+			return LoadTestResultMsg{Err: clientErr, run: run}
+		}
+		stats, err := client.SendN(ctx, req, loadTestCount)
+		return LoadTestResultMsg{Stats: stats, Err: err, run: run}
+	}
+}
+
+// cancelLoadTest stops a running load test. Its partial stats are shown once
+// SendN returns.
+func (m Model) cancelLoadTest() Model {
+	if m.loadTest == nil || !m.loadTest.running {
+		return m
+	}
+	lt := *m.loadTest
+	lt.cancel()
+	lt.running = false
+	m.loadTest = &lt
+	m.replaying = false
+	return m
+}
+
+func (m Model) handleLoadTestResult(msg LoadTestResultMsg) Model {
+	if m.loadTest == nil || msg.run != m.loadTest.run {
+		return m
+	}
+	lt := *m.loadTest
+	if lt.running {
+		m.replaying = false
+	}
+	lt.running = false
+	lt.stats = msg.Stats
+	lt.err = msg.Err
+	m.loadTest = &lt
+	return m
+}
+
+func (m Model) renderLoadTest(b *strings.Builder) {
+	lt := m.loadTest
+	b.WriteString(labelStyle.Render("Load test: "))
+	switch {
+	case lt.running:
+		b.WriteString(fmt.Sprintf("sending %d calls...\n", lt.total))
+		return
+	case lt.stats == nil && lt.err == nil:
+		b.WriteString(helpStyle.Render("canceled"))
+		b.WriteString("\n")
+		return
+	}
+	if lt.err != nil {
+		b.WriteString(errorStyle.Render(lt.err.Error()))
+		b.WriteString("\n")
+	}
+	s := lt.stats
+	if s == nil {
+		return
+	}
+
+	if lt.err != nil {
+		b.WriteString("           ")
+	}
+	b.WriteString(fmt.Sprintf("%d/%d calls  ", s.Count, lt.total))
+	b.WriteString(successStyle.Render(fmt.Sprintf("ok %d", s.Success)))
+	b.WriteString(fmt.Sprintf("  min %s  avg %s  p99 %s  max %s\n", s.Min, s.Avg, s.P99, s.Max))
+
+	statuses := make([]codes.Code, 0, len(s.ByStatus))
+	for code := range s.ByStatus {
+		statuses = append(statuses, code)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i] < statuses[j] })
+	for _, code := range statuses {
+		line := fmt.Sprintf("  %-18s %d", code, s.ByStatus[code])
+		if code != codes.OK {
+			line = errorStyle.Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+}
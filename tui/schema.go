@@ -0,0 +1,78 @@
+package tui
+
+import (
+	"context"
+	"maps"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// schemaTimeout bounds resolving the schema of a method's payloads.
+const schemaTimeout = 5 * time.Second
+
+// methodSchema is the schema of a method's payloads, used to annotate them in
+// the detail pane. Both are nil until resolved, and stay nil if the method
+// cannot be resolved.
+type methodSchema struct {
+	request  protoreflect.MessageDescriptor
+	response protoreflect.MessageDescriptor
+}
+
+// schemaMsg carries the schema of method once resolved.
+type schemaMsg struct {
+	method string
+	schema methodSchema
+	err    error
+}
+
+// resolveSchemas resolves, through the replay client, the schema of each
+// method of events not yet asked for. Without a replay client there is no
+// schema to resolve; the methods are asked for once one exists.
+func (m Model) resolveSchemas(events ...*scopev1.CallEvent) (Model, tea.Cmd) {
+	client := m.replayClient
+	if client == nil {
+		return m, nil
+	}
+	var cmds []tea.Cmd
+	for _, ev := range events {
+		method := ev.GetMethod()
+		if _, ok := m.schemas[method]; ok || method == "" {
+			continue
+		}
+		if len(cmds) == 0 {
+			m.schemas = cloneSchemas(m.schemas)
+		}
+		m.schemas[method] = methodSchema{}
+		cmds = append(cmds, func() tea.Msg {
+			ctx, cancel := context.WithTimeout(context.Background(), schemaTimeout)
+			defer cancel()
+			desc, err := client.Describe(ctx, method)
+			if err != nil {
+				return schemaMsg{method: method, err: err}
+			}
+			return schemaMsg{method: method, schema: methodSchema{request: desc.Input(), response: desc.Output()}}
+		})
+	}
+	return m, tea.Batch(cmds...)
+}
+
+// handleSchema keeps a resolved schema. A method that cannot be resolved is
+// shown without annotations and not asked for again.
+func (m Model) handleSchema(msg schemaMsg) Model {
+	if msg.err != nil {
+		return m
+	}
+	m.schemas = cloneSchemas(m.schemas)
+	m.schemas[msg.method] = msg.schema
+	return m
+}
+
+// cloneSchemas copies schemas before a change, since models share it.
+func cloneSchemas(schemas map[string]methodSchema) map[string]methodSchema {
+	clone := make(map[string]methodSchema, len(schemas)+1)
+	maps.Copy(clone, schemas)
+	return clone
+}
@@ -0,0 +1,32 @@
+package tui
+
+import "fmt"
+
+// detailSection is a part of the detail pane that can be collapsed.
+type detailSection int
+
+const (
+	sectionRequest detailSection = iota
+	sectionResponse
+	sectionMetadata
+	detailSectionCount // number of sections; not a valid section itself
+)
+
+// detailSectionForKey returns the section toggled by key, "1" to "3".
+func detailSectionForKey(key string) (detailSection, bool) {
+	switch key {
+	case "1":
+		return sectionRequest, true
+	case "2":
+		return sectionResponse, true
+	case "3":
+		return sectionMetadata, true
+	default:
+		return 0, false
+	}
+}
+
+// collapsedHint is shown in place of a collapsed section's content.
+func collapsedHint(s detailSection) string {
+	return helpStyle.Render(fmt.Sprintf("(collapsed, press %d to expand)", s+1))
+}
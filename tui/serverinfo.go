@@ -0,0 +1,87 @@
+package tui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mickamy/grpc-scope/replay"
+	"github.com/mickamy/grpc-scope/scope/domain"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// maxServerInfoQueries bounds how often the scope server is asked for its
+// settings per connection: once on connect, and once more after an inbound
+// call, which is when the server learns the application address.
+const maxServerInfoQueries = 2
+
+const serverInfoTimeout = 5 * time.Second
+
+// serverInfoMsg carries the settings the scope server reported.
+type serverInfoMsg struct {
+	info *scopev1.ServerInfoResponse
+	err  error
+}
+
+// queryServerInfo asks the scope server for its settings, including the
+// application address to replay against when no app-addr was given. It
+// returns a nil command when there is nothing to ask.
+func (m Model) queryServerInfo() (Model, tea.Cmd) {
+	if m.conn == nil || m.serverInfoQuerying || m.serverInfoQueries >= maxServerInfoQueries {
+		return m, nil
+	}
+	m.serverInfoQuerying = true
+	m.serverInfoQueries++
+	conn, token := m.conn, m.token
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), serverInfoTimeout)
+		defer cancel()
+		if token != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, domain.HeaderScopeToken, token)
+		}
+		resp, err := scopev1.NewScopeServiceClient(conn).ServerInfo(ctx, &scopev1.ServerInfoRequest{})
+		return serverInfoMsg{info: resp, err: err}
+	}
+}
+
+// handleServerInfo applies the reported settings. It enables replay against
+// the reported application address, unless one was given on the command
+// line.
+func (m Model) handleServerInfo(msg serverInfoMsg) Model {
+	m.serverInfoQuerying = false
+	if status.Code(msg.err) == codes.Unimplemented {
+		// An older scope server or a shared session; asking again won't help.
+		m.serverInfoQueries = maxServerInfoQueries
+		return m
+	}
+	if msg.err != nil {
+		return m
+	}
+	m.maxShown = int(msg.info.GetMaxShownElements())
+	m.heartbeatInterval = msg.info.GetHeartbeatInterval().AsDuration()
+	target := msg.info.GetAppTarget()
+	if target == "" || m.appTarget != "" {
+		return m
+	}
+	m.appTarget = target
+	m.replayClient, m.replayErr = replay.NewClient(target, m.replayOpts...)
+	m.status = "Replay enabled for " + target + ", as reported by the scope server"
+	return m
+}
+
+// queryAppTargetAfter asks for the application address again once an inbound
+// call arrives, if the server did not know it when the TUI connected.
+func (m Model) queryAppTargetAfter(events ...*scopev1.CallEvent) (Model, tea.Cmd) {
+	if m.appTarget != "" {
+		return m, nil
+	}
+	for _, ev := range events {
+		if domain.Direction(ev.GetDirection()) == domain.DirectionInbound {
+			return m.queryServerInfo()
+		}
+	}
+	return m, nil
+}
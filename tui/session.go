@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+	"github.com/mickamy/grpc-scope/session"
+)
+
+// sessionExportedMsg is sent when a session export finishes.
+type sessionExportedMsg struct {
+	path string
+	err  error
+}
+
+// exportSession writes all captured events, oldest first, to a new session
+// file in m.exportDir. anonymize strips real timestamps and IDs so the file
+// can be shared.
+func (m Model) exportSession(anonymize bool) tea.Cmd {
+	events := slices.Clone(m.events)
+	slices.Reverse(events)
+	dir := m.exportDir
+	if dir == "" {
+		dir = "."
+	}
+
+	return func() tea.Msg {
+		name := "grpc-scope-" + time.Now().Format("20060102-150405")
+		var opts []session.Option
+		if anonymize {
+			name += "-anon"
+			opts = append(opts, session.WithAnonymize())
+		}
+		path := filepath.Join(dir, name+".jsonl")
+		return sessionExportedMsg{path: path, err: writeSession(path, events, opts...)}
+	}
+}
+
+func writeSession(path string, events []*scopev1.CallEvent, opts ...session.Option) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := session.Write(f, events, opts...); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (m Model) handleSessionExported(msg sessionExportedMsg) Model {
+	if msg.err != nil {
+		m.status = fmt.Sprintf("Export failed: %v", msg.err)
+		return m
+	}
+	m.status = fmt.Sprintf("Exported %d events to %s", len(m.events), msg.path)
+	return m
+}
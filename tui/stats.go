@@ -0,0 +1,327 @@
+package tui
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/mickamy/grpc-scope/scope/domain"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+)
+
+// DefaultMaxStatsMethods is how many methods the stats panel lists before
+// folding the rest into otherMethods.
+const DefaultMaxStatsMethods = 500
+
+// otherMethods is the stats row that collects the methods beyond the cap.
+const otherMethods = "(other)"
+
+// WithMaxStatsMethods caps the stats panel and its JSON export at n methods.
+// The methods called most recently are kept, and calls to the others are
+// counted in a single "(other)" row, so names with embedded IDs cannot grow
+// the panel without bound. n <= 0 removes the cap; the default is
+// DefaultMaxStatsMethods.
+func WithMaxStatsMethods(n int) Option {
+	return func(m *Model) {
+		m.maxStatsMethods = n
+	}
+}
+
+// methodStats summarizes the captured calls of a single method.
+type methodStats struct {
+	method string
+	calls  int
+	errors int
+	p50    time.Duration
+	p99    time.Duration
+	folded int // methods counted in this row; set on the otherMethods row
+}
+
+// label names the row in the stats panel.
+func (s methodStats) label() string {
+	if s.folded > 0 {
+		return fmt.Sprintf("%s %d methods", otherMethods, s.folded)
+	}
+	return s.method
+}
+
+func (s methodStats) errorRate() float64 {
+	if s.calls == 0 {
+		return 0
+	}
+	return float64(s.errors) / float64(s.calls) * 100
+}
+
+type statsSort int
+
+const (
+	statsSortCalls statsSort = iota
+	statsSortErrors
+	statsSortP50
+	statsSortP99
+	statsSortMethod
+	statsSortCount // number of sort keys; not a valid key itself
+)
+
+func (s statsSort) String() string {
+	switch s {
+	case statsSortCalls:
+		return "calls"
+	case statsSortErrors:
+		return "errors"
+	case statsSortP50:
+		return "p50"
+	case statsSortP99:
+		return "p99"
+	case statsSortMethod:
+		return "method"
+	default:
+		return "unknown"
+	}
+}
+
+// computeStats aggregates events by method and sorts the result by key.
+// Ties are broken by method name so the order is stable. With limit > 0,
+// only the limit methods seen first are kept, which are the most recently
+// called ones for events newest first; the others are summed into an
+// otherMethods row listed last.
+func computeStats(events []*scopev1.CallEvent, key statsSort, limit int) []methodStats {
+	durations := make(map[string][]time.Duration)
+	byMethod := make(map[string]*methodStats)
+	kept := 0
+	var other *methodStats
+	for _, ev := range events {
+		method := ev.GetMethod()
+		st, ok := byMethod[method]
+		switch {
+		case ok:
+		case limit <= 0 || kept < limit:
+			st = &methodStats{method: method}
+			byMethod[method] = st
+			kept++
+		default:
+			if other == nil {
+				other = &methodStats{method: otherMethods}
+			}
+			// Map the method to the shared row so later calls find it.
+			st = other
+			byMethod[method] = st
+			st.folded++
+		}
+		st.calls++
+		if domain.StatusCode(ev.GetStatusCode()) != domain.StatusOK {
+			st.errors++
+		}
+		durations[st.method] = append(durations[st.method], ev.GetDuration().AsDuration())
+	}
+
+	out := make([]methodStats, 0, kept)
+	for method, st := range byMethod {
+		if st == other {
+			continue
+		}
+		out = append(out, st.withPercentiles(durations[method]))
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		switch key {
+		case statsSortCalls:
+			if a.calls != b.calls {
+				return a.calls > b.calls
+			}
+		case statsSortErrors:
+			if a.errors != b.errors {
+				return a.errors > b.errors
+			}
+		case statsSortP50:
+			if a.p50 != b.p50 {
+				return a.p50 > b.p50
+			}
+		case statsSortP99:
+			if a.p99 != b.p99 {
+				return a.p99 > b.p99
+			}
+		case statsSortMethod, statsSortCount:
+		}
+		return a.method < b.method
+	})
+	if other != nil {
+		out = append(out, other.withPercentiles(durations[otherMethods]))
+	}
+	return out
+}
+
+// withPercentiles returns st with p50 and p99 taken from durations, which it
+// sorts.
+func (st *methodStats) withPercentiles(durations []time.Duration) methodStats {
+	slices.Sort(durations)
+	out := *st
+	out.p50 = percentile(durations, 50)
+	out.p99 = percentile(durations, 99)
+	return out
+}
+
+// percentile returns the nearest-rank percentile p of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// statsReport is the JSON form of the stats panel, for pasting into reports
+// or feeding dashboards. Methods are in the panel's current order.
+type statsReport struct {
+	GeneratedAt time.Time           `json:"generatedAt"`
+	Events      int                 `json:"events"`
+	Methods     []methodStatsReport `json:"methods"`
+}
+
+type methodStatsReport struct {
+	Method       string  `json:"method"`
+	Calls        int     `json:"calls"`
+	Errors       int     `json:"errors"`
+	ErrorPercent float64 `json:"errorPercent"`
+	P50Ms        float64 `json:"p50Ms"`
+	P99Ms        float64 `json:"p99Ms"`
+	// FoldedMethods is the number of methods summed into the "(other)" row.
+	FoldedMethods int `json:"foldedMethods,omitempty"`
+}
+
+func newStatsReport(events []*scopev1.CallEvent, key statsSort, limit int, now time.Time) statsReport {
+	stats := computeStats(events, key, limit)
+	r := statsReport{
+		GeneratedAt: now,
+		Events:      len(events),
+		Methods:     make([]methodStatsReport, 0, len(stats)),
+	}
+	for _, st := range stats {
+		r.Methods = append(r.Methods, methodStatsReport{
+			Method:        st.method,
+			Calls:         st.calls,
+			Errors:        st.errors,
+			ErrorPercent:  st.errorRate(),
+			P50Ms:         float64(st.p50) / float64(time.Millisecond),
+			P99Ms:         float64(st.p99) / float64(time.Millisecond),
+			FoldedMethods: st.folded,
+		})
+	}
+	return r
+}
+
+// statsExportedMsg is sent when a stats report export finishes.
+type statsExportedMsg struct {
+	path    string
+	methods int
+	err     error
+}
+
+// exportStats writes the stats of all captured events as a JSON report to a
+// new file in m.exportDir.
+func (m Model) exportStats() tea.Cmd {
+	events := slices.Clone(m.events)
+	key := m.statsSort
+	limit := m.maxStatsMethods
+	dir := m.exportDir
+	if dir == "" {
+		dir = "."
+	}
+
+	return func() tea.Msg {
+		now := time.Now()
+		report := newStatsReport(events, key, limit, now)
+		path := filepath.Join(dir, "grpc-scope-stats-"+now.Format("20060102-150405")+".json")
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err == nil {
+			err = os.WriteFile(path, append(b, '\n'), 0o644)
+		}
+		return statsExportedMsg{path: path, methods: len(report.Methods), err: err}
+	}
+}
+
+func (m Model) handleStatsExported(msg statsExportedMsg) Model {
+	if msg.err != nil {
+		m.status = fmt.Sprintf("Stats export failed: %v", msg.err)
+		return m
+	}
+	m.status = fmt.Sprintf("Exported stats for %d methods to %s", msg.methods, msg.path)
+	return m
+}
+
+func (m Model) renderStats() string {
+	stats := computeStats(m.events, m.statsSort, m.maxStatsMethods)
+
+	// 2(indent) + method + 1 + 8(calls) + 1 + 8(errors) + 1 + 8(err%) + 1 + 10(p50) + 1 + 10(p99) + 4(border/padding)
+	const fixed = 2 + 1 + 8 + 1 + 8 + 1 + 8 + 1 + 10 + 1 + 10 + 4
+	mw := m.width - fixed
+	if mw < 20 {
+		mw = 20
+	}
+
+	header := fmt.Sprintf("  %-*s %8s %8s %8s %10s %10s", mw, "Method", "Calls", "Errors", "Err%", "p50", "p99")
+	lines := []string{headerStyle.Render(header)}
+
+	// Visible area: border(2) + title(1) + header(1) + rows + help(1) = m.height
+	visibleMax := m.height - 2 - 1 - 1 - 1
+	if visibleMax < 1 {
+		visibleMax = 1
+	}
+	maxScroll := len(stats) - visibleMax
+	if maxScroll < 0 {
+		maxScroll = 0
+	}
+	start := m.statsScroll
+	if start > maxScroll {
+		start = maxScroll
+	}
+	end := start + visibleMax
+	if end > len(stats) {
+		end = len(stats)
+	}
+
+	for _, st := range stats[start:end] {
+		line := fmt.Sprintf("  %-*s %8d %8d %7.1f%% %10s %10s",
+			mw,
+			truncate(st.label(), mw),
+			st.calls,
+			st.errors,
+			st.errorRate(),
+			st.p50,
+			st.p99,
+		)
+		if st.errors > 0 {
+			line = errorStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if len(stats) == 0 {
+		lines = append(lines, "No events yet.")
+	}
+
+	for len(lines) < visibleMax+1 {
+		lines = append(lines, "")
+	}
+
+	methods := len(stats)
+	if n := len(stats); n > 0 && stats[n-1].folded > 0 {
+		methods += stats[n-1].folded - 1
+	}
+	title := fmt.Sprintf(" Stats (%d methods, %d events, sorted by %s) ", methods, len(m.events), m.statsSort)
+	help := helpStyle.Render("t/q: back  j/k/↑/↓: scroll  s: sort  w: export JSON")
+	if m.status != "" {
+		help = helpStyle.Render(m.status)
+	}
+	return borderStyle.Width(m.width-2).Render(title+"\n"+strings.Join(lines, "\n")) + "\n" + help
+}
@@ -0,0 +1,169 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+)
+
+// timeWindowLayouts are the layouts accepted for the ends of an absolute
+// time window. Layouts without a date refer to today.
+var timeWindowLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04",
+	"15:04:05.999999999",
+	"15:04",
+}
+
+// timeWindow limits the list to calls started within the last `last`, or
+// between from and to. A zero from or to leaves that end open.
+type timeWindow struct {
+	text     string // as typed, shown in the list title
+	last     time.Duration
+	from, to time.Time
+}
+
+// parseTimeWindow parses a relative window such as "30s" or "5m", or an
+// absolute one such as "15:04:05..15:05:00", where either end may be left
+// out. Times without a zone are read in loc, and times without a date as
+// of the day of now.
+func parseTimeWindow(s string, loc *time.Location, now time.Time) (timeWindow, error) {
+	s = strings.TrimSpace(s)
+	w := timeWindow{text: s}
+	if d, err := time.ParseDuration(s); err == nil {
+		if d <= 0 {
+			return timeWindow{}, fmt.Errorf("window %q must be positive", s)
+		}
+		w.last = d
+		return w, nil
+	}
+
+	from, to, ok := strings.Cut(s, "..")
+	if !ok {
+		return timeWindow{}, fmt.Errorf("expected a duration like 30s or a range like 15:04:05..15:05:00, got %q", s)
+	}
+	var err error
+	if w.from, err = parseWindowEnd(from, loc, now); err != nil {
+		return timeWindow{}, err
+	}
+	if w.to, err = parseWindowEnd(to, loc, now); err != nil {
+		return timeWindow{}, err
+	}
+	if w.from.IsZero() && w.to.IsZero() {
+		return timeWindow{}, fmt.Errorf("range %q has no start or end", s)
+	}
+	if !w.from.IsZero() && !w.to.IsZero() && w.to.Before(w.from) {
+		return timeWindow{}, fmt.Errorf("range %q ends before it starts", s)
+	}
+	return w, nil
+}
+
+// parseWindowEnd parses one end of an absolute window; "" yields the zero
+// time.
+func parseWindowEnd(s string, loc *time.Location, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, nil
+	}
+	for _, layout := range timeWindowLayouts {
+		t, err := time.ParseInLocation(layout, s, loc)
+		if err != nil {
+			continue
+		}
+		if t.Year() == 0 {
+			y, mo, d := now.In(loc).Date()
+			t = time.Date(y, mo, d, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+		}
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q", s)
+}
+
+// contains reports whether t falls within the window as of now. Both ends
+// of an absolute window are inclusive.
+func (w timeWindow) contains(t, now time.Time) bool {
+	if w.last > 0 {
+		return !t.Before(now.Add(-w.last))
+	}
+	if !w.from.IsZero() && t.Before(w.from) {
+		return false
+	}
+	return w.to.IsZero() || !t.After(w.to)
+}
+
+// matchesTimeWindow reports whether ev started within the time window.
+// Events without a start time only match when no window is set.
+func (m Model) matchesTimeWindow(ev *scopev1.CallEvent) bool {
+	if m.timeWindow == nil {
+		return true
+	}
+	if ev.GetStartTime() == nil {
+		return false
+	}
+	return m.timeWindow.contains(ev.GetStartTime().AsTime(), time.Now())
+}
+
+// timeWindowEvents returns the events within the time window, ignoring the
+// other filters, for the timeline.
+func (m Model) timeWindowEvents() []*scopev1.CallEvent {
+	if m.timeWindow == nil {
+		return m.events
+	}
+	events := make([]*scopev1.CallEvent, 0, len(m.events))
+	for _, ev := range m.events {
+		if m.matchesTimeWindow(ev) {
+			events = append(events, ev)
+		}
+	}
+	return events
+}
+
+func (m Model) location() *time.Location {
+	if m.utc {
+		return time.UTC
+	}
+	return time.Local
+}
+
+// handleTimeWindowKey edits the time window while it is being typed. Unlike
+// the method filter it applies on enter, once the input parses; esc clears
+// it.
+func (m Model) handleTimeWindowKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	selected := m.selectedEvent()
+
+	switch msg.Type {
+	case tea.KeyEnter:
+		if strings.TrimSpace(m.timeWindowInput) == "" {
+			m.editingTimeWindow = false
+			m.timeWindow = nil
+			break
+		}
+		w, err := parseTimeWindow(m.timeWindowInput, m.location(), time.Now())
+		if err != nil {
+			m.status = err.Error()
+			return m, nil
+		}
+		m.editingTimeWindow = false
+		m.timeWindow = &w
+	case tea.KeyEsc, tea.KeyCtrlC:
+		m.editingTimeWindow = false
+		m.timeWindowInput = ""
+		m.timeWindow = nil
+	case tea.KeyBackspace:
+		if m.timeWindowInput != "" {
+			runes := []rune(m.timeWindowInput)
+			m.timeWindowInput = string(runes[:len(runes)-1])
+		}
+		return m, nil
+	case tea.KeyRunes, tea.KeySpace:
+		m.timeWindowInput += string(msg.Runes)
+		return m, nil
+	default:
+		return m, nil
+	}
+	return m.reselect(selected), nil
+}
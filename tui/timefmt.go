@@ -0,0 +1,63 @@
+package tui
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// DefaultTimeLayout is the layout the list shows call start times in.
+const DefaultTimeLayout = "15:04:05"
+
+// layoutCheckTime is formatted to validate layouts and size the time column.
+// It differs from the reference time in every field, so a layout that
+// formats to itself has no layout elements.
+var layoutCheckTime = time.Date(2009, time.November, 28, 23, 45, 51, 123456789, time.UTC)
+
+// WithTimeFormat sets the time.Format layout of call start times in the list
+// and errors panel, shown in UTC if utc is set and in local time otherwise.
+// An empty layout keeps DefaultTimeLayout; callers should check others with
+// ValidTimeLayout.
+func WithTimeFormat(layout string, utc bool) Option {
+	return func(m *Model) {
+		if layout != "" {
+			m.timeLayout = layout
+		}
+		m.utc = utc
+	}
+}
+
+// ValidTimeLayout reports whether layout is a time.Format layout that shows
+// at least part of a time and formats times it can parse back.
+func ValidTimeLayout(layout string) bool {
+	s := layoutCheckTime.Format(layout)
+	if s == layout {
+		return false
+	}
+	_, err := time.Parse(layout, s)
+	return err == nil
+}
+
+// formatTime formats a call's start time for the list; nil yields "".
+func (m Model) formatTime(ts *timestamppb.Timestamp) string {
+	if ts == nil {
+		return ""
+	}
+	t := ts.AsTime().Local()
+	if m.utc {
+		t = t.UTC()
+	}
+	return t.Format(m.layout())
+}
+
+func (m Model) layout() string {
+	if m.timeLayout == "" {
+		return DefaultTimeLayout
+	}
+	return m.timeLayout
+}
+
+// timeColumnWidth is the width of formatted start times.
+func (m Model) timeColumnWidth() int {
+	return len(layoutCheckTime.Format(m.layout()))
+}
@@ -0,0 +1,143 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mickamy/grpc-scope/scope/domain"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+)
+
+// timelineWindows are the bucket sizes the timeline cycles through.
+var timelineWindows = []time.Duration{
+	time.Second,
+	5 * time.Second,
+	10 * time.Second,
+	30 * time.Second,
+	time.Minute,
+}
+
+// timelineBucket counts the calls started within one window.
+type timelineBucket struct {
+	start  time.Time
+	calls  int
+	errors int
+}
+
+func (b timelineBucket) errorRate() float64 {
+	if b.calls == 0 {
+		return 0
+	}
+	return float64(b.errors) / float64(b.calls) * 100
+}
+
+// computeTimeline buckets events by start time into consecutive windows,
+// oldest first, ending at the window holding the latest event. Empty windows
+// are kept so gaps between bursts show. At most limit buckets are returned;
+// older ones are dropped. Events without a start time are ignored.
+func computeTimeline(events []*scopev1.CallEvent, window time.Duration, limit int) []timelineBucket {
+	var first, last time.Time
+	for _, ev := range events {
+		if ev.GetStartTime() == nil {
+			continue
+		}
+		t := ev.GetStartTime().AsTime()
+		if first.IsZero() || t.Before(first) {
+			first = t
+		}
+		if last.IsZero() || t.After(last) {
+			last = t
+		}
+	}
+	if first.IsZero() || limit < 1 {
+		return nil
+	}
+
+	start := first.Truncate(window)
+	end := last.Truncate(window)
+	n := int(end.Sub(start)/window) + 1
+	if n > limit {
+		n = limit
+		start = end.Add(-time.Duration(n-1) * window)
+	}
+
+	buckets := make([]timelineBucket, n)
+	for i := range buckets {
+		buckets[i].start = start.Add(time.Duration(i) * window)
+	}
+	for _, ev := range events {
+		if ev.GetStartTime() == nil {
+			continue
+		}
+		i := int(ev.GetStartTime().AsTime().Truncate(window).Sub(start) / window)
+		if i < 0 {
+			continue
+		}
+		buckets[i].calls++
+		if domain.StatusCode(ev.GetStatusCode()) != domain.StatusOK {
+			buckets[i].errors++
+		}
+	}
+	return buckets
+}
+
+func (m Model) timelineWindow() time.Duration {
+	return timelineWindows[m.timelineIndex%len(timelineWindows)]
+}
+
+func (m Model) renderTimeline() string {
+	window := m.timelineWindow()
+
+	// Visible area: border(2) + title(1) + header(1) + rows + help(1) = m.height
+	visibleMax := m.height - 2 - 1 - 1 - 1
+	if visibleMax < 1 {
+		visibleMax = 1
+	}
+	events := m.timeWindowEvents()
+	buckets := computeTimeline(events, window, visibleMax)
+
+	// 2(indent) + 8(time) + 1 + bar + 1 + 6(calls) + 1 + 8(err%) + 4(border/padding)
+	const fixed = 2 + 8 + 1 + 1 + 6 + 1 + 8 + 4
+	bw := m.width - fixed
+	if bw < 10 {
+		bw = 10
+	}
+
+	header := fmt.Sprintf("  %-8s %-*s %6s %8s", "Time", bw, "Calls", "Count", "Err%")
+	lines := []string{headerStyle.Render(header)}
+
+	peak := 0
+	for _, b := range buckets {
+		peak = max(peak, b.calls)
+	}
+	for _, b := range buckets {
+		bar := ""
+		if b.calls > 0 {
+			// Round up so every non-empty window shows at least one cell.
+			bar = strings.Repeat("█", (b.calls*bw+peak-1)/peak)
+		}
+		line := fmt.Sprintf("  %-8s %-*s %6d %7.1f%%", b.start.Local().Format("15:04:05"), bw, bar, b.calls, b.errorRate())
+		switch rate := b.errorRate(); {
+		case rate >= 50:
+			line = errorStyle.Render(line)
+		case rate > 0:
+			line = warnStyle.Render(line)
+		}
+		lines = append(lines, line)
+	}
+	if len(buckets) == 0 {
+		lines = append(lines, "No events yet.")
+	}
+
+	for len(lines) < visibleMax+1 {
+		lines = append(lines, "")
+	}
+
+	title := fmt.Sprintf(" Timeline (%d events, %s windows, peak %d) ", len(events), window, peak)
+	if m.timeWindow != nil {
+		title = fmt.Sprintf(" Timeline [@%s] (%d events, %s windows, peak %d) ", m.timeWindow.text, len(events), window, peak)
+	}
+	help := helpStyle.Render("T/q: back  b: window size")
+	return borderStyle.Width(m.width-2).Render(title+"\n"+strings.Join(lines, "\n")) + "\n" + help
+}
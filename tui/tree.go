@@ -0,0 +1,232 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/mickamy/grpc-scope/scope/domain"
+	scopev1 "github.com/mickamy/grpc-scope/scope/gen/scope/v1"
+)
+
+// treeRow is a row of the list panel grouped by service: a service, one of
+// its methods, or a call of an expanded method.
+type treeRow struct {
+	key     string // identifies the row across renders; see treeSelected
+	depth   int    // 0 for services, 1 for methods, 2 for calls
+	label   string
+	open    bool
+	calls   int
+	errors  int
+	stats   *methodStats       // set on method rows
+	ev      *scopev1.CallEvent // set on call rows
+	service string
+}
+
+func (r treeRow) errorRate() float64 {
+	if r.calls == 0 {
+		return 0
+	}
+	return float64(r.errors) / float64(r.calls) * 100
+}
+
+// splitMethod splits a full method name, e.g. "/pkg.Service/Method", into its
+// service and method.
+func splitMethod(fullMethod string) (service, method string) {
+	service, method, ok := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if !ok {
+		return fullMethod, fullMethod
+	}
+	return service, method
+}
+
+// treeNodeOpen reports whether the node with key is expanded. Services start
+// expanded and methods collapsed; treeToggled flips that per node.
+func (m Model) treeNodeOpen(key string, depth int) bool {
+	return (depth == 0) != m.treeToggled[key]
+}
+
+// treeRows returns the rows of the service tree built from the visible
+// events, sorted by service and method name.
+func (m Model) treeRows() []treeRow {
+	visible := m.visibleEvents()
+	byMethod := make(map[string][]*scopev1.CallEvent)
+	for _, ev := range visible {
+		byMethod[ev.GetMethod()] = append(byMethod[ev.GetMethod()], ev)
+	}
+
+	var rows []treeRow
+	serviceRow := -1
+	for _, st := range computeStats(visible, statsSortMethod, 0) {
+		service, method := splitMethod(st.method)
+		if serviceRow < 0 || rows[serviceRow].service != service {
+			rows = append(rows, treeRow{
+				key:     "service:" + service,
+				label:   service,
+				open:    m.treeNodeOpen("service:"+service, 0),
+				service: service,
+			})
+			serviceRow = len(rows) - 1
+		}
+		rows[serviceRow].calls += st.calls
+		rows[serviceRow].errors += st.errors
+		if !rows[serviceRow].open {
+			continue
+		}
+
+		stats := st
+		open := m.treeNodeOpen("method:"+st.method, 1)
+		rows = append(rows, treeRow{
+			key:     "method:" + st.method,
+			depth:   1,
+			label:   method,
+			open:    open,
+			calls:   st.calls,
+			errors:  st.errors,
+			stats:   &stats,
+			service: service,
+		})
+		if !open {
+			continue
+		}
+		for _, ev := range byMethod[st.method] {
+			rows = append(rows, treeRow{
+				key:     fmt.Sprintf("call:%s#%d", ev.GetId(), ev.GetSeq()),
+				depth:   2,
+				label:   ev.GetId(),
+				ev:      ev,
+				service: service,
+			})
+		}
+	}
+	return rows
+}
+
+// treeCursor returns the index of the selected row, falling back to the
+// first row when the selection is no longer shown.
+func (m Model) treeCursor(rows []treeRow) int {
+	for i, r := range rows {
+		if r.key == m.treeSelected {
+			return i
+		}
+	}
+	return 0
+}
+
+// toggleTree switches the list between calls and calls grouped by service
+// and method, keeping the selected call selected where it is shown.
+func (m Model) toggleTree() Model {
+	selected := m.selectedEvent()
+	m.grouped = !m.grouped
+	if !m.grouped {
+		if selected != nil {
+			return m.reselect(selected)
+		}
+		return m
+	}
+	if selected != nil {
+		return m.selectTreeEvent(selected)
+	}
+	return m
+}
+
+// selectTreeEvent selects the row of ev in the tree, or that of its service
+// when its method is collapsed.
+func (m Model) selectTreeEvent(ev *scopev1.CallEvent) Model {
+	for _, r := range m.treeRows() {
+		if r.ev == ev {
+			m.treeSelected = r.key
+			return m
+		}
+	}
+	service, _ := splitMethod(ev.GetMethod())
+	m.treeSelected = "service:" + service
+	return m
+}
+
+// toggleTreeNode expands or collapses the selected service or method.
+func (m Model) toggleTreeNode() Model {
+	rows := m.treeRows()
+	if len(rows) == 0 {
+		return m
+	}
+	r := rows[m.treeCursor(rows)]
+	if r.ev != nil {
+		return m
+	}
+	toggled := make(map[string]bool, len(m.treeToggled)+1)
+	for k, v := range m.treeToggled {
+		toggled[k] = v
+	}
+	toggled[r.key] = !toggled[r.key]
+	m.treeToggled = toggled
+	m.treeSelected = r.key
+	return m
+}
+
+// moveTreeCursor moves the tree selection by delta rows.
+func (m Model) moveTreeCursor(delta int) Model {
+	rows := m.treeRows()
+	if len(rows) == 0 {
+		return m
+	}
+	i := min(max(m.treeCursor(rows)+delta, 0), len(rows)-1)
+	m.treeSelected = rows[i].key
+	return m
+}
+
+// renderTreeRow formats a row of the tree in the list panel's columns. styled
+// colors the latency of call rows; see latencyCell.
+func (m Model) renderTreeRow(r treeRow, mw int, styled bool) string {
+	if r.ev != nil {
+		return m.renderListRow(r.ev, mw, strings.Repeat("  ", r.depth)+directionBadge(r.ev)+r.label, styled)
+	}
+
+	marker := "▸ "
+	if r.open {
+		marker = "▾ "
+	}
+	label := fmt.Sprintf("%s%s%s (%d)", strings.Repeat("  ", r.depth), marker, r.label, r.calls)
+	status := domain.StatusOK.String()
+	if r.errors > 0 {
+		status = fmt.Sprintf("%d ERR", r.errors)
+	}
+	latency := ""
+	if r.stats != nil {
+		latency = r.stats.p50.String()
+	}
+	return fmt.Sprintf("%-*s %-12s %-8s %-10s", mw, truncate(label, mw), status, "", latency)
+}
+
+// renderTreeNodeDetail summarizes a selected service or method in the detail
+// pane.
+func (m Model) renderTreeNodeDetail(r treeRow) string {
+	var b strings.Builder
+	if r.depth == 0 {
+		b.WriteString(labelStyle.Render("Service: "))
+		b.WriteString(r.label)
+	} else {
+		b.WriteString(labelStyle.Render("Method: "))
+		b.WriteString(r.stats.method)
+	}
+	b.WriteString("\n")
+	b.WriteString(labelStyle.Render("Calls: "))
+	b.WriteString(fmt.Sprintf("%d", r.calls))
+	b.WriteString("  ")
+	b.WriteString(labelStyle.Render("Errors: "))
+	b.WriteString(fmt.Sprintf("%d (%.1f%%)", r.errors, r.errorRate()))
+	if r.stats != nil {
+		b.WriteString("  ")
+		b.WriteString(labelStyle.Render("p50: "))
+		b.WriteString(r.stats.p50.String())
+		b.WriteString("  ")
+		b.WriteString(labelStyle.Render("p99: "))
+		b.WriteString(r.stats.p99.String())
+	}
+	b.WriteString("\n")
+	action := "expand"
+	if r.open {
+		action = "collapse"
+	}
+	b.WriteString(helpStyle.Render("enter: " + action))
+	return borderStyle.Width(m.width - 2).Render(b.String())
+}